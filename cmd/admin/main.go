@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"time"
 
+	"github.com/asakaida/keruberosu/internal/infrastructure/auth"
 	"github.com/asakaida/keruberosu/internal/infrastructure/config"
 	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
 	"github.com/asakaida/keruberosu/internal/repositories/postgres"
 	"github.com/spf13/cobra"
 )
 
-var envFlag string
+var (
+	envFlag  string
+	logLevel *slog.LevelVar
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "admin",
@@ -29,42 +35,62 @@ that skipped closure updates. Safe to run at any time.`,
 	Run: runRebuildClosures,
 }
 
+var generateKeyCmd = &cobra.Command{
+	Use:   "generate-key",
+	Short: "Generate a pre-shared API key",
+	Long: `Generate a random pre-shared API key and print it with its SHA-256 hash.
+Give the key to the client and put only the hash in AUTH_KEYS_FILE.`,
+	Run: runGenerateKey,
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&envFlag, "env", "e", "dev", "Environment to use (dev, test, prod)")
 	rootCmd.AddCommand(rebuildClosuresCmd)
+	rootCmd.AddCommand(generateKeyCmd)
 }
 
 func main() {
+	// Log JSON lines to stderr like the server; command output (e.g., generated
+	// keys) goes to stdout
+	logLevel = logging.Setup(os.Stderr, slog.LevelInfo)
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Failed to execute command: %v", err)
+		fatal("Failed to execute command", "error", err)
 	}
 }
 
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
 func runRebuildClosures(cmd *cobra.Command, args []string) {
-	log.Println("Starting closure table rebuild...")
+	slog.Info("Starting closure table rebuild", "environment", envFlag)
 
 	if err := config.InitConfig(envFlag); err != nil {
-		log.Fatalf("Failed to initialize config: %v", err)
+		fatal("Failed to initialize config", "error", err)
 	}
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", "error", err)
 	}
+	level, _ := cfg.Log.ParseLevel() // Validated by Load
+	logLevel.Set(level)
 
 	cluster, err := database.NewDBCluster(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", "error", err)
 	}
 	defer cluster.Close()
 
-	log.Printf("Connected to database: %s@%s:%d/%s",
-		cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+	slog.Info("Connected to database",
+		"user", cfg.Database.User, "host", cfg.Database.Host, "port", cfg.Database.Port, "database", cfg.Database.Database)
 
 	// Get all tenant IDs
 	ctx := context.Background()
-	rows, err := cluster.PrimaryDB().QueryContext(ctx, "SELECT DISTINCT tenant_id FROM schemas ORDER BY tenant_id")
+	rows, err := cluster.PrimaryDB().QueryContext(ctx, "SELECT id FROM tenants ORDER BY id")
 	if err != nil {
-		log.Fatalf("Failed to query tenant IDs: %v", err)
+		fatal("Failed to query tenant IDs", "error", err)
 	}
 	defer rows.Close()
 
@@ -72,20 +98,20 @@ func runRebuildClosures(cmd *cobra.Command, args []string) {
 	for rows.Next() {
 		var tenantID string
 		if err := rows.Scan(&tenantID); err != nil {
-			log.Fatalf("Failed to scan tenant ID: %v", err)
+			fatal("Failed to scan tenant ID", "error", err)
 		}
 		tenantIDs = append(tenantIDs, tenantID)
 	}
 	if err := rows.Err(); err != nil {
-		log.Fatalf("Error iterating tenant IDs: %v", err)
+		fatal("Error iterating tenant IDs", "error", err)
 	}
 
 	if len(tenantIDs) == 0 {
-		log.Println("No tenants found. Nothing to rebuild.")
+		slog.Info("No tenants found, nothing to rebuild")
 		return
 	}
 
-	log.Printf("Found %d tenant(s): %v", len(tenantIDs), tenantIDs)
+	slog.Info("Found tenants", "count", len(tenantIDs), "tenant_ids", tenantIDs)
 
 	closureExcluded := cfg.Database.ParseClosureExcludedRelations()
 	relationRepo := postgres.NewPostgresRelationRepository(cluster, closureExcluded)
@@ -99,12 +125,12 @@ func runRebuildClosures(cmd *cobra.Command, args []string) {
 		cluster.PrimaryDB().QueryRowContext(ctx,
 			"SELECT COUNT(*) FROM entity_closure WHERE tenant_id = $1", tenantID).Scan(&closureBefore)
 
-		log.Printf("  Rebuilding tenant %s (relations: %d, closures before: %d)...",
-			tenantID, relationCount, closureBefore)
+		slog.Info("Rebuilding tenant closures",
+			"tenant_id", tenantID, "relations", relationCount, "closures_before", closureBefore)
 
 		start := time.Now()
 		if err := relationRepo.RebuildClosure(ctx, tenantID); err != nil {
-			log.Printf("  ERROR rebuilding tenant %s: %v", tenantID, err)
+			slog.Error("Failed to rebuild tenant closures", "tenant_id", tenantID, "error", err)
 			continue
 		}
 
@@ -112,10 +138,18 @@ func runRebuildClosures(cmd *cobra.Command, args []string) {
 		cluster.PrimaryDB().QueryRowContext(ctx,
 			"SELECT COUNT(*) FROM entity_closure WHERE tenant_id = $1", tenantID).Scan(&closureAfter)
 
-		log.Printf("  Done tenant %s in %v (closures: %d -> %d, delta: %+d)",
-			tenantID, time.Since(start).Round(time.Millisecond),
-			closureBefore, closureAfter, closureAfter-closureBefore)
+		slog.Info("Rebuilt tenant closures",
+			"tenant_id", tenantID, "duration", time.Since(start).Round(time.Millisecond).String(),
+			"closures_before", closureBefore, "closures_after", closureAfter, "delta", closureAfter-closureBefore)
 	}
 
-	fmt.Printf("\nAll tenants rebuilt in %v\n", time.Since(totalStart).Round(time.Millisecond))
+	slog.Info("All tenants rebuilt", "duration", time.Since(totalStart).Round(time.Millisecond).String())
+}
+
+func runGenerateKey(cmd *cobra.Command, args []string) {
+	key, err := auth.GenerateKey()
+	if err != nil {
+		fatal("Failed to generate key", "error", err)
+	}
+	fmt.Printf("key:  %s\nhash: %s\n", key, auth.HashKey(key))
 }
@@ -2,12 +2,13 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/asakaida/keruberosu/internal/infrastructure/config"
 	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/spf13/cobra"
 )
@@ -17,8 +18,9 @@ const (
 )
 
 var (
-	envFlag string
-	pg      *database.Postgres
+	envFlag  string
+	pg       *database.Postgres
+	logLevel *slog.LevelVar
 )
 
 var rootCmd = &cobra.Command{
@@ -80,35 +82,45 @@ func init() {
 }
 
 func main() {
+	// Log JSON lines to stderr like the server
+	logLevel = logging.Setup(os.Stderr, slog.LevelInfo)
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Failed to execute command: %v", err)
+		fatal("Failed to execute command", "error", err)
 	}
 }
 
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
 func setupDatabase(cmd *cobra.Command, args []string) {
-	log.Printf("Using environment: %s", envFlag)
+	slog.Info("Using environment", "environment", envFlag)
 
 	// Initialize configuration from .env.{env} file
 	if err := config.InitConfig(envFlag); err != nil {
-		log.Fatalf("Failed to initialize config: %v", err)
+		fatal("Failed to initialize config", "error", err)
 	}
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", "error", err)
 	}
+	level, _ := cfg.Log.ParseLevel() // Validated by Load
+	logLevel.Set(level)
 
 	// Connect to database
 	pg, err = database.NewPostgres(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", "error", err)
 	}
 
-	log.Printf("Connected to database: %s@%s:%d/%s",
-		cfg.Database.User,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Database)
+	slog.Info("Connected to database",
+		"user", cfg.Database.User,
+		"host", cfg.Database.Host,
+		"port", cfg.Database.Port,
+		"database", cfg.Database.Database)
 }
 
 func getMigrationsPath() (string, error) {
@@ -119,30 +131,30 @@ func getMigrationsPath() (string, error) {
 	}
 
 	migrationsPath := filepath.Join(projectRoot, migrationsPathSuffix)
-	log.Printf("Using migrations path: %s", migrationsPath)
+	slog.Info("Using migrations path", "path", migrationsPath)
 	return migrationsPath, nil
 }
 
 func runUp(cmd *cobra.Command, args []string) {
 	migrationsPath, err := getMigrationsPath()
 	if err != nil {
-		log.Fatalf("Failed to get migrations path: %v", err)
+		fatal("Failed to get migrations path", "error", err)
 	}
 
 	m, err := createMigrate(pg, migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		fatal("Failed to create migrate instance", "error", err)
 	}
 	defer m.Close()
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Migration up failed: %v", err)
+		fatal("Migration up failed", "error", err)
 	}
 
 	if err == migrate.ErrNoChange {
-		log.Println("No migrations to apply")
+		slog.Info("No migrations to apply")
 	} else {
-		log.Println("Migration up completed successfully")
+		slog.Info("Migration up completed successfully")
 	}
 }
 
@@ -154,23 +166,23 @@ func runDown(cmd *cobra.Command, args []string) {
 
 	migrationsPath, err := getMigrationsPath()
 	if err != nil {
-		log.Fatalf("Failed to get migrations path: %v", err)
+		fatal("Failed to get migrations path", "error", err)
 	}
 
 	m, err := createMigrate(pg, migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		fatal("Failed to create migrate instance", "error", err)
 	}
 	defer m.Close()
 
 	if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Migration down failed: %v", err)
+		fatal("Migration down failed", "error", err)
 	}
 
 	if err == migrate.ErrNoChange {
-		log.Println("No migrations to rollback")
+		slog.Info("No migrations to rollback")
 	} else {
-		log.Printf("Migration down completed successfully (rolled back %d migration(s))", steps)
+		slog.Info("Migration down completed successfully", "steps", steps)
 	}
 }
 
@@ -180,51 +192,51 @@ func runGoto(cmd *cobra.Command, args []string) {
 
 	migrationsPath, err := getMigrationsPath()
 	if err != nil {
-		log.Fatalf("Failed to get migrations path: %v", err)
+		fatal("Failed to get migrations path", "error", err)
 	}
 
 	m, err := createMigrate(pg, migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		fatal("Failed to create migrate instance", "error", err)
 	}
 	defer m.Close()
 
 	if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Migration goto failed: %v", err)
+		fatal("Migration goto failed", "version", version, "error", err)
 	}
 
 	if err == migrate.ErrNoChange {
-		log.Printf("Already at version %d", version)
+		slog.Info("Already at version", "version", version)
 	} else {
-		log.Printf("Migration goto %d completed successfully", version)
+		slog.Info("Migration goto completed successfully", "version", version)
 	}
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
 	migrationsPath, err := getMigrationsPath()
 	if err != nil {
-		log.Fatalf("Failed to get migrations path: %v", err)
+		fatal("Failed to get migrations path", "error", err)
 	}
 
 	m, err := createMigrate(pg, migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		fatal("Failed to create migrate instance", "error", err)
 	}
 	defer m.Close()
 
 	version, dirty, err := m.Version()
 	if err == migrate.ErrNilVersion {
-		log.Println("Current version: No migrations applied yet")
+		slog.Info("No migrations applied yet")
 		return
 	}
 	if err != nil {
-		log.Fatalf("Failed to get version: %v", err)
+		fatal("Failed to get version", "error", err)
 	}
 
 	if dirty {
-		log.Printf("Current version: %d (dirty - migration may have failed)", version)
+		slog.Warn("Current version is dirty, migration may have failed", "version", version, "dirty", dirty)
 	} else {
-		log.Printf("Current version: %d", version)
+		slog.Info("Current version", "version", version, "dirty", dirty)
 	}
 }
 
@@ -234,20 +246,20 @@ func runForce(cmd *cobra.Command, args []string) {
 
 	migrationsPath, err := getMigrationsPath()
 	if err != nil {
-		log.Fatalf("Failed to get migrations path: %v", err)
+		fatal("Failed to get migrations path", "error", err)
 	}
 
 	m, err := createMigrate(pg, migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		fatal("Failed to create migrate instance", "error", err)
 	}
 	defer m.Close()
 
 	if err := m.Force(version); err != nil {
-		log.Fatalf("Migration force failed: %v", err)
+		fatal("Migration force failed", "version", version, "error", err)
 	}
 
-	log.Printf("Migration forced to version %d", version)
+	slog.Info("Migration forced to version", "version", version)
 }
 
 func createMigrate(pg *database.Postgres, migrationsPath string) (*migrate.Migrate, error) {
@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -11,22 +12,37 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/asakaida/keruberosu/internal/gateway"
 	"github.com/asakaida/keruberosu/internal/handlers"
+	"github.com/asakaida/keruberosu/internal/infrastructure/auth"
 	"github.com/asakaida/keruberosu/internal/infrastructure/cache"
+	"github.com/asakaida/keruberosu/internal/infrastructure/certs"
 	"github.com/asakaida/keruberosu/internal/infrastructure/config"
 	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/infrastructure/decisionlog"
+	"github.com/asakaida/keruberosu/internal/infrastructure/health"
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
 	"github.com/asakaida/keruberosu/internal/infrastructure/metrics"
+	"github.com/asakaida/keruberosu/internal/infrastructure/ratelimit"
+	"github.com/asakaida/keruberosu/internal/infrastructure/tenant"
+	"github.com/asakaida/keruberosu/internal/infrastructure/tracing"
 	"github.com/asakaida/keruberosu/internal/infrastructure/validation"
 	"github.com/asakaida/keruberosu/internal/repositories/postgres"
 	"github.com/asakaida/keruberosu/internal/services"
 	"github.com/asakaida/keruberosu/internal/services/authorization"
 	pkgcache "github.com/asakaida/keruberosu/pkg/cache"
 	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
+	"github.com/asakaida/keruberosu/pkg/cache/rediscache"
+	"github.com/asakaida/keruberosu/pkg/cache/tieredcache"
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var (
@@ -34,6 +50,10 @@ var (
 	portFlag int
 )
 
+// gatewayBufferSize is the buffer size of the in-process listener between the
+// HTTP gateway and its gRPC server
+const gatewayBufferSize = 1 << 20
+
 var rootCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Keruberosu gRPC server",
@@ -49,42 +69,70 @@ func init() {
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Failed to execute command: %v", err)
+		fatal("Failed to execute command", "error", err)
 	}
 }
 
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
 func runServer(cmd *cobra.Command, args []string) {
-	log.Printf("Starting Keruberosu server (environment: %s)", envFlag)
+	// Log JSON lines to stderr; the level is applied once the config is loaded,
+	// and can be changed at runtime through /loglevel on the metrics port when
+	// LOG_LEVEL_CHANGE_ENABLED is set
+	logLevel := logging.Setup(os.Stderr, slog.LevelInfo)
+	slog.Info("Starting Keruberosu server", "environment", envFlag)
 
 	// Initialize configuration from .env.{env} file
 	if err := config.InitConfig(envFlag); err != nil {
-		log.Fatalf("Failed to initialize config: %v", err)
+		fatal("Failed to initialize config", "error", err)
 	}
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", "error", err)
 	}
+	level, _ := cfg.Log.ParseLevel() // Validated by Load
+	logLevel.Set(level)
 
 	// Override port if specified via flag
 	if cmd.Flags().Changed("port") {
 		cfg.Server.Port = portFlag
 	}
 
+	// Install the OpenTelemetry tracer provider before any traced component is used
+	var shutdownTracing func(context.Context) error
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  cfg.Tracing.ServiceName,
+			Exporter:     cfg.Tracing.Exporter,
+			OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+			OTLPInsecure: cfg.Tracing.OTLPInsecure,
+			SampleRatio:  cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			fatal("Failed to initialize tracing", "error", err)
+		}
+		slog.Info("Tracing enabled", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
+
 	// Connect to database cluster (primary + optional replica)
 	cluster, err := database.NewDBCluster(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", "error", err)
 	}
 	cluster.Start()
 
-	log.Printf("Connected to database: %s@%s:%d/%s",
-		cfg.Database.User,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Database)
+	slog.Info("Connected to database",
+		"user", cfg.Database.User,
+		"host", cfg.Database.Host,
+		"port", cfg.Database.Port,
+		"database", cfg.Database.Database)
 	if cfg.Database.HasReplica() {
-		log.Printf("Read replica configured: %s:%d", cfg.Database.ReplicaHost, cfg.Database.ReplicaPort)
+		slog.Info("Read replica configured", "host", cfg.Database.ReplicaHost, "port", cfg.Database.ReplicaPort)
 	}
 
 	// Initialize repositories
@@ -92,12 +140,14 @@ func runServer(cmd *cobra.Command, args []string) {
 	schemaRepo := postgres.NewPostgresSchemaRepository(cluster)
 	relationRepo := postgres.NewPostgresRelationRepository(cluster, closureExcluded)
 	attributeRepo := postgres.NewPostgresAttributeRepository(cluster)
+	auditRepo := postgres.NewPostgresAuditRepository(cluster)
+	tenantRepo := postgres.NewPostgresTenantRepository(cluster)
 
 	// Initialize services
 	schemaService := services.NewSchemaService(schemaRepo)
 	celEngine, err := authorization.NewCELEngine()
 	if err != nil {
-		log.Fatalf("Failed to create CEL engine: %v", err)
+		fatal("Failed to create CEL engine", "error", err)
 	}
 	evaluator := authorization.NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
 
@@ -106,32 +156,73 @@ func runServer(cmd *cobra.Command, args []string) {
 	var snapshotMgr *cache.SnapshotManager
 
 	if cfg.Cache.Enabled {
-		// Initialize memory cache
-		checkCache, err = memorycache.New(&memorycache.Config{
-			MaxSizeBytes:  cfg.Cache.MaxMemoryBytes,
-			DefaultTTL:    time.Duration(cfg.Cache.TTLMinutes) * time.Minute,
-			EnableMetrics: cfg.Cache.Metrics,
-		})
-		if err != nil {
-			log.Fatalf("Failed to create cache: %v", err)
+		// Initialize the cache backend: memory, redis, or memory in front of redis (tiered)
+		backend := cfg.Cache.Backend
+		if backend == "" {
+			backend = "memory"
+		}
+		cacheTTL := time.Duration(cfg.Cache.TTLMinutes) * time.Minute
+
+		var localCache, remoteCache pkgcache.Cache
+		if backend == "memory" || backend == "tiered" {
+			localCache, err = memorycache.New(&memorycache.Config{
+				MaxSizeBytes:  cfg.Cache.MaxMemoryBytes,
+				DefaultTTL:    cacheTTL,
+				EnableMetrics: cfg.Cache.Metrics,
+			})
+			if err != nil {
+				fatal("Failed to create cache", "error", err)
+			}
+		}
+		if backend == "redis" || backend == "tiered" {
+			remoteCache, err = rediscache.New(&rediscache.Config{
+				Addr:          cfg.Cache.RedisAddr,
+				Password:      cfg.Cache.RedisPassword,
+				DB:            cfg.Cache.RedisDB,
+				TLS:           cfg.Cache.RedisTLS,
+				KeyPrefix:     cfg.Cache.RedisKeyPrefix,
+				DefaultTTL:    cacheTTL,
+				Timeout:       time.Duration(cfg.Cache.RedisTimeoutMillis) * time.Millisecond,
+				EnableMetrics: cfg.Cache.Metrics,
+			})
+			if err != nil {
+				fatal("Failed to create redis cache", "error", err)
+			}
 		}
-		log.Printf("Cache enabled: maxSize=%dMB, TTL=%dm",
-			cfg.Cache.MaxMemoryBytes/(1024*1024),
-			cfg.Cache.TTLMinutes)
+
+		switch backend {
+		case "memory":
+			checkCache = localCache
+		case "redis":
+			checkCache = remoteCache
+		case "tiered":
+			checkCache = tieredcache.New(localCache, remoteCache, &tieredcache.Config{
+				LocalTTL:      time.Duration(cfg.Cache.LocalTTLSeconds) * time.Second,
+				EnableMetrics: cfg.Cache.Metrics,
+			})
+		}
+		cacheAttrs := []any{"backend", backend, "ttl_minutes", cfg.Cache.TTLMinutes}
+		if localCache != nil {
+			cacheAttrs = append(cacheAttrs, "max_size_mb", cfg.Cache.MaxMemoryBytes/(1024*1024))
+		}
+		if remoteCache != nil {
+			cacheAttrs = append(cacheAttrs, "redis_addr", cfg.Cache.RedisAddr)
+		}
+		slog.Info("Cache enabled", cacheAttrs...)
 
 		// Initialize snapshot manager for cache consistency
 		connStr := cfg.Database.ConnectionString()
 		snapshotMgr = cache.NewSnapshotManager(cluster.PrimaryDB(), connStr, 5*time.Minute)
 		if err := snapshotMgr.Start(context.Background()); err != nil {
-			log.Printf("Warning: Failed to start snapshot manager: %v (cache will use TTL-only mode)", err)
+			slog.Warn("Failed to start snapshot manager, cache will use TTL-only mode", "error", err)
 			snapshotMgr = nil
 		} else {
-			log.Println("Snapshot manager started (LISTEN/NOTIFY enabled)")
+			slog.Info("Snapshot manager started (LISTEN/NOTIFY enabled)")
 		}
 	}
 
 	// Initialize checker (with or without cache)
-	var checker authorization.CheckerInterface
+	var checker *authorization.Checker
 	if cfg.Cache.Enabled && checkCache != nil {
 		checker = authorization.NewCheckerWithCache(
 			schemaService,
@@ -143,8 +234,9 @@ func runServer(cmd *cobra.Command, args []string) {
 	} else {
 		checker = authorization.NewChecker(schemaService, evaluator)
 	}
+	checker.SetBulkCheckConcurrency(cfg.Server.BulkCheckConcurrency)
 
-	expander := authorization.NewExpander(schemaService, relationRepo)
+	expander := authorization.NewExpander(schemaService, relationRepo, attributeRepo, celEngine)
 	lookup := authorization.NewLookup(checker, schemaService, relationRepo, attributeRepo)
 
 	// Initialize metrics collector and Prometheus exporter
@@ -164,40 +256,266 @@ func runServer(cmd *cobra.Command, args []string) {
 		lookup,
 		schemaService,
 	)
+	permissionHandler.SetStatsRecorder(prometheusExporter)
+
+	// Initialize decision log if enabled
+	var decisionLogger *decisionlog.Logger
+	var decisionFileSink *decisionlog.FileSink
+	if cfg.DecisionLog.Enabled {
+		var sink decisionlog.Sink
+		switch cfg.DecisionLog.Sink {
+		case "file":
+			decisionFileSink, err = decisionlog.NewFileSink(
+				cfg.DecisionLog.FilePath,
+				int64(cfg.DecisionLog.FileMaxSizeMB)*1024*1024,
+				cfg.DecisionLog.FileMaxBackups,
+			)
+			if err != nil {
+				fatal("Failed to create decision log file sink", "error", err)
+			}
+			sink = decisionFileSink
+		case "postgres":
+			sink = postgres.NewPostgresDecisionRepository(cluster)
+		default:
+			fatal("Unknown DECISION_LOG_SINK (expected file or postgres)", "sink", cfg.DecisionLog.Sink)
+		}
+		tenantRates, err := cfg.DecisionLog.ParseTenantSampleRates()
+		if err != nil {
+			fatal("Failed to parse decision log sample rates", "error", err)
+		}
+		decisionLogger = decisionlog.NewLogger(sink, &decisionlog.Config{
+			SampleRate:        cfg.DecisionLog.SampleRate,
+			TenantSampleRates: tenantRates,
+			BufferSize:        cfg.DecisionLog.BufferSize,
+		})
+		permissionHandler.SetDecisionLogger(decisionLogger)
+		slog.Info("Decision log enabled", "sink", cfg.DecisionLog.Sink, "sample_rate", cfg.DecisionLog.SampleRate)
+	}
 	dataHandler := handlers.NewDataHandlerWithTokenGenerator(
 		relationRepo,
 		attributeRepo,
 		tokenGenerator,
 		cluster.PrimaryDB(),
 	)
+
+	// Initialize change notifier and watch service for the Watch RPC
+	changeRepo := postgres.NewPostgresChangeRepository(cluster)
+	watchPollInterval := time.Duration(cfg.Server.WatchPollIntervalMillis) * time.Millisecond
+	changeNotifier := database.NewChangeNotifier(cfg.Database.ConnectionString())
+	var changeSubscriber services.ChangeSubscriber
+	if err := changeNotifier.Start(); err != nil {
+		slog.Warn("Failed to start change notifier, watch will poll", "poll_interval", watchPollInterval.String(), "error", err)
+		changeNotifier = nil
+	} else {
+		changeSubscriber = changeNotifier
+	}
+	dataHandler.SetWatchService(services.NewWatchService(changeRepo, changeSubscriber, watchPollInterval))
+
+	// Prune the change log outside the retention window
+	var changePruner *services.ChangePruner
+	if cfg.Server.WatchChangeRetentionHours > 0 {
+		changePruner = services.NewChangePruner(changeRepo, time.Duration(cfg.Server.WatchChangeRetentionHours)*time.Hour)
+		changePruner.Start()
+	}
+
 	schemaHandler := handlers.NewSchemaHandler(
 		schemaService,
 		schemaRepo,
 	)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	tenancyHandler := handlers.NewTenancyHandler(tenantRepo)
+
+	// Reject schema and data writes to tenants not created through the Tenancy service
+	// (checked in the write's transaction, so writes cannot race Tenancy.Delete)
+	schemaHandler.SetTenantRepository(tenantRepo, cluster.PrimaryDB())
+	dataHandler.SetTenantRepository(tenantRepo)
+
+	// Route reads to the primary after writes committed in the handlers' own transactions
+	dataHandler.SetWriteRecorder(cluster)
+	schemaHandler.SetWriteRecorder(cluster)
+	tenancyHandler.SetWriteRecorder(cluster)
+
+	// Record an audit log for every schema, data and tenant mutation, in the mutation's transaction
+	if cfg.Server.AuditMutations {
+		dataHandler.SetAuditRepository(auditRepo)
+		schemaHandler.SetAuditRepository(auditRepo, cluster.PrimaryDB())
+		tenancyHandler.SetAuditRepository(auditRepo, cluster.PrimaryDB())
+		slog.Info("Mutation audit logging enabled")
+	}
 
-	// Create gRPC server with chained interceptors (metrics + validation)
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			metrics.UnaryServerInterceptor(metricsCollector, prometheusExporter),
-			validation.UnaryServerInterceptor(),
-		),
+	// Initialize health monitor, reporting each service's dependencies
+	permissionServiceName := pb.Permission_ServiceDesc.ServiceName
+	dataServiceName := pb.Data_ServiceDesc.ServiceName
+	schemaServiceName := pb.Schema_ServiceDesc.ServiceName
+	auditServiceName := pb.AuditService_ServiceDesc.ServiceName
+	tenancyServiceName := pb.Tenancy_ServiceDesc.ServiceName
+	healthMonitor := health.NewMonitor(
+		[]string{permissionServiceName, dataServiceName, schemaServiceName, auditServiceName, tenancyServiceName},
+		time.Duration(cfg.Server.HealthCheckIntervalSeconds)*time.Second,
 	)
-	pb.RegisterPermissionServer(grpcServer, permissionHandler)
-	pb.RegisterDataServer(grpcServer, dataHandler)
-	pb.RegisterSchemaServer(grpcServer, schemaHandler)
+	healthMonitor.AddCheck("database.primary", cluster.CheckPrimary)
+	if cluster.HasReplica() {
+		healthMonitor.AddCheck("database.replica", cluster.CheckReplica, permissionServiceName, dataServiceName, schemaServiceName)
+	}
+	healthMonitor.AddCheck("schema_service", schemaService.Ready, permissionServiceName, schemaServiceName)
+	if snapshotMgr != nil {
+		healthMonitor.AddCheck("snapshot_listener", snapshotMgr.CheckListener, permissionServiceName)
+	}
+	healthMonitor.Start()
+
+	// Load TLS certificate if enabled (reloaded automatically when the files change)
+	var certReloader *certs.Reloader
+	var tlsConfig *tls.Config
+	if cfg.Server.TLS.Enabled {
+		minVersion, err := cfg.Server.TLS.ParseMinVersion()
+		if err != nil {
+			fatal("Invalid TLS config", "error", err)
+		}
+		certReloader, err = certs.NewReloader(
+			cfg.Server.TLS.CertFile,
+			cfg.Server.TLS.KeyFile,
+			cfg.Server.TLS.ClientCAFile,
+			time.Duration(cfg.Server.TLS.ReloadIntervalSeconds)*time.Second,
+		)
+		if err != nil {
+			fatal("Failed to load TLS certificate", "error", err)
+		}
+		certReloader.Start()
+		tlsConfig = certReloader.TLSConfig(minVersion)
+		slog.Info("TLS enabled",
+			"cert_file", cfg.Server.TLS.CertFile,
+			"mtls", cfg.Server.TLS.ClientCAFile != "",
+			"min_version", cfg.Server.TLS.MinVersion)
+	}
+
+	// Create gRPC server with chained interceptors (metrics + logging + tenant + auth + strict tenant +
+	// log tenant + rate limit + validation). Logging comes first so requests rejected by tenant or auth
+	// are logged with a request ID. The tenant is resolved from metadata before auth checks tenant
+	// restrictions, strict mode is enforced after auth binds the tenant of the credential,
+	// and requests are logged and rate limited with the resulting tenant.
+	tenantResolver := tenant.NewResolver(cfg.Tenant.Header, cfg.Tenant.Strict)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		metrics.UnaryServerInterceptor(metricsCollector, prometheusExporter),
+		logging.UnaryServerInterceptor(),
+		tenant.UnaryServerInterceptor(tenantResolver),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		logging.StreamServerInterceptor(),
+		tenant.StreamServerInterceptor(tenantResolver),
+	}
+	if cfg.Tenant.Strict {
+		slog.Info("Strict tenant mode enabled: requests without a tenant are rejected")
+	}
+	var jwtAuthenticator *auth.JWTAuthenticator
+	if cfg.Auth.Enabled {
+		var authenticator auth.Authenticator
+		if cfg.Auth.Mode == "jwt" {
+			jwtAuthenticator, err = auth.NewJWTAuthenticator(auth.JWTConfig{
+				JWKSFile:        cfg.Auth.JWKSFile,
+				JWKSURL:         cfg.Auth.JWKSURL,
+				Issuer:          cfg.Auth.JWTIssuer,
+				Audience:        cfg.Auth.JWTAudience,
+				TenantClaim:     cfg.Auth.JWTTenantClaim,
+				ScopeClaim:      cfg.Auth.JWTScopeClaim,
+				RefreshInterval: time.Duration(cfg.Auth.JWKSRefreshIntervalSeconds) * time.Second,
+			})
+			if err != nil {
+				fatal("Failed to initialize JWT authentication", "error", err)
+			}
+			jwtAuthenticator.Start()
+			authenticator = jwtAuthenticator
+			slog.Info("Authentication enabled",
+				"mode", "jwt",
+				"issuer", cfg.Auth.JWTIssuer,
+				"tenant_claim", cfg.Auth.JWTTenantClaim)
+		} else {
+			authenticator, err = auth.NewPresharedKeyAuthenticator(cfg.Auth.KeysFile)
+			if err != nil {
+				fatal("Failed to load authentication keys", "error", err)
+			}
+			slog.Info("Authentication enabled", "mode", "preshared", "keys_file", cfg.Auth.KeysFile)
+		}
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authenticator))
+	}
+	if tenantResolver.Strict() {
+		unaryInterceptors = append(unaryInterceptors, tenant.RequireUnaryServerInterceptor(tenantResolver))
+		streamInterceptors = append(streamInterceptors, tenant.RequireStreamServerInterceptor(tenantResolver))
+	}
+	unaryInterceptors = append(unaryInterceptors, logging.UnaryTenantInterceptor())
+	streamInterceptors = append(streamInterceptors, logging.StreamTenantInterceptor())
+	var tenantDirectory *tenant.Directory
+	if cfg.RateLimit.Enabled {
+		tenantLimits, err := cfg.RateLimit.ParseTenantLimits()
+		if err != nil {
+			fatal("Invalid rate limit configuration", "error", err)
+		}
+		overrides := make(map[string]ratelimit.Limits, len(tenantLimits))
+		for tenantID, classLimits := range tenantLimits {
+			overrides[tenantID] = make(ratelimit.Limits, len(classLimits))
+			for class, limit := range classLimits {
+				overrides[tenantID][ratelimit.Class(class)] = ratelimit.Limit(limit)
+			}
+		}
+		limiter := ratelimit.NewLimiter(ratelimit.Limits{
+			ratelimit.ClassCheck:  ratelimit.Limit(cfg.RateLimit.Check),
+			ratelimit.ClassLookup: ratelimit.Limit(cfg.RateLimit.Lookup),
+			ratelimit.ClassWrite:  ratelimit.Limit(cfg.RateLimit.Write),
+		}, overrides)
+		// Unregistered tenant IDs share one bucket, so clients cannot grow the
+		// limiter state or the throttle metric labels with random tenant IDs
+		tenantDirectory = tenant.NewDirectory(tenantRepo, time.Duration(cfg.RateLimit.TenantRefreshIntervalSeconds)*time.Second)
+		tenantDirectory.Start()
+		limiter.SetTenantFilter(tenantDirectory.Contains)
+		unaryInterceptors = append(unaryInterceptors, ratelimit.UnaryServerInterceptor(limiter, prometheusExporter))
+		streamInterceptors = append(streamInterceptors, ratelimit.StreamServerInterceptor(limiter, prometheusExporter))
+		slog.Info("Rate limiting enabled",
+			"check", fmt.Sprintf("%+v", cfg.RateLimit.Check),
+			"lookup", fmt.Sprintf("%+v", cfg.RateLimit.Lookup),
+			"write", fmt.Sprintf("%+v", cfg.RateLimit.Write),
+			"tenant_overrides", len(overrides))
+	}
+	unaryInterceptors = append(unaryInterceptors, validation.UnaryServerInterceptor())
+	interceptors := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if cfg.Tracing.Enabled {
+		interceptors = append(interceptors, grpc.StatsHandler(tracing.ServerHandler()))
+	}
+	registerServices := func(s *grpc.Server) {
+		pb.RegisterPermissionServer(s, permissionHandler)
+		pb.RegisterDataServer(s, dataHandler)
+		pb.RegisterSchemaServer(s, schemaHandler)
+		pb.RegisterAuditServiceServer(s, auditHandler)
+		pb.RegisterTenancyServer(s, tenancyHandler)
+		healthpb.RegisterHealthServer(s, healthMonitor.Server())
+	}
+	serverOptions := append([]grpc.ServerOption{}, interceptors...)
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
+	registerServices(grpcServer)
 
 	// Register reflection service (for grpcurl, etc.)
 	reflection.Register(grpcServer)
 
-	// Start Prometheus metrics HTTP server
+	// Start Prometheus metrics HTTP server (also serving liveness and readiness probes,
+	// and the log level)
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsMux.Handle("/healthz", healthMonitor.LivenessHandler())
+	metricsMux.Handle("/readyz", healthMonitor.ReadinessHandler())
+	metricsMux.Handle("/loglevel", logging.LevelHandler(logLevel, cfg.Log.LevelChangeEnabled))
 	metricsServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.MetricsPort),
-		Handler: promhttp.Handler(),
+		Handler: metricsMux,
 	}
 	go func() {
-		log.Printf("Prometheus metrics server listening on :%d", cfg.Server.MetricsPort)
+		slog.Info("Prometheus metrics server listening", "port", cfg.Server.MetricsPort)
 		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Metrics server error: %v", err)
+			slog.Error("Metrics server error", "error", err)
 		}
 	}()
 
@@ -220,10 +538,10 @@ func runServer(cmd *cobra.Command, args []string) {
 	port := cfg.Server.Port
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("Failed to listen", "error", err)
 	}
 
-	log.Printf("gRPC server listening on :%d", port)
+	slog.Info("gRPC server listening", "port", port)
 
 	// Start server in a goroutine
 	serverErrors := make(chan error, 1)
@@ -233,6 +551,52 @@ func runServer(cmd *cobra.Command, args []string) {
 		}
 	}()
 
+	// Start HTTP/JSON gateway. It calls an internal gRPC server with the same services and
+	// interceptors over an in-process listener, which is not reachable from the network.
+	// The HTTP listener itself uses tlsConfig, so with mTLS HTTP clients need a client
+	// certificate just like gRPC clients.
+	var gatewayServer *http.Server
+	var gatewayGRPCServer *grpc.Server
+	var gatewayConn *grpc.ClientConn
+	if cfg.Server.HTTPPort > 0 {
+		gatewayListener := bufconn.Listen(gatewayBufferSize)
+		gatewayGRPCServer = grpc.NewServer(interceptors...)
+		registerServices(gatewayGRPCServer)
+		go func() {
+			if err := gatewayGRPCServer.Serve(gatewayListener); err != nil {
+				serverErrors <- fmt.Errorf("gateway gRPC server error: %w", err)
+			}
+		}()
+
+		gatewayConn, err = grpc.NewClient(
+			"passthrough:///gateway",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return gatewayListener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		if err != nil {
+			fatal("Failed to create gateway client", "error", err)
+		}
+		gatewayServer = &http.Server{
+			Addr:      fmt.Sprintf(":%d", cfg.Server.HTTPPort),
+			Handler:   gateway.NewHandler(gatewayConn, tenantResolver.Header()),
+			TLSConfig: tlsConfig,
+		}
+		go func() {
+			slog.Info("HTTP gateway listening", "port", cfg.Server.HTTPPort)
+			var err error
+			if tlsConfig != nil {
+				err = gatewayServer.ListenAndServeTLS("", "")
+			} else {
+				err = gatewayServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				serverErrors <- fmt.Errorf("HTTP gateway error: %w", err)
+			}
+		}()
+	}
+
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
@@ -240,21 +604,28 @@ func runServer(cmd *cobra.Command, args []string) {
 	// Wait for shutdown signal or server error
 	select {
 	case err := <-serverErrors:
-		log.Fatalf("Server error: %v", err)
+		fatal("Server error", "error", err)
 	case sig := <-sigChan:
-		log.Printf("Received signal: %v", sig)
-		log.Println("Initiating graceful shutdown...")
+		slog.Info("Received signal, initiating graceful shutdown", "signal", sig.String())
 
 		// Create shutdown context with timeout
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		// Stop metrics update goroutine
-		close(metricsStopCh)
+		// Report NOT_SERVING first so load balancers stop routing new traffic
+		healthMonitor.Shutdown()
+		if drain := time.Duration(cfg.Server.ShutdownDrainSeconds) * time.Second; drain > 0 {
+			slog.Info("Draining before stopping servers", "drain", drain.String())
+			time.Sleep(drain)
+		}
 
-		// Shutdown metrics server
-		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Error shutting down metrics server: %v", err)
+		// Shutdown HTTP gateway before the gRPC server it calls
+		if gatewayServer != nil {
+			if err := gatewayServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("Error shutting down HTTP gateway", "error", err)
+			}
+			gatewayConn.Close()
+			gatewayGRPCServer.GracefulStop()
 		}
 
 		// Channel to notify when graceful stop completes
@@ -267,32 +638,86 @@ func runServer(cmd *cobra.Command, args []string) {
 		// Wait for graceful stop or timeout
 		select {
 		case <-stopped:
-			log.Println("gRPC server stopped gracefully")
+			slog.Info("gRPC server stopped gracefully")
 		case <-shutdownCtx.Done():
-			log.Println("Shutdown timeout exceeded, forcing stop")
+			slog.Warn("Shutdown timeout exceeded, forcing stop")
 			grpcServer.Stop()
 		}
 
+		// Stop metrics update goroutine
+		close(metricsStopCh)
+
+		// Shutdown metrics server (after the gRPC server, so probes report NOT_SERVING while draining)
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Error shutting down metrics server", "error", err)
+		}
+
+		// Flush decision log (after the gRPC server stops producing decisions)
+		if decisionLogger != nil {
+			if err := decisionLogger.Close(); err != nil {
+				slog.Error("Error closing decision log", "error", err)
+			}
+		}
+		if decisionFileSink != nil {
+			if err := decisionFileSink.Close(); err != nil {
+				slog.Error("Error closing decision log file", "error", err)
+			}
+		}
+
 		// Stop snapshot manager
 		if snapshotMgr != nil {
 			if err := snapshotMgr.Stop(); err != nil {
-				log.Printf("Error stopping snapshot manager: %v", err)
+				slog.Error("Error stopping snapshot manager", "error", err)
 			}
 		}
 
+		// Stop change notifier
+		if changeNotifier != nil {
+			if err := changeNotifier.Stop(); err != nil {
+				slog.Error("Error stopping change notifier", "error", err)
+			}
+		}
+
+		// Stop pruning the change log
+		if changePruner != nil {
+			changePruner.Stop()
+		}
+
 		// Close cache
 		if checkCache != nil {
 			if err := checkCache.Close(); err != nil {
-				log.Printf("Error closing cache: %v", err)
+				slog.Error("Error closing cache", "error", err)
 			}
 		}
 
+		// Stop reloading registered tenants
+		if tenantDirectory != nil {
+			tenantDirectory.Stop()
+		}
+
+		// Stop refreshing JWKS
+		if jwtAuthenticator != nil {
+			jwtAuthenticator.Stop()
+		}
+
+		// Stop reloading TLS certificates
+		if certReloader != nil {
+			certReloader.Stop()
+		}
+
 		// Stop write tracker and close database connections
 		cluster.Stop()
 		if err := cluster.Close(); err != nil {
-			log.Printf("Error closing database connections: %v", err)
+			slog.Error("Error closing database connections", "error", err)
+		}
+
+		// Flush spans of the last requests
+		if shutdownTracing != nil {
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				slog.Error("Error shutting down tracing", "error", err)
+			}
 		}
 
-		log.Println("Shutdown complete")
+		slog.Info("Shutdown complete")
 	}
 }
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditLog represents an audit event, such as an administrative action
+type AuditLog struct {
+	ID           int64
+	EventType    string                 // Event category (e.g., "schema.write")
+	ActorID      string                 // Who performed the action
+	ActorType    string                 // Kind of actor (e.g., "user", "service")
+	ResourceType string                 // Type of the affected resource
+	ResourceID   string                 // ID of the affected resource
+	Action       string                 // What was done (e.g., "create", "delete")
+	Details      map[string]interface{} // Free-form event details
+	CreatedAt    time.Time
+}
+
+// Validate checks if the audit log is valid
+func (l *AuditLog) Validate() error {
+	if l.EventType == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if l.Action == "" {
+		return fmt.Errorf("action is required")
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package entities
+
+// ChangeOperation is the kind of a data change
+type ChangeOperation string
+
+// Data change operations
+const (
+	ChangeOperationWrite  ChangeOperation = "write"
+	ChangeOperationDelete ChangeOperation = "delete"
+)
+
+// Change represents a single relation or attribute change.
+// Exactly one of Tuple and Attribute is set; Attribute.Value is nil for deletes.
+type Change struct {
+	Operation ChangeOperation
+	Tuple     *RelationTuple
+	Attribute *Attribute
+}
+
+// ChangeSet groups the changes committed by one database transaction
+type ChangeSet struct {
+	TxID    int64     // Writing transaction ID
+	Changes []*Change // Changes in the order they were made
+}
@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// DecisionResult is the outcome of an authorization decision
+type DecisionResult string
+
+// Authorization decision outcomes
+const (
+	DecisionAllowed DecisionResult = "allowed"
+	DecisionDenied  DecisionResult = "denied"
+	DecisionError   DecisionResult = "error" // The call failed; see Decision.Error
+)
+
+// Decision records one authorization decision of the Permission service
+// (a Check, a permission of SubjectPermission, or a Lookup call)
+type Decision struct {
+	ID              int64
+	TenantID        string
+	Method          string         // RPC that made the decision (e.g., "Check", "LookupEntity")
+	EntityType      string         // Resource entity type
+	EntityID        string         // Resource entity ID (empty for LookupEntity)
+	Permission      string         // Permission or relation checked
+	SubjectType     string         // Subject type
+	SubjectID       string         // Subject ID (empty for LookupSubject)
+	SubjectRelation string         // Optional subject relation
+	Result          DecisionResult // Allowed or denied; always allowed for lookups, which return the allowed IDs
+	ResultIDs       []string       // Entity or subject IDs returned by a lookup (at most the first 1000)
+	ResultCount     int            // Number of IDs returned by a lookup
+	SchemaVersion   string         // Schema version evaluated (the requested one if the call failed)
+	SnapToken       string         // Snapshot token the decision was made at (if known)
+	CacheHit        bool           // Whether the result came from the check cache
+	Latency         time.Duration  // Time taken by the call
+	Error           string         // Error message when Result is DecisionError
+	CreatedAt       time.Time
+}
@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTenantIDLength is the length of the tenant_id columns
+const maxTenantIDLength = 255
+
+// Tenant represents a tenant registered through the Tenancy service.
+// All schemas, relations and attributes belong to exactly one tenant.
+type Tenant struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// Validate checks if the tenant is valid
+func (t *Tenant) Validate() error {
+	if t.ID == "" {
+		return fmt.Errorf("tenant id is required")
+	}
+	if len(t.ID) > maxTenantIDLength {
+		return fmt.Errorf("tenant id must be at most %d characters", maxTenantIDLength)
+	}
+	if t.Name == "" {
+		return fmt.Errorf("tenant name is required")
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxRequestBytes matches the default gRPC maximum receive message size
+const maxRequestBytes = 4 * 1024 * 1024
+
+var (
+	unmarshalOptions = protojson.UnmarshalOptions{}
+	marshalOptions   = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+// route maps an HTTP path to a gRPC method
+type route struct {
+	path        string               // Path pattern, optionally prefixed with the HTTP method (default POST)
+	method      string               // Full gRPC method name
+	newRequest  func() proto.Message // Creates an empty request message
+	newResponse func() proto.Message // Creates an empty response message
+	stream      bool                 // Server streaming: responses are written as JSON lines
+}
+
+// routes follows Permify's REST layout. Routes are POST with the request message as the
+// JSON body unless the path names another method; path wildcards set the request field
+// of the same name.
+var routes = []route{
+	// Permission service
+	{"/v1/tenants/{tenant_id}/permissions/check", pb.Permission_Check_FullMethodName,
+		func() proto.Message { return &pb.PermissionCheckRequest{} }, func() proto.Message { return &pb.PermissionCheckResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/permissions/expand", pb.Permission_Expand_FullMethodName,
+		func() proto.Message { return &pb.PermissionExpandRequest{} }, func() proto.Message { return &pb.PermissionExpandResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/permissions/lookup-entity", pb.Permission_LookupEntity_FullMethodName,
+		func() proto.Message { return &pb.PermissionLookupEntityRequest{} }, func() proto.Message { return &pb.PermissionLookupEntityResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/permissions/lookup-entity-stream", pb.Permission_LookupEntityStream_FullMethodName,
+		func() proto.Message { return &pb.PermissionLookupEntityRequest{} }, func() proto.Message { return &pb.PermissionLookupEntityStreamResponse{} }, true},
+	{"/v1/tenants/{tenant_id}/permissions/lookup-subject", pb.Permission_LookupSubject_FullMethodName,
+		func() proto.Message { return &pb.PermissionLookupSubjectRequest{} }, func() proto.Message { return &pb.PermissionLookupSubjectResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/permissions/subject-permission", pb.Permission_SubjectPermission_FullMethodName,
+		func() proto.Message { return &pb.PermissionSubjectPermissionRequest{} }, func() proto.Message { return &pb.PermissionSubjectPermissionResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/permissions/bulk-check", pb.Permission_BulkCheck_FullMethodName,
+		func() proto.Message { return &pb.PermissionBulkCheckRequest{} }, func() proto.Message { return &pb.PermissionBulkCheckResponse{} }, false},
+
+	// Data service
+	{"/v1/tenants/{tenant_id}/data/write", pb.Data_Write_FullMethodName,
+		func() proto.Message { return &pb.DataWriteRequest{} }, func() proto.Message { return &pb.DataWriteResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/data/delete", pb.Data_Delete_FullMethodName,
+		func() proto.Message { return &pb.DataDeleteRequest{} }, func() proto.Message { return &pb.DataDeleteResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/data/relationships/read", pb.Data_Read_FullMethodName,
+		func() proto.Message { return &pb.DataReadRequest{} }, func() proto.Message { return &pb.DataReadResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/data/attributes/read", pb.Data_ReadAttributes_FullMethodName,
+		func() proto.Message { return &pb.AttributeReadRequest{} }, func() proto.Message { return &pb.AttributeReadResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/watch", pb.Data_Watch_FullMethodName,
+		func() proto.Message { return &pb.DataWatchRequest{} }, func() proto.Message { return &pb.DataWatchResponse{} }, true},
+
+	// Schema service
+	{"/v1/tenants/{tenant_id}/schemas/write", pb.Schema_Write_FullMethodName,
+		func() proto.Message { return &pb.SchemaWriteRequest{} }, func() proto.Message { return &pb.SchemaWriteResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/schemas/read", pb.Schema_Read_FullMethodName,
+		func() proto.Message { return &pb.SchemaReadRequest{} }, func() proto.Message { return &pb.SchemaReadResponse{} }, false},
+	{"/v1/tenants/{tenant_id}/schemas/list", pb.Schema_List_FullMethodName,
+		func() proto.Message { return &pb.SchemaListRequest{} }, func() proto.Message { return &pb.SchemaListResponse{} }, false},
+
+	// Tenancy service
+	{"/v1/tenants/create", pb.Tenancy_Create_FullMethodName,
+		func() proto.Message { return &pb.TenantCreateRequest{} }, func() proto.Message { return &pb.TenantCreateResponse{} }, false},
+	{"/v1/tenants/list", pb.Tenancy_List_FullMethodName,
+		func() proto.Message { return &pb.TenantListRequest{} }, func() proto.Message { return &pb.TenantListResponse{} }, false},
+	{"DELETE /v1/tenants/{id}", pb.Tenancy_Delete_FullMethodName,
+		func() proto.Message { return &pb.TenantDeleteRequest{} }, func() proto.Message { return &pb.TenantDeleteResponse{} }, false},
+}
+
+// NewHandler returns an HTTP handler serving the REST API by calling the gRPC
+// services over conn, so requests go through the same interceptors as gRPC clients.
+// extraHeaders are forwarded as metadata in addition to Authorization, X-* headers
+// and the W3C trace context headers (traceparent, tracestate and baggage).
+// The request ID assigned by the server is returned as X-Request-Id.
+func NewHandler(conn grpc.ClientConnInterface, extraHeaders ...string) http.Handler {
+	forward := map[string]bool{"authorization": true, "traceparent": true, "tracestate": true, "baggage": true}
+	for _, name := range extraHeaders {
+		if name != "" {
+			forward[strings.ToLower(name)] = true
+		}
+	}
+
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		pattern := rt.path
+		if strings.HasPrefix(pattern, "/") {
+			pattern = "POST " + pattern
+		}
+		mux.Handle(pattern, &routeHandler{conn: conn, route: rt, forward: forward})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, status.Errorf(codes.NotFound, "no route for %s %s", r.Method, r.URL.Path))
+	})
+	return mux
+}
+
+// routeHandler serves one route
+type routeHandler struct {
+	conn    grpc.ClientConnInterface
+	route   route
+	forward map[string]bool // Headers forwarded as metadata besides X-* headers
+}
+
+func (h *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(w, r, h.route.path, h.route.newRequest())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx := outgoingContext(r, h.forward)
+	if h.route.stream {
+		h.serveStream(ctx, w, req)
+		return
+	}
+
+	resp := h.route.newResponse()
+	var header metadata.MD
+	err = h.conn.Invoke(ctx, h.route.method, req, resp, grpc.Header(&header))
+	setRequestID(w, header)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// serveStream writes each streamed response as a {"result": ...} JSON line.
+// An error after the first line is written as a final {"error": ...} line,
+// because the HTTP status has already been sent.
+func (h *routeHandler) serveStream(ctx context.Context, w http.ResponseWriter, req proto.Message) {
+	stream, err := h.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, h.route.method)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if header, err := stream.Header(); err == nil {
+		setRequestID(w, header)
+	}
+
+	flusher, _ := w.(http.Flusher)
+	started := false
+	for {
+		resp := h.route.newResponse()
+		err := stream.RecvMsg(resp)
+		if errors.Is(err, io.EOF) {
+			if !started {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+			}
+			return
+		}
+		if err != nil {
+			if !started {
+				writeError(w, err)
+				return
+			}
+			writeStreamLine(w, "error", status.Convert(err).Proto())
+			return
+		}
+
+		if !started {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		writeStreamLine(w, "result", resp)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// decodeRequest reads the JSON body into req and sets the fields named by the path
+// wildcards (such as tenant_id), which take precedence over the same fields in the body
+func decodeRequest(w http.ResponseWriter, r *http.Request, path string, req proto.Message) (proto.Message, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := unmarshalOptions.Unmarshal(body, req); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+	}
+
+	msg := req.ProtoReflect()
+	for _, name := range pathWildcards(path) {
+		value := r.PathValue(name)
+		if value == "" {
+			continue
+		}
+		field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			return nil, status.Errorf(codes.Internal, "request %s has no %s field", msg.Descriptor().FullName(), name)
+		}
+		msg.Set(field, protoreflect.ValueOfString(value))
+	}
+	return req, nil
+}
+
+// pathWildcards returns the wildcard names of a path pattern, e.g. tenant_id for
+// "/v1/tenants/{tenant_id}/data/write"
+func pathWildcards(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, segment[1:len(segment)-1])
+		}
+	}
+	return names
+}
+
+// outgoingContext forwards the headers in forward and x-* headers as gRPC metadata
+func outgoingContext(r *http.Request, forward map[string]bool) context.Context {
+	md := metadata.MD{}
+	for name, values := range r.Header {
+		key := strings.ToLower(name)
+		if forward[key] || strings.HasPrefix(key, "x-") {
+			md.Append(key, values...)
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		md.Append("x-forwarded-for", host)
+	}
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// setRequestID returns the request ID of the gRPC response header as X-Request-Id
+func setRequestID(w http.ResponseWriter, header metadata.MD) {
+	if values := header.Get(logging.RequestIDKey); len(values) > 0 {
+		w.Header().Set("X-Request-Id", values[0])
+	}
+}
+
+// writeJSON writes a proto message as a JSON response
+func writeJSON(w http.ResponseWriter, code int, msg proto.Message) {
+	body, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		code = http.StatusInternalServerError
+		body = []byte(fmt.Sprintf(`{"code":%d,"message":"failed to marshal response"}`, codes.Internal))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// writeStreamLine writes {"<key>": msg} followed by a newline
+func writeStreamLine(w io.Writer, key string, msg proto.Message) {
+	body, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "{%q:%s}\n", key, body)
+}
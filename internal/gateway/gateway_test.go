@@ -0,0 +1,291 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
+	"github.com/asakaida/keruberosu/internal/infrastructure/validation"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakePermissionServer records the last Check request and its metadata
+type fakePermissionServer struct {
+	pb.UnimplementedPermissionServer
+	lastCheck *pb.PermissionCheckRequest
+	lastMD    metadata.MD
+}
+
+func (s *fakePermissionServer) Check(ctx context.Context, req *pb.PermissionCheckRequest) (*pb.PermissionCheckResponse, error) {
+	s.lastCheck = req
+	s.lastMD, _ = metadata.FromIncomingContext(ctx)
+	if req.Entity.Id == "missing" {
+		return nil, status.Error(codes.NotFound, "schema not found")
+	}
+	if req.Entity.Id == "throttled" {
+		st, _ := status.New(codes.ResourceExhausted, "check rate limit exceeded").WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(1500 * time.Millisecond),
+		})
+		return nil, st.Err()
+	}
+	return &pb.PermissionCheckResponse{Can: pb.CheckResult_CHECK_RESULT_ALLOWED}, nil
+}
+
+func (s *fakePermissionServer) LookupEntityStream(req *pb.PermissionLookupEntityRequest, stream pb.Permission_LookupEntityStreamServer) error {
+	for _, id := range []string{"doc1", "doc2"} {
+		if err := stream.Send(&pb.PermissionLookupEntityStreamResponse{EntityId: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeTenancyServer echoes the tenant of each request
+type fakeTenancyServer struct {
+	pb.UnimplementedTenancyServer
+}
+
+func (s *fakeTenancyServer) Create(ctx context.Context, req *pb.TenantCreateRequest) (*pb.TenantCreateResponse, error) {
+	return &pb.TenantCreateResponse{Tenant: &pb.Tenant{Id: req.Id, Name: req.Name}}, nil
+}
+
+func (s *fakeTenancyServer) Delete(ctx context.Context, req *pb.TenantDeleteRequest) (*pb.TenantDeleteResponse, error) {
+	return &pb.TenantDeleteResponse{TenantId: req.Id}, nil
+}
+
+// newTestGateway serves fake services over an in-memory gRPC connection
+func newTestGateway(t *testing.T) (http.Handler, *fakePermissionServer) {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), validation.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor()),
+	)
+	permission := &fakePermissionServer{}
+	pb.RegisterPermissionServer(server, permission)
+	pb.RegisterTenancyServer(server, &fakeTenancyServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough://bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client connection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewHandler(conn, "Organization"), permission
+}
+
+func doRequest(handler http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+const checkBody = `{
+	"metadata": {"depth": 20},
+	"entity": {"type": "document", "id": "doc1"},
+	"permission": "view",
+	"subject": {"type": "user", "id": "alice"}
+}`
+
+func TestGateway_Check(t *testing.T) {
+	handler, permission := newTestGateway(t)
+
+	header := http.Header{
+		"Authorization": {"Bearer secret"},
+		"X-Actor-Id":    {"alice"},
+		"Organization":  {"acme"},
+		"Cookie":        {"session=1"},
+		"Traceparent":   {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"X-Request-Id":  {"req-123"},
+	}
+	rec := doRequest(handler, http.MethodPost, "/v1/tenants/t1/permissions/check", checkBody, header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("X-Request-Id"); got != "req-123" {
+		t.Errorf("expected the request ID echoed as X-Request-Id, got %q", got)
+	}
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if resp["can"] != "CHECK_RESULT_ALLOWED" {
+		t.Errorf("expected can CHECK_RESULT_ALLOWED, got %v", resp["can"])
+	}
+
+	if permission.lastCheck.TenantId != "t1" {
+		t.Errorf("expected tenant_id from path, got %q", permission.lastCheck.TenantId)
+	}
+	if permission.lastCheck.Metadata.GetDepth() != 20 {
+		t.Errorf("expected depth 20, got %d", permission.lastCheck.Metadata.GetDepth())
+	}
+	if got := permission.lastMD.Get("authorization"); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("expected authorization metadata, got %v", got)
+	}
+	if got := permission.lastMD.Get("x-actor-id"); len(got) != 1 || got[0] != "alice" {
+		t.Errorf("expected x-actor-id metadata, got %v", got)
+	}
+	if got := permission.lastMD.Get("organization"); len(got) != 1 || got[0] != "acme" {
+		t.Errorf("expected extra header forwarded as metadata, got %v", got)
+	}
+	if got := permission.lastMD.Get("traceparent"); len(got) != 1 || got[0] != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("expected traceparent metadata, got %v", got)
+	}
+	if got := permission.lastMD.Get("cookie"); len(got) != 0 {
+		t.Errorf("expected cookie not to be forwarded, got %v", got)
+	}
+}
+
+func TestGateway_Errors(t *testing.T) {
+	handler, _ := newTestGateway(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   codes.Code
+	}{
+		{"validation error", http.MethodPost, "/v1/tenants/t1/permissions/check", `{"permission": "view"}`, http.StatusBadRequest, codes.InvalidArgument},
+		{"malformed body", http.MethodPost, "/v1/tenants/t1/permissions/check", `{"entity":`, http.StatusBadRequest, codes.InvalidArgument},
+		{"not found", http.MethodPost, "/v1/tenants/t1/permissions/check", strings.Replace(checkBody, "doc1", "missing", 1), http.StatusNotFound, codes.NotFound},
+		{"rate limited", http.MethodPost, "/v1/tenants/t1/permissions/check", strings.Replace(checkBody, "doc1", "throttled", 1), http.StatusTooManyRequests, codes.ResourceExhausted},
+		{"unimplemented", http.MethodPost, "/v1/tenants/t1/schemas/read", `{}`, http.StatusNotImplemented, codes.Unimplemented},
+		{"unknown route", http.MethodPost, "/v1/tenants/t1/unknown", `{}`, http.StatusNotFound, codes.NotFound},
+		{"wrong method", http.MethodGet, "/v1/tenants/t1/permissions/check", ``, http.StatusNotFound, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(handler, tt.method, tt.path, tt.body, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body)
+			}
+			var body struct {
+				Code    int           `json:"code"`
+				Message string        `json:"message"`
+				Details []interface{} `json:"details"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON error body: %v", err)
+			}
+			if codes.Code(body.Code) != tt.wantCode || body.Message == "" {
+				t.Errorf("expected code %v with a message, got %+v", tt.wantCode, body)
+			}
+		})
+	}
+
+	t.Run("validation violations in details", func(t *testing.T) {
+		rec := doRequest(handler, http.MethodPost, "/v1/tenants/t1/permissions/check", `{"permission": "view"}`, nil)
+		if !strings.Contains(rec.Body.String(), "buf.validate.Violations") || !strings.Contains(rec.Body.String(), "entity") {
+			t.Errorf("expected buf.validate violations for entity, got %s", rec.Body)
+		}
+	})
+
+	t.Run("retry info as Retry-After", func(t *testing.T) {
+		rec := doRequest(handler, http.MethodPost, "/v1/tenants/t1/permissions/check", strings.Replace(checkBody, "doc1", "throttled", 1), nil)
+		if got := rec.Header().Get("Retry-After"); got != "2" {
+			t.Errorf("expected Retry-After 2, got %q", got)
+		}
+		if !strings.Contains(rec.Body.String(), "google.rpc.RetryInfo") {
+			t.Errorf("expected RetryInfo in details, got %s", rec.Body)
+		}
+	})
+}
+
+func TestGateway_Tenancy(t *testing.T) {
+	handler, _ := newTestGateway(t)
+
+	rec := doRequest(handler, http.MethodPost, "/v1/tenants/create", `{"id": "acme", "name": "Acme"}`, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"acme"`) {
+		t.Errorf("expected created tenant acme, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = doRequest(handler, http.MethodDelete, "/v1/tenants/acme", ``, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"tenant_id":"acme"`) {
+		t.Errorf("expected id from path, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = doRequest(handler, http.MethodPost, "/v1/tenants/acme", ``, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for POST to the delete path, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGateway_LookupEntityStream(t *testing.T) {
+	handler, _ := newTestGateway(t)
+
+	rec := doRequest(handler, http.MethodPost, "/v1/tenants/t1/permissions/lookup-entity-stream", `{"entity_type": "document"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec.Header().Get("X-Request-Id") == "" {
+		t.Error("expected a generated X-Request-Id")
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), rec.Body)
+	}
+	for i, want := range []string{"doc1", "doc2"} {
+		var line struct {
+			Result struct {
+				EntityID string `json:"entity_id"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &line); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", lines[i], err)
+		}
+		if line.Result.EntityID != want {
+			t.Errorf("line %d: expected entity_id %s, got %s", i, want, line.Result.EntityID)
+		}
+	}
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	tests := map[codes.Code]int{
+		codes.OK:                 http.StatusOK,
+		codes.InvalidArgument:    http.StatusBadRequest,
+		codes.FailedPrecondition: http.StatusBadRequest,
+		codes.NotFound:           http.StatusNotFound,
+		codes.AlreadyExists:      http.StatusConflict,
+		codes.PermissionDenied:   http.StatusForbidden,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+		codes.ResourceExhausted:  http.StatusTooManyRequests,
+		codes.Unavailable:        http.StatusServiceUnavailable,
+		codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+		codes.Internal:           http.StatusInternalServerError,
+	}
+	for code, want := range tests {
+		if got := HTTPStatusFromCode(code); got != want {
+			t.Errorf("%v: expected %d, got %d", code, want, got)
+		}
+	}
+}
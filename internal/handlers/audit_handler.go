@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// AuditHandler handles AuditService gRPC requests
+type AuditHandler struct {
+	pb.UnimplementedAuditServiceServer
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditRepo repositories.AuditRepository) *AuditHandler {
+	return &AuditHandler{
+		auditRepo: auditRepo,
+	}
+}
+
+// WriteAuditLog handles the WriteAuditLog RPC
+func (h *AuditHandler) WriteAuditLog(ctx context.Context, req *pb.WriteAuditLogRequest) (*pb.WriteAuditLogResponse, error) {
+	if req.EventType == "" {
+		return nil, status.Error(codes.InvalidArgument, "event_type is required")
+	}
+	if req.Action == "" {
+		return nil, status.Error(codes.InvalidArgument, "action is required")
+	}
+
+	tenantID := req.TenantId
+	if tenantID == "" {
+		tenantID = "default"
+	}
+
+	log := &entities.AuditLog{
+		EventType:    req.EventType,
+		ActorID:      req.ActorId,
+		ActorType:    req.ActorType,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceId,
+		Action:       req.Action,
+		Details:      req.Details.AsMap(),
+	}
+
+	if err := h.auditRepo.Write(ctx, tenantID, log); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write audit log: %v", err)
+	}
+
+	return &pb.WriteAuditLogResponse{
+		Success: true,
+	}, nil
+}
+
+// ReadAuditLogs handles the ReadAuditLogs RPC.
+// Logs are returned newest first; next_cursor is empty on the last page.
+func (h *AuditHandler) ReadAuditLogs(ctx context.Context, req *pb.ReadAuditLogsRequest) (*pb.ReadAuditLogsResponse, error) {
+	tenantID := req.TenantId
+	if tenantID == "" {
+		tenantID = "default"
+	}
+
+	filter := &repositories.AuditLogFilter{
+		EventType: req.EventType,
+		ActorID:   req.ActorId,
+	}
+	if req.StartTime != "" {
+		startTime, err := time.Parse(time.RFC3339Nano, req.StartTime)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid start_time: %v", err)
+		}
+		filter.StartTime = startTime
+	}
+	if req.EndTime != "" {
+		endTime, err := time.Parse(time.RFC3339Nano, req.EndTime)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid end_time: %v", err)
+		}
+		filter.EndTime = endTime
+	}
+	if !filter.StartTime.IsZero() && !filter.EndTime.IsZero() && !filter.StartTime.Before(filter.EndTime) {
+		return nil, status.Error(codes.InvalidArgument, "start_time must be before end_time")
+	}
+
+	if req.Cursor != "" {
+		if _, err := strconv.ParseInt(req.Cursor, 10, 64); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %s", req.Cursor)
+		}
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 100
+	}
+
+	// Fetch one extra to determine if there's a next page
+	logs, err := h.auditRepo.List(ctx, tenantID, filter, limit+1, req.Cursor)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read audit logs: %v", err)
+	}
+
+	var nextCursor string
+	if len(logs) > limit {
+		logs = logs[:limit]
+		nextCursor = strconv.FormatInt(logs[limit-1].ID, 10)
+	}
+
+	totalCount, err := h.auditRepo.Count(ctx, tenantID, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count audit logs: %v", err)
+	}
+
+	protoLogs := make([]*pb.AuditLog, 0, len(logs))
+	for _, log := range logs {
+		details, err := structpb.NewStruct(log.Details)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert audit log details: %v", err)
+		}
+		protoLogs = append(protoLogs, &pb.AuditLog{
+			Id:           strconv.FormatInt(log.ID, 10),
+			EventType:    log.EventType,
+			ActorId:      log.ActorID,
+			ActorType:    log.ActorType,
+			ResourceType: log.ResourceType,
+			ResourceId:   log.ResourceID,
+			Action:       log.Action,
+			Details:      details,
+			Timestamp:    log.CreatedAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	return &pb.ReadAuditLogsResponse{
+		Logs:       protoLogs,
+		NextCursor: nextCursor,
+		TotalCount: int32(totalCount),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// === WriteAuditLog Tests ===
+
+func TestAuditHandler_WriteAuditLog_Success(t *testing.T) {
+	repo := &mockAuditRepository{}
+	handler := NewAuditHandler(repo)
+
+	details, _ := structpb.NewStruct(map[string]interface{}{"reason": "quarterly review"})
+	resp, err := handler.WriteAuditLog(context.Background(), &pb.WriteAuditLogRequest{
+		EventType:    "admin",
+		ActorId:      "alice",
+		ActorType:    "user",
+		ResourceType: "schema",
+		ResourceId:   "v1",
+		Action:       "approve",
+		Details:      details,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Error("expected success")
+	}
+
+	if len(repo.logs) != 1 {
+		t.Fatalf("expected 1 stored log, got %d", len(repo.logs))
+	}
+	log := repo.logs[0]
+	if log.ActorID != "alice" || log.Action != "approve" || log.Details["reason"] != "quarterly review" {
+		t.Errorf("unexpected stored log: %+v", log)
+	}
+}
+
+func TestAuditHandler_WriteAuditLog_MissingFields(t *testing.T) {
+	handler := NewAuditHandler(&mockAuditRepository{})
+
+	tests := []struct {
+		name string
+		req  *pb.WriteAuditLogRequest
+	}{
+		{"missing event_type", &pb.WriteAuditLogRequest{Action: "approve"}},
+		{"missing action", &pb.WriteAuditLogRequest{EventType: "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.WriteAuditLog(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuditHandler_WriteAuditLog_RepositoryError(t *testing.T) {
+	handler := NewAuditHandler(&mockAuditRepository{
+		writeFunc: func(ctx context.Context, tenantID string, log *entities.AuditLog) error {
+			return errors.New("database error")
+		},
+	})
+
+	_, err := handler.WriteAuditLog(context.Background(), &pb.WriteAuditLogRequest{EventType: "admin", Action: "approve"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal error, got %v", err)
+	}
+}
+
+// === ReadAuditLogs Tests ===
+
+func TestAuditHandler_ReadAuditLogs_Pagination(t *testing.T) {
+	repo := &mockAuditRepository{}
+	for i := 0; i < 5; i++ {
+		eventType := "admin"
+		if i%2 == 1 {
+			eventType = "login"
+		}
+		_ = repo.Write(context.Background(), "default", &entities.AuditLog{
+			EventType: eventType,
+			Action:    fmt.Sprintf("action%d", i),
+		})
+	}
+	handler := NewAuditHandler(repo)
+
+	// admin logs: IDs 5, 3, 1 (newest first)
+	first, err := handler.ReadAuditLogs(context.Background(), &pb.ReadAuditLogsRequest{EventType: "admin", Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Logs) != 2 || first.Logs[0].Id != "5" || first.Logs[1].Id != "3" {
+		t.Fatalf("unexpected first page: %v", first.Logs)
+	}
+	if first.NextCursor != "3" {
+		t.Errorf("expected next cursor '3', got %q", first.NextCursor)
+	}
+	if first.TotalCount != 3 {
+		t.Errorf("expected total count 3, got %d", first.TotalCount)
+	}
+
+	second, err := handler.ReadAuditLogs(context.Background(), &pb.ReadAuditLogsRequest{EventType: "admin", Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Logs) != 1 || second.Logs[0].Id != "1" {
+		t.Fatalf("unexpected second page: %v", second.Logs)
+	}
+	if second.NextCursor != "" {
+		t.Errorf("expected empty next cursor on the last page, got %q", second.NextCursor)
+	}
+}
+
+func TestAuditHandler_ReadAuditLogs_InvalidArguments(t *testing.T) {
+	handler := NewAuditHandler(&mockAuditRepository{})
+
+	tests := []struct {
+		name string
+		req  *pb.ReadAuditLogsRequest
+	}{
+		{"invalid start_time", &pb.ReadAuditLogsRequest{StartTime: "yesterday"}},
+		{"invalid end_time", &pb.ReadAuditLogsRequest{EndTime: "2025-13-01"}},
+		{"start after end", &pb.ReadAuditLogsRequest{StartTime: "2025-02-01T00:00:00Z", EndTime: "2025-01-01T00:00:00Z"}},
+		{"invalid cursor", &pb.ReadAuditLogsRequest{Cursor: "abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.ReadAuditLogs(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument error, got %v", err)
+			}
+		})
+	}
+}
@@ -72,7 +72,7 @@ func setupIntegrationTest(t *testing.T) (*HandlerSet, *sql.DB) {
 	}
 	evaluator := authorization.NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
 	checker := authorization.NewChecker(schemaService, evaluator)
-	expander := authorization.NewExpander(schemaService, relationRepo)
+	expander := authorization.NewExpander(schemaService, relationRepo, attributeRepo, celEngine)
 	lookup := authorization.NewLookup(checker, schemaService, relationRepo, attributeRepo)
 
 	// Initialize new handlers
@@ -3,9 +3,12 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/repositories"
+	"github.com/asakaida/keruberosu/internal/repositories/postgres"
+	"github.com/asakaida/keruberosu/internal/services"
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -14,16 +17,28 @@ import (
 // SnapTokenGenerator generates snapshot tokens for write operations.
 // This interface allows dependency injection for different token generation strategies.
 type SnapTokenGenerator interface {
+	GenerateWriteToken(ctx context.Context, tx *sql.Tx) (string, error)
 	GenerateWriteTokenWithDB(ctx context.Context) (string, error)
 }
 
+// WriteRecorder records that a tenant has been written to, so its reads are routed
+// to the primary until replicas catch up. It is implemented by database.DBCluster.
+// Repositories record their own writes; handlers use it for the transactions they commit.
+type WriteRecorder interface {
+	RecordWrite(tenantID string)
+}
+
 // DataHandler handles Data service gRPC requests
 type DataHandler struct {
 	pb.UnimplementedDataServer
 	relationRepo   repositories.RelationRepository
 	attributeRepo  repositories.AttributeRepository
-	tokenGenerator SnapTokenGenerator // Optional: generates snapshot tokens for write responses
-	db             *sql.DB            // Optional: for transactional writes
+	tokenGenerator SnapTokenGenerator             // Optional: generates snapshot tokens for write responses
+	db             *sql.DB                        // Optional: for transactional writes
+	watchService   services.WatchServiceInterface // Optional: enables the Watch RPC
+	auditRepo      repositories.AuditRepository   // Optional: records an audit log for every Write and Delete
+	tenantRepo     repositories.TenantRepository  // Optional: rejects writes to unknown tenants
+	writeRecorder  WriteRecorder                  // Optional: records writes committed in the handler's transactions
 }
 
 // NewDataHandler creates a new DataHandler
@@ -52,6 +67,32 @@ func NewDataHandlerWithTokenGenerator(
 	}
 }
 
+// SetWatchService sets the service that streams data changes for the Watch RPC
+func (h *DataHandler) SetWatchService(watchService services.WatchServiceInterface) {
+	h.watchService = watchService
+}
+
+// SetAuditRepository enables automatic audit logs for Write and Delete.
+// Each audit log is written in the same transaction as the mutation, so it requires
+// the handler to have been created with a database (NewDataHandlerWithTokenGenerator).
+func (h *DataHandler) SetAuditRepository(auditRepo repositories.AuditRepository) {
+	h.auditRepo = auditRepo
+}
+
+// SetTenantRepository rejects Write and Delete for tenants that have not been
+// created through the Tenancy service. If the handler has a database, mutations
+// then always run in a transaction that checks the tenant, so a concurrent
+// Tenancy.Delete cannot leave orphaned rows.
+func (h *DataHandler) SetTenantRepository(tenantRepo repositories.TenantRepository) {
+	h.tenantRepo = tenantRepo
+}
+
+// SetWriteRecorder records the writes and deletes committed in the handler's own
+// transactions, for read-your-writes consistency
+func (h *DataHandler) SetWriteRecorder(writeRecorder WriteRecorder) {
+	h.writeRecorder = writeRecorder
+}
+
 // Write handles the Write RPC - writes both tuples and attributes
 func (h *DataHandler) Write(ctx context.Context, req *pb.DataWriteRequest) (*pb.DataWriteResponse, error) {
 	tenantID := req.TenantId
@@ -87,32 +128,35 @@ func (h *DataHandler) Write(ctx context.Context, req *pb.DataWriteRequest) (*pb.
 		}
 	}
 
+	if err := requireTenant(ctx, h.tenantRepo, tenantID); err != nil {
+		return nil, err
+	}
+
+	audited := h.auditRepo != nil && h.db != nil
+	var auditLog *entities.AuditLog
+	if audited {
+		auditLog = newMutationAuditLog(ctx, auditEventDataWrite, "write", "data", map[string]interface{}{
+			"tuples":     tuplesToAuditDetail(tuples),
+			"attributes": attributesToAuditDetail(attrs),
+		})
+	}
+
 	// Use transaction when writing multiple items atomically.
 	// Covers: tuples+attributes, tuples-only (handled by BatchWrite), and
 	// multiple attributes (to prevent partial writes).
-	needsTx := h.db != nil && ((hasTuples && hasAttributes) || (hasAttributes && len(attrs) > 1))
+	// Audited writes always use a transaction so the audit log commits with the data,
+	// and so do writes checking the tenant, which is locked in the transaction.
+	snapToken := ""
+	needsTx := h.db != nil && (audited || h.tenantRepo != nil || (hasTuples && hasAttributes) || (hasAttributes && len(attrs) > 1))
 	if needsTx {
-		tx, err := h.db.BeginTx(ctx, nil)
+		token, err := h.writeInTx(ctx, tenantID, tuples, attrs, auditLog)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to begin transaction: %v", err)
-		}
-		defer tx.Rollback()
-
-		if hasTuples {
-			if err := h.relationRepo.BatchWriteInTx(ctx, tx, tenantID, tuples); err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to write relations: %v", err)
+			if audited {
+				recordMutationFailure(ctx, h.auditRepo, tenantID, auditLog, err)
 			}
+			return nil, err
 		}
-
-		for _, attr := range attrs {
-			if err := h.attributeRepo.WriteInTx(ctx, tx, tenantID, attr); err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to write attribute: %v", err)
-			}
-		}
-
-		if err := tx.Commit(); err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to commit transaction: %v", err)
-		}
+		snapToken = token
 	} else {
 		if hasTuples {
 			if err := h.relationRepo.BatchWrite(ctx, tenantID, tuples); err != nil {
@@ -130,8 +174,7 @@ func (h *DataHandler) Write(ctx context.Context, req *pb.DataWriteRequest) (*pb.
 	}
 
 	// Generate snapshot token for cache consistency
-	snapToken := ""
-	if h.tokenGenerator != nil {
+	if snapToken == "" && h.tokenGenerator != nil {
 		token, err := h.tokenGenerator.GenerateWriteTokenWithDB(ctx)
 		if err == nil {
 			snapToken = token
@@ -143,18 +186,43 @@ func (h *DataHandler) Write(ctx context.Context, req *pb.DataWriteRequest) (*pb.
 	}, nil
 }
 
-// Delete handles the Delete RPC
+// Delete handles the Delete RPC - deletes tuples and/or attributes matching the filters
 func (h *DataHandler) Delete(ctx context.Context, req *pb.DataDeleteRequest) (*pb.DataDeleteResponse, error) {
-	if req.Filter == nil {
-		return nil, status.Error(codes.InvalidArgument, "filter is required")
+	if req.Filter == nil && req.AttributeFilter == nil {
+		return nil, status.Error(codes.InvalidArgument, "filter or attribute_filter is required")
 	}
 
-	// Validate filter has at least one criterion to prevent accidental mass deletion
-	hasEntityFilter := req.Filter.Entity != nil && (req.Filter.Entity.GetType() != "" || len(req.Filter.Entity.GetIds()) > 0)
-	hasSubjectFilter := req.Filter.Subject != nil && (req.Filter.Subject.GetType() != "" || len(req.Filter.Subject.GetIds()) > 0)
-	hasRelationFilter := req.Filter.GetRelation() != ""
-	if !hasEntityFilter && !hasSubjectFilter && !hasRelationFilter {
-		return nil, status.Error(codes.InvalidArgument, "filter must specify at least one of: entity, subject, or relation")
+	// Validate filters have at least one criterion to prevent accidental mass deletion
+	var relationFilter *repositories.RelationFilter
+	if req.Filter != nil {
+		hasEntityFilter := req.Filter.Entity != nil && (req.Filter.Entity.GetType() != "" || len(req.Filter.Entity.GetIds()) > 0)
+		hasSubjectFilter := req.Filter.Subject != nil && (req.Filter.Subject.GetType() != "" || len(req.Filter.Subject.GetIds()) > 0)
+		hasRelationFilter := req.Filter.GetRelation() != ""
+		if !hasEntityFilter && !hasSubjectFilter && !hasRelationFilter {
+			return nil, status.Error(codes.InvalidArgument, "filter must specify at least one of: entity, subject, or relation")
+		}
+
+		// Convert filter to repository format
+		relationFilter = &repositories.RelationFilter{
+			EntityType:      req.Filter.Entity.GetType(),
+			EntityIDs:       req.Filter.Entity.GetIds(),
+			Relation:        req.Filter.GetRelation(),
+			SubjectType:     req.Filter.Subject.GetType(),
+			SubjectIDs:      req.Filter.Subject.GetIds(),
+			SubjectRelation: req.Filter.Subject.GetRelation(),
+		}
+	}
+
+	var attributeFilter *repositories.AttributeFilter
+	if req.AttributeFilter != nil {
+		if req.AttributeFilter.Entity.GetType() == "" {
+			return nil, status.Error(codes.InvalidArgument, "attribute_filter must specify an entity type")
+		}
+		attributeFilter = &repositories.AttributeFilter{
+			EntityType: req.AttributeFilter.Entity.GetType(),
+			EntityIDs:  req.AttributeFilter.Entity.GetIds(),
+			Attributes: req.AttributeFilter.GetAttributes(),
+		}
 	}
 
 	tenantID := req.TenantId
@@ -162,23 +230,50 @@ func (h *DataHandler) Delete(ctx context.Context, req *pb.DataDeleteRequest) (*p
 		tenantID = "default"
 	}
 
-	// Convert filter to repository format
-	filter := &repositories.RelationFilter{
-		EntityType:      req.Filter.Entity.GetType(),
-		EntityIDs:       req.Filter.Entity.GetIds(),
-		Relation:        req.Filter.GetRelation(),
-		SubjectType:     req.Filter.Subject.GetType(),
-		SubjectIDs:      req.Filter.Subject.GetIds(),
-		SubjectRelation: req.Filter.Subject.GetRelation(),
+	if err := requireTenant(ctx, h.tenantRepo, tenantID); err != nil {
+		return nil, err
 	}
 
-	if err := h.relationRepo.DeleteByFilter(ctx, tenantID, filter); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to delete relations: %v", err)
+	audited := h.auditRepo != nil && h.db != nil
+	var auditLog *entities.AuditLog
+	if audited {
+		details := map[string]interface{}{}
+		if relationFilter != nil {
+			details["filter"] = relationFilterToAuditDetail(relationFilter)
+		}
+		if attributeFilter != nil {
+			details["attribute_filter"] = attributeFilterToAuditDetail(attributeFilter)
+		}
+		auditLog = newMutationAuditLog(ctx, auditEventDataDelete, "delete", "data", details)
 	}
 
-	// Generate snapshot token for cache consistency
+	// Use transaction when deleting both tuples and attributes atomically,
+	// when the audit log must commit with the deletion, or when the tenant is checked
 	snapToken := ""
-	if h.tokenGenerator != nil {
+	if h.db != nil && (audited || h.tenantRepo != nil || (relationFilter != nil && attributeFilter != nil)) {
+		token, err := h.deleteInTx(ctx, tenantID, relationFilter, attributeFilter, auditLog)
+		if err != nil {
+			if audited {
+				recordMutationFailure(ctx, h.auditRepo, tenantID, auditLog, err)
+			}
+			return nil, err
+		}
+		snapToken = token
+	} else {
+		if relationFilter != nil {
+			if err := h.relationRepo.DeleteByFilter(ctx, tenantID, relationFilter); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to delete relations: %v", err)
+			}
+		}
+		if attributeFilter != nil {
+			if err := h.attributeRepo.DeleteByFilter(ctx, tenantID, attributeFilter); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to delete attributes: %v", err)
+			}
+		}
+	}
+
+	// Generate snapshot token for cache consistency
+	if snapToken == "" && h.tokenGenerator != nil {
 		token, err := h.tokenGenerator.GenerateWriteTokenWithDB(ctx)
 		if err == nil {
 			snapToken = token
@@ -191,6 +286,91 @@ func (h *DataHandler) Delete(ctx context.Context, req *pb.DataDeleteRequest) (*p
 	}, nil
 }
 
+// writeInTx writes tuples and attributes in one transaction and returns the snap token
+// of that transaction. If auditLog is non-nil, it is recorded in the same transaction.
+func (h *DataHandler) writeInTx(ctx context.Context, tenantID string, tuples []*entities.RelationTuple, attrs []*entities.Attribute, auditLog *entities.AuditLog) (string, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockTenant(ctx, h.tenantRepo, tx, tenantID); err != nil {
+		return "", err
+	}
+
+	if len(tuples) > 0 {
+		if err := h.relationRepo.BatchWriteInTx(ctx, tx, tenantID, tuples); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to write relations: %v", err)
+		}
+	}
+
+	for _, attr := range attrs {
+		if err := h.attributeRepo.WriteInTx(ctx, tx, tenantID, attr); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to write attribute: %v", err)
+		}
+	}
+
+	return h.commitWithAudit(ctx, tx, tenantID, auditLog)
+}
+
+// deleteInTx deletes tuples and attributes matching the filters in one transaction and
+// returns the snap token of that transaction. If auditLog is non-nil, it is recorded
+// in the same transaction.
+func (h *DataHandler) deleteInTx(ctx context.Context, tenantID string, relationFilter *repositories.RelationFilter, attributeFilter *repositories.AttributeFilter, auditLog *entities.AuditLog) (string, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockTenant(ctx, h.tenantRepo, tx, tenantID); err != nil {
+		return "", err
+	}
+
+	if relationFilter != nil {
+		if err := h.relationRepo.DeleteByFilterInTx(ctx, tx, tenantID, relationFilter); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to delete relations: %v", err)
+		}
+	}
+	if attributeFilter != nil {
+		if err := h.attributeRepo.DeleteByFilterInTx(ctx, tx, tenantID, attributeFilter); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to delete attributes: %v", err)
+		}
+	}
+
+	return h.commitWithAudit(ctx, tx, tenantID, auditLog)
+}
+
+// commitWithAudit takes the snap token of tx, records auditLog (if non-nil) with that
+// token, and commits. The snap token is empty when no token generator is configured.
+func (h *DataHandler) commitWithAudit(ctx context.Context, tx *sql.Tx, tenantID string, auditLog *entities.AuditLog) (string, error) {
+	snapToken := ""
+	if h.tokenGenerator != nil {
+		token, err := h.tokenGenerator.GenerateWriteToken(ctx, tx)
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "failed to generate snap token: %v", err)
+		}
+		snapToken = token
+	}
+
+	if auditLog != nil {
+		auditLog.Details["snap_token"] = snapToken
+		auditLog.Details["outcome"] = auditOutcomeSuccess
+		if err := h.auditRepo.WriteInTx(ctx, tx, tenantID, auditLog); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to write audit log: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", status.Errorf(codes.Internal, "failed to commit transaction: %v", err)
+	}
+	if h.writeRecorder != nil {
+		h.writeRecorder.RecordWrite(tenantID)
+	}
+	return snapToken, nil
+}
+
 // Read handles the Read RPC
 func (h *DataHandler) Read(ctx context.Context, req *pb.DataReadRequest) (*pb.DataReadResponse, error) {
 	if req.Filter == nil {
@@ -229,6 +409,11 @@ func (h *DataHandler) Read(ctx context.Context, req *pb.DataReadRequest) (*pb.Da
 		pageSize = 100 // default
 	}
 
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
 	// Read from repository
 	tuples, nextToken, err := h.relationRepo.ReadByFilter(ctx, tenantID, filter, pageSize, req.ContinuousToken)
 	if err != nil {
@@ -284,6 +469,11 @@ func (h *DataHandler) ReadAttributes(ctx context.Context, req *pb.AttributeReadR
 
 	entityID := entityIDs[0]
 
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
 	// Read all attributes for the entity
 	attrMap, err := h.attributeRepo.Read(ctx, tenantID, entityType, entityID)
 	if err != nil {
@@ -328,3 +518,52 @@ func (h *DataHandler) ReadAttributes(ctx context.Context, req *pb.AttributeReadR
 		ContinuousToken: "", // TODO: implement pagination for attributes
 	}, nil
 }
+
+// Watch handles the Watch RPC.
+// Each committed transaction is sent as one DataChanges message. Clients resume
+// after a disconnect by passing the snap token of the last message received.
+// Tokens older than the change log retention fail with OutOfRange.
+func (h *DataHandler) Watch(req *pb.DataWatchRequest, stream pb.Data_WatchServer) error {
+	if h.watchService == nil {
+		return status.Error(codes.Unimplemented, "watch is not enabled")
+	}
+
+	tenantID := req.TenantId
+	if tenantID == "" {
+		tenantID = "default"
+	}
+
+	var afterTxID int64
+	if req.SnapToken != "" {
+		token, err := postgres.ParseSnapshotToken(req.SnapToken)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid snap_token: %v", err)
+		}
+		// Xmax - 1 is the newest transaction the token covers
+		afterTxID = token.Xmax - 1
+	}
+
+	ctx := stream.Context()
+	err := h.watchService.Watch(ctx, tenantID, afterTxID, func(changeSet *entities.ChangeSet) error {
+		changes, err := changeSetToProto(changeSet)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to convert changes: %v", err)
+		}
+		return stream.Send(&pb.DataWatchResponse{Changes: changes})
+	})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		if errors.Is(err, repositories.ErrChangesPruned) {
+			return status.Errorf(codes.OutOfRange, "snap_token is older than the retained change log: %v", err)
+		}
+		if _, ok := status.FromError(err); ok {
+			// Errors from stream.Send and conversion are already gRPC status errors
+			return err
+		}
+		return status.Errorf(codes.Internal, "watch failed: %v", err)
+	}
+
+	return nil
+}
@@ -317,6 +317,136 @@ func TestDataHandler_Delete_EmptyFilter(t *testing.T) {
 	}
 }
 
+func TestDataHandler_Delete_AttributeFilter(t *testing.T) {
+	relationDeleted := false
+	mockRelationRepo := &mockRelationRepository{
+		deleteByFilterFunc: func(ctx context.Context, tenantID string, filter *repositories.RelationFilter) error {
+			relationDeleted = true
+			return nil
+		},
+	}
+	var gotFilter *repositories.AttributeFilter
+	mockAttrRepo := &mockAttributeRepository{
+		deleteByFilterFunc: func(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error {
+			if tenantID != "default" {
+				t.Errorf("expected tenant ID 'default', got %s", tenantID)
+			}
+			gotFilter = filter
+			return nil
+		},
+	}
+
+	handler := NewDataHandler(mockRelationRepo, mockAttrRepo)
+
+	req := &pb.DataDeleteRequest{
+		AttributeFilter: &pb.AttributeFilter{
+			Entity:     &pb.EntityFilter{Type: "document", Ids: []string{"1", "2"}},
+			Attributes: []string{"public"},
+		},
+	}
+
+	if _, err := handler.Delete(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if relationDeleted {
+		t.Error("expected relations not to be deleted without a tuple filter")
+	}
+	if gotFilter == nil {
+		t.Fatal("expected attributes to be deleted")
+	}
+	if gotFilter.EntityType != "document" || len(gotFilter.EntityIDs) != 2 || len(gotFilter.Attributes) != 1 || gotFilter.Attributes[0] != "public" {
+		t.Errorf("unexpected attribute filter: %+v", gotFilter)
+	}
+}
+
+func TestDataHandler_Delete_TupleAndAttributeFilters(t *testing.T) {
+	relationDeleted, attributesDeleted := false, false
+	mockRelationRepo := &mockRelationRepository{
+		deleteByFilterFunc: func(ctx context.Context, tenantID string, filter *repositories.RelationFilter) error {
+			relationDeleted = true
+			return nil
+		},
+	}
+	mockAttrRepo := &mockAttributeRepository{
+		deleteByFilterFunc: func(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error {
+			attributesDeleted = true
+			return nil
+		},
+	}
+
+	handler := NewDataHandler(mockRelationRepo, mockAttrRepo)
+
+	req := &pb.DataDeleteRequest{
+		Filter: &pb.TupleFilter{
+			Entity:   &pb.EntityFilter{Type: "document", Ids: []string{"1"}},
+			Relation: "viewer",
+		},
+		AttributeFilter: &pb.AttributeFilter{
+			Entity: &pb.EntityFilter{Type: "document", Ids: []string{"1"}},
+		},
+	}
+
+	if _, err := handler.Delete(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !relationDeleted || !attributesDeleted {
+		t.Errorf("expected both relations and attributes to be deleted, got relations=%v attributes=%v", relationDeleted, attributesDeleted)
+	}
+}
+
+func TestDataHandler_AuditedMutations_RecordWrite(t *testing.T) {
+	auditRepo := &mockAuditRepository{}
+	recorder := &mockWriteRecorder{}
+	handler := NewDataHandlerWithTokenGenerator(&mockRelationRepository{}, &mockAttributeRepository{}, nil, newFakeTxDB())
+	handler.SetAuditRepository(auditRepo)
+	handler.SetWriteRecorder(recorder)
+
+	_, err := handler.Write(context.Background(), &pb.DataWriteRequest{
+		TenantId: "tenant1",
+		Tuples: []*pb.Tuple{
+			{
+				Entity:   &pb.Entity{Type: "document", Id: "1"},
+				Relation: "owner",
+				Subject:  &pb.Subject{Type: "user", Id: "alice"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	_, err = handler.Delete(context.Background(), &pb.DataDeleteRequest{
+		TenantId: "tenant1",
+		Filter: &pb.TupleFilter{
+			Entity: &pb.EntityFilter{Type: "document", Ids: []string{"1"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	if len(auditRepo.logs) != 2 {
+		t.Fatalf("expected the write and the delete to be audited in a transaction, got %d logs", len(auditRepo.logs))
+	}
+	if len(recorder.tenants) != 2 || recorder.tenants[0] != "tenant1" || recorder.tenants[1] != "tenant1" {
+		t.Errorf("expected both mutations to be recorded for tenant1, got %v", recorder.tenants)
+	}
+}
+
+func TestDataHandler_Delete_AttributeFilterWithoutEntityType(t *testing.T) {
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+
+	req := &pb.DataDeleteRequest{
+		AttributeFilter: &pb.AttributeFilter{Attributes: []string{"public"}},
+	}
+
+	_, err := handler.Delete(context.Background(), req)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
 // === Read Tests ===
 
 func TestDataHandler_Read_Success(t *testing.T) {
@@ -779,6 +909,131 @@ func TestDataHandler_ReadAttributes_BoolStringNumberValues(t *testing.T) {
 	}
 }
 
+// === Watch Tests ===
+
+func TestDataHandler_Watch_Success(t *testing.T) {
+	watchService := &mockWatchService{
+		watchFunc: func(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error {
+			if tenantID != "default" {
+				t.Errorf("expected tenant ID 'default', got %s", tenantID)
+			}
+			if afterTxID != 100 {
+				t.Errorf("expected afterTxID 100, got %d", afterTxID)
+			}
+			return send(&entities.ChangeSet{
+				TxID: 105,
+				Changes: []*entities.Change{
+					{
+						Operation: entities.ChangeOperationWrite,
+						Tuple: &entities.RelationTuple{
+							EntityType: "document", EntityID: "doc1", Relation: "viewer",
+							SubjectType: "team", SubjectID: "eng", SubjectRelation: "member",
+						},
+					},
+					{
+						Operation: entities.ChangeOperationWrite,
+						Attribute: &entities.Attribute{EntityType: "document", EntityID: "doc1", Name: "public", Value: true},
+					},
+					{
+						Operation: entities.ChangeOperationDelete,
+						Attribute: &entities.Attribute{EntityType: "document", EntityID: "doc1", Name: "owner"},
+					},
+				},
+			})
+		},
+	}
+
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+	handler.SetWatchService(watchService)
+
+	stream := &mockDataWatchStream{}
+	if err := handler.Watch(&pb.DataWatchRequest{SnapToken: "100:101:"}, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stream.sent) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(stream.sent))
+	}
+	changes := stream.sent[0].Changes
+	if changes.SnapToken != "105:106:" {
+		t.Errorf("expected snap token '105:106:', got %s", changes.SnapToken)
+	}
+	if len(changes.DataChanges) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(changes.DataChanges))
+	}
+
+	tupleChange := changes.DataChanges[0]
+	if tupleChange.Operation != pb.DataChange_OPERATION_WRITE {
+		t.Errorf("expected WRITE operation, got %v", tupleChange.Operation)
+	}
+	if tupleChange.GetTuple().GetSubject().GetRelation() != "member" {
+		t.Errorf("expected subject relation 'member', got %v", tupleChange.GetTuple())
+	}
+
+	if !changes.DataChanges[1].GetAttribute().GetValue().GetBoolValue() {
+		t.Errorf("expected attribute value true, got %v", changes.DataChanges[1].GetAttribute())
+	}
+
+	deleteChange := changes.DataChanges[2]
+	if deleteChange.Operation != pb.DataChange_OPERATION_DELETE {
+		t.Errorf("expected DELETE operation, got %v", deleteChange.Operation)
+	}
+	if deleteChange.GetAttribute().GetAttribute() != "owner" || deleteChange.GetAttribute().GetValue() != nil {
+		t.Errorf("expected deleted attribute 'owner' without value, got %v", deleteChange.GetAttribute())
+	}
+}
+
+func TestDataHandler_Watch_InvalidSnapToken(t *testing.T) {
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+	handler.SetWatchService(&mockWatchService{})
+
+	err := handler.Watch(&pb.DataWatchRequest{SnapToken: "invalid"}, &mockDataWatchStream{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestDataHandler_Watch_NotEnabled(t *testing.T) {
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+
+	err := handler.Watch(&pb.DataWatchRequest{}, &mockDataWatchStream{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented error, got %v", err)
+	}
+}
+
+func TestDataHandler_Watch_ClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+	handler.SetWatchService(&mockWatchService{
+		watchFunc: func(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	err := handler.Watch(&pb.DataWatchRequest{TenantId: "tenant1"}, &mockDataWatchStream{ctx: ctx})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("expected Canceled error, got %v", err)
+	}
+}
+
+func TestDataHandler_Watch_ChangesPruned(t *testing.T) {
+	handler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+	handler.SetWatchService(&mockWatchService{
+		watchFunc: func(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error {
+			return fmt.Errorf("%w: changes up to transaction 200 are no longer retained", repositories.ErrChangesPruned)
+		},
+	})
+
+	err := handler.Watch(&pb.DataWatchRequest{SnapToken: "100:101:"}, &mockDataWatchStream{})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected OutOfRange error, got %v", err)
+	}
+}
+
 // === interfaceToProtoValue Tests ===
 
 func TestInterfaceToProtoValue_Nil(t *testing.T) {
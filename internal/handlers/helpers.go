@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/repositories/postgres"
 	"github.com/asakaida/keruberosu/internal/services/authorization"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -46,16 +51,25 @@ func protoContextToTuplesAndAttributes(ctx *pb.Context) ([]*entities.RelationTup
 	return tuples, attrs, nil
 }
 
-func protoContextToTuples(ctx *pb.Context) ([]*entities.RelationTuple, error) {
-	tuples, _, err := protoContextToTuplesAndAttributes(ctx)
-	return tuples, err
-}
-
 func expandNodeToProto(node *authorization.ExpandNode) *pb.Expand {
 	if node == nil {
 		return nil
 	}
 
+	// 条件リーフ（ABAC / ルール呼び出し）の場合は評価に使った値を返す
+	if node.Type == "leaf" && node.Values != nil {
+		return &pb.Expand{
+			Node: &pb.Expand_Leaf{
+				Leaf: &pb.ExpandLeaf{
+					Type: &pb.ExpandLeaf_Values{
+						Values: &pb.Values{Values: valuesToProto(node.Values)},
+					},
+					Satisfiable: node.Satisfiable,
+				},
+			},
+		}
+	}
+
 	// Leafノードの場合
 	if node.Type == "leaf" {
 		subjects := &pb.Subjects{
@@ -112,6 +126,123 @@ func expandNodeToProto(node *authorization.ExpandNode) *pb.Expand {
 	}
 }
 
+// traceToProto converts an evaluation trace into its proto representation
+func traceToProto(trace *authorization.Trace) []*pb.CheckTraceNode {
+	if trace == nil {
+		return nil
+	}
+
+	nodes := make([]*pb.CheckTraceNode, 0, len(trace.Nodes))
+	for _, node := range trace.Nodes {
+		protoNode := &pb.CheckTraceNode{
+			Level:    int32(node.Level),
+			Type:     node.Type,
+			Rule:     node.Rule,
+			Entity:   node.Entity,
+			Subject:  node.Subject,
+			Result:   node.Result,
+			Matched:  node.Matched,
+			Error:    node.Error,
+			Duration: durationpb.New(node.Duration),
+		}
+		if len(node.Values) > 0 {
+			protoNode.Values = &structpb.Struct{Fields: valuesToProto(node.Values)}
+		}
+		nodes = append(nodes, protoNode)
+	}
+	return nodes
+}
+
+// withReadConsistency attaches the read consistency requested in metadata to ctx,
+// for DBCluster.ReaderFor. A snap token without an explicit consistency means
+// at-least-as-fresh as the token.
+func withReadConsistency(ctx context.Context, metadata *pb.PermissionCheckMetadata) (context.Context, error) {
+	switch metadata.GetConsistency() {
+	case pb.Consistency_CONSISTENCY_MINIMIZE_LATENCY:
+		return database.WithReadConsistency(ctx, database.ReadConsistency{Mode: database.ConsistencyMinimizeLatency}), nil
+	case pb.Consistency_CONSISTENCY_FULL:
+		return database.WithReadConsistency(ctx, database.ReadConsistency{Mode: database.ConsistencyFull}), nil
+	}
+
+	if metadata.GetSnapToken() == "" {
+		if metadata.GetConsistency() == pb.Consistency_CONSISTENCY_AT_LEAST_AS_FRESH {
+			return nil, fmt.Errorf("snap_token is required for CONSISTENCY_AT_LEAST_AS_FRESH")
+		}
+		return ctx, nil
+	}
+
+	token, err := postgres.ParseSnapshotToken(metadata.GetSnapToken())
+	if err != nil {
+		return nil, fmt.Errorf("invalid snap_token: %w", err)
+	}
+	// Xmax - 1 is the newest transaction the token covers: the write itself for
+	// tokens returned by Data.Write
+	return database.WithReadConsistency(ctx, database.ReadConsistency{
+		Mode:    database.ConsistencyAtLeastAsFresh,
+		MinTxID: token.Xmax - 1,
+	}), nil
+}
+
+// valuesToProto converts evaluation values (attributes and rule parameters) into
+// protobuf Values. Values that cannot be represented are rendered as strings so
+// that a response is never dropped because of a single attribute.
+func valuesToProto(values map[string]interface{}) map[string]*structpb.Value {
+	fields := make(map[string]*structpb.Value, len(values))
+	for key, value := range values {
+		protoValue, err := interfaceToProtoValue(value)
+		if err != nil {
+			protoValue = structpb.NewStringValue(fmt.Sprint(value))
+		}
+		fields[key] = protoValue
+	}
+	return fields
+}
+
+// changeSetToProto converts a change set into its Watch representation.
+// The snap token covers the change set's transaction, like the token returned
+// by Data.Write, so it can be used both for reads and to resume watching.
+func changeSetToProto(changeSet *entities.ChangeSet) (*pb.DataChanges, error) {
+	token := &postgres.SnapshotToken{Xmin: changeSet.TxID, Xmax: changeSet.TxID + 1}
+	dataChanges := make([]*pb.DataChange, 0, len(changeSet.Changes))
+	for _, change := range changeSet.Changes {
+		dataChange := &pb.DataChange{Operation: pb.DataChange_OPERATION_WRITE}
+		if change.Operation == entities.ChangeOperationDelete {
+			dataChange.Operation = pb.DataChange_OPERATION_DELETE
+		}
+
+		if attr := change.Attribute; attr != nil {
+			protoAttr := &pb.Attribute{
+				Entity:    &pb.Entity{Type: attr.EntityType, Id: attr.EntityID},
+				Attribute: attr.Name,
+			}
+			if attr.Value != nil {
+				protoValue, err := interfaceToProtoValue(attr.Value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert attribute %s: %w", attr.Name, err)
+				}
+				protoAttr.Value = protoValue
+			}
+			dataChange.Type = &pb.DataChange_Attribute{Attribute: protoAttr}
+		} else if tuple := change.Tuple; tuple != nil {
+			dataChange.Type = &pb.DataChange_Tuple{Tuple: &pb.Tuple{
+				Entity:   &pb.Entity{Type: tuple.EntityType, Id: tuple.EntityID},
+				Relation: tuple.Relation,
+				Subject: &pb.Subject{
+					Type:     tuple.SubjectType,
+					Id:       tuple.SubjectID,
+					Relation: tuple.SubjectRelation,
+				},
+			}}
+		}
+		dataChanges = append(dataChanges, dataChange)
+	}
+
+	return &pb.DataChanges{
+		SnapToken:   token.String(),
+		DataChanges: dataChanges,
+	}, nil
+}
+
 // parseSubjectRef parses a subject reference like "user:alice" or "team:eng#member"
 // into type, ID, and relation.
 func parseSubjectRef(ref string) (string, string, string) {
@@ -224,6 +355,26 @@ func protoToAttribute(proto *pb.Attribute) (*entities.Attribute, error) {
 	return attr, nil
 }
 
+// protoArgumentsToValues converts positional rule-call arguments to Go values.
+// A null argument converts to nil, meaning "not supplied" for that position.
+func protoArgumentsToValues(args []*structpb.Value) ([]interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		value, err := protoValueToInterface(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
 func protoValueToInterface(v *structpb.Value) (interface{}, error) {
 	if v == nil {
 		return nil, fmt.Errorf("value cannot be nil")
@@ -305,6 +456,20 @@ func interfaceToProtoValue(v interface{}) (*structpb.Value, error) {
 	}
 }
 
+// evaluationErrorCode maps errors returned by the authorization services to a
+// gRPC status code. Errors caused by the request itself (bad rule arguments, a
+// depth limit too small for the schema) are distinguished from internal failures.
+func evaluationErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, authorization.ErrInvalidRuleArgument):
+		return codes.InvalidArgument
+	case errors.Is(err, authorization.ErrMaxDepthExceeded):
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
 func handleReadSchemaError(err error) error {
 	errMsg := err.Error()
 
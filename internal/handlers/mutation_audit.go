@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/auth"
+	"github.com/asakaida/keruberosu/internal/repositories"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Event types of the audit logs recorded automatically for mutations
+const (
+	auditEventSchemaWrite  = "schema.write"
+	auditEventDataWrite    = "data.write"
+	auditEventDataDelete   = "data.delete"
+	auditEventTenantCreate = "tenant.create"
+	auditEventTenantDelete = "tenant.delete"
+)
+
+// Outcomes recorded in the "outcome" detail of mutation audit logs
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeFailure = "failure"
+)
+
+// gRPC metadata keys identifying the caller of a mutation when authentication is disabled
+const (
+	actorIDMetadataKey   = "x-actor-id"
+	actorTypeMetadataKey = "x-actor-type"
+)
+
+// actorTypePrincipal is the actor type of authenticated callers
+const actorTypePrincipal = "principal"
+
+// newMutationAuditLog builds the audit log of a mutation, with the caller identity
+// taken from the authenticated principal or the incoming gRPC metadata. The outcome
+// is filled in once the mutation finishes.
+func newMutationAuditLog(ctx context.Context, eventType, action, resourceType string, details map[string]interface{}) *entities.AuditLog {
+	actorID, actorType := callerFromContext(ctx)
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	if claimed := metadataValue(ctx, actorIDMetadataKey); claimed != "" && claimed != actorID {
+		details["claimed_actor_id"] = claimed
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		details["peer"] = p.Addr.String()
+	}
+	return &entities.AuditLog{
+		EventType:    eventType,
+		ActorID:      actorID,
+		ActorType:    actorType,
+		ResourceType: resourceType,
+		Action:       action,
+		Details:      details,
+	}
+}
+
+// callerFromContext returns the caller identity. Authenticated callers are recorded
+// by their principal name, since the x-actor-id and x-actor-type metadata are
+// client-supplied and could name anyone. Without authentication the metadata is
+// all there is; callers that send no identity are recorded as anonymous.
+func callerFromContext(ctx context.Context) (actorID, actorType string) {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Name, actorTypePrincipal
+	}
+	actorID = metadataValue(ctx, actorIDMetadataKey)
+	actorType = metadataValue(ctx, actorTypeMetadataKey)
+	if actorType == "" {
+		if actorID == "" {
+			actorType = "anonymous"
+		} else {
+			actorType = "user"
+		}
+	}
+	return actorID, actorType
+}
+
+// metadataValue returns the first value of an incoming gRPC metadata key, or ""
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// recordMutationFailure records a failed mutation. The mutation's transaction has been
+// rolled back, so the log is written on its own; errors are logged, not returned,
+// because the caller is already reporting the mutation error.
+func recordMutationFailure(ctx context.Context, auditRepo repositories.AuditRepository, tenantID string, auditLog *entities.AuditLog, mutationErr error) {
+	// A snap token or schema version taken before a failed commit was rolled back
+	delete(auditLog.Details, "snap_token")
+	delete(auditLog.Details, "schema_version")
+	auditLog.ResourceID = ""
+	auditLog.Details["outcome"] = auditOutcomeFailure
+	auditLog.Details["error"] = mutationErr.Error()
+	if err := auditRepo.Write(context.WithoutCancel(ctx), tenantID, auditLog); err != nil {
+		slog.WarnContext(ctx, "Failed to record audit log", "event_type", auditLog.EventType, "error", err)
+	}
+}
+
+// tuplesToAuditDetail converts tuples to their string form for audit log details
+func tuplesToAuditDetail(tuples []*entities.RelationTuple) []interface{} {
+	result := make([]interface{}, 0, len(tuples))
+	for _, tuple := range tuples {
+		result = append(result, tuple.String())
+	}
+	return result
+}
+
+// attributesToAuditDetail converts attributes to JSON-compatible maps for audit log details
+func attributesToAuditDetail(attrs []*entities.Attribute) []interface{} {
+	result := make([]interface{}, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, map[string]interface{}{
+			"entity":    attr.EntityType + ":" + attr.EntityID,
+			"attribute": attr.Name,
+			"value":     attr.Value,
+		})
+	}
+	return result
+}
+
+// relationFilterToAuditDetail converts a Data.Delete tuple filter to a map for audit log details
+func relationFilterToAuditDetail(filter *repositories.RelationFilter) map[string]interface{} {
+	return map[string]interface{}{
+		"entity_type":      filter.EntityType,
+		"entity_ids":       stringsToAuditDetail(filter.EntityIDs),
+		"relation":         filter.Relation,
+		"subject_type":     filter.SubjectType,
+		"subject_ids":      stringsToAuditDetail(filter.SubjectIDs),
+		"subject_relation": filter.SubjectRelation,
+	}
+}
+
+// attributeFilterToAuditDetail converts a Data.Delete attribute filter to a map for audit log details
+func attributeFilterToAuditDetail(filter *repositories.AttributeFilter) map[string]interface{} {
+	return map[string]interface{}{
+		"entity_type": filter.EntityType,
+		"entity_ids":  stringsToAuditDetail(filter.EntityIDs),
+		"attributes":  stringsToAuditDetail(filter.Attributes),
+	}
+}
+
+// stringsToAuditDetail converts a string slice to []interface{}, which structpb accepts
+func stringsToAuditDetail(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/auth"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCallerFromContext(t *testing.T) {
+	tests := []struct {
+		name          string
+		md            metadata.MD
+		principal     *auth.Principal
+		wantActorID   string
+		wantActorType string
+	}{
+		{"no metadata", nil, nil, "", "anonymous"},
+		{"actor id only", metadata.Pairs("x-actor-id", "alice"), nil, "alice", "user"},
+		{"actor id and type", metadata.Pairs("x-actor-id", "ci", "x-actor-type", "service"), nil, "ci", "service"},
+		{"principal", nil, &auth.Principal{Name: "ci-key"}, "ci-key", "principal"},
+		{"principal over metadata", metadata.Pairs("x-actor-id", "admin", "x-actor-type", "user"), &auth.Principal{Name: "ci-key"}, "ci-key", "principal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			actorID, actorType := callerFromContext(ctx)
+			if actorID != tt.wantActorID || actorType != tt.wantActorType {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.wantActorID, tt.wantActorType, actorID, actorType)
+			}
+		})
+	}
+}
+
+func TestNewMutationAuditLog(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor-id", "alice"))
+	tuples := []*entities.RelationTuple{
+		{EntityType: "document", EntityID: "1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+	}
+
+	log := newMutationAuditLog(ctx, auditEventDataWrite, "write", "data", map[string]interface{}{
+		"tuples": tuplesToAuditDetail(tuples),
+	})
+	if log.EventType != "data.write" || log.ActorID != "alice" || log.ActorType != "user" {
+		t.Errorf("unexpected audit log: %+v", log)
+	}
+	got := log.Details["tuples"].([]interface{})
+	if len(got) != 1 || got[0] != "document:1#owner@user:alice" {
+		t.Errorf("unexpected tuples detail: %v", got)
+	}
+	if err := log.Validate(); err != nil {
+		t.Errorf("expected valid audit log, got: %v", err)
+	}
+}
+
+func TestNewMutationAuditLog_ClaimedActor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor-id", "admin"))
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Name: "ci-key"})
+
+	log := newMutationAuditLog(ctx, auditEventSchemaWrite, "write", "schema", nil)
+	if log.ActorID != "ci-key" || log.ActorType != "principal" {
+		t.Errorf("expected the principal as actor, got (%q, %q)", log.ActorID, log.ActorType)
+	}
+	if log.Details["claimed_actor_id"] != "admin" {
+		t.Errorf("expected the x-actor-id to be kept as claimed_actor_id, got %v", log.Details)
+	}
+}
+
+func TestRecordMutationFailure(t *testing.T) {
+	repo := &mockAuditRepository{}
+	log := newMutationAuditLog(context.Background(), auditEventSchemaWrite, "write", "schema", nil)
+	log.ResourceID = "v1"
+	log.Details["schema_version"] = "v1"
+	log.Details["outcome"] = auditOutcomeSuccess
+
+	recordMutationFailure(context.Background(), repo, "default", log, errors.New("commit failed"))
+
+	if len(repo.logs) != 1 {
+		t.Fatalf("expected 1 stored log, got %d", len(repo.logs))
+	}
+	stored := repo.logs[0]
+	if stored.Details["outcome"] != "failure" || stored.Details["error"] != "commit failed" {
+		t.Errorf("unexpected failure details: %v", stored.Details)
+	}
+	if _, ok := stored.Details["schema_version"]; ok || stored.ResourceID != "" {
+		t.Errorf("expected rolled-back schema version to be removed, got %+v", stored)
+	}
+}
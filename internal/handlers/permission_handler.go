@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"errors"
+	"time"
 
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/repositories"
 	"github.com/asakaida/keruberosu/internal/services"
 	"github.com/asakaida/keruberosu/internal/services/authorization"
@@ -12,13 +14,34 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// CheckStatsRecorder receives the evaluation statistics of every check
+// (e.g., to export them as metrics)
+type CheckStatsRecorder interface {
+	RecordCheckStats(checkCount, queryCount, celEvaluations int)
+}
+
+// DecisionLogger receives the authorization decisions of Check, BulkCheck,
+// SubjectPermission and the Lookup RPCs (e.g., to keep a decision log).
+// Sampled is asked once per decision before anything is collected for it,
+// and only sampled decisions are passed to Log. Log must not block.
+type DecisionLogger interface {
+	Sampled(tenantID string) bool
+	Log(decision *entities.Decision)
+}
+
+// maxDecisionResultIDs caps the IDs recorded in a lookup decision;
+// Decision.ResultCount keeps the number of IDs actually returned
+const maxDecisionResultIDs = 1000
+
 // PermissionHandler handles Permission service gRPC requests
 type PermissionHandler struct {
 	pb.UnimplementedPermissionServer
-	checker       authorization.CheckerInterface
-	expander      authorization.ExpanderInterface
-	lookup        authorization.LookupInterface
-	schemaService services.SchemaServiceInterface
+	checker        authorization.CheckerInterface
+	expander       authorization.ExpanderInterface
+	lookup         authorization.LookupInterface
+	schemaService  services.SchemaServiceInterface
+	statsRecorder  CheckStatsRecorder // Optional
+	decisionLogger DecisionLogger     // Optional
 }
 
 // NewPermissionHandler creates a new PermissionHandler
@@ -36,6 +59,113 @@ func NewPermissionHandler(
 	}
 }
 
+// SetStatsRecorder sets the recorder that receives per-check evaluation statistics
+func (h *PermissionHandler) SetStatsRecorder(recorder CheckStatsRecorder) {
+	h.statsRecorder = recorder
+}
+
+// SetDecisionLogger sets the logger that receives every authorization decision
+func (h *PermissionHandler) SetDecisionLogger(logger DecisionLogger) {
+	h.decisionLogger = logger
+}
+
+// decisionSampled reports whether a decision of the tenant is to be logged.
+// It is always false without a decision logger.
+func (h *PermissionHandler) decisionSampled(tenantID string) bool {
+	return h.decisionLogger != nil && h.decisionLogger.Sampled(tenantID)
+}
+
+// logDecision samples a decision and, if sampled, records it
+func (h *PermissionHandler) logDecision(decision *entities.Decision, err error, start time.Time) {
+	if !h.decisionSampled(decision.TenantID) {
+		return
+	}
+	h.recordDecision(decision, err, start)
+}
+
+// recordDecision completes a sampled decision with its latency and error (if any)
+// and sends it to the decision logger
+func (h *PermissionHandler) recordDecision(decision *entities.Decision, err error, start time.Time) {
+	decision.Latency = time.Since(start)
+	if err != nil {
+		decision.Result = entities.DecisionError
+		decision.Error = err.Error()
+	}
+	h.decisionLogger.Log(decision)
+}
+
+// checkDecision builds the decision of a check. resp is nil when the check failed,
+// in which case the requested schema version and snapshot token are recorded.
+func checkDecision(method string, req *authorization.CheckRequest, resp *authorization.CheckResponse) *entities.Decision {
+	decision := &entities.Decision{
+		TenantID:        req.TenantID,
+		Method:          method,
+		EntityType:      req.EntityType,
+		EntityID:        req.EntityID,
+		Permission:      req.Permission,
+		SubjectType:     req.SubjectType,
+		SubjectID:       req.SubjectID,
+		SubjectRelation: req.SubjectRelation,
+		SchemaVersion:   req.SchemaVersion,
+		SnapToken:       req.SnapshotToken,
+	}
+	if resp != nil {
+		decision.Result = entities.DecisionDenied
+		if resp.Allowed {
+			decision.Result = entities.DecisionAllowed
+		}
+		decision.SchemaVersion = resp.SchemaVersion
+		decision.SnapToken = resp.SnapshotToken
+		decision.CacheHit = resp.CacheHit
+	}
+	return decision
+}
+
+// lookupEntityDecision builds the decision of a LookupEntity call with the
+// requested schema version and snapshot token; callers replace them with the
+// resolved ones and set the result IDs when the lookup succeeds
+func lookupEntityDecision(method string, req *authorization.LookupEntityRequest) *entities.Decision {
+	return &entities.Decision{
+		TenantID:        req.TenantID,
+		Method:          method,
+		EntityType:      req.EntityType,
+		Permission:      req.Permission,
+		SubjectType:     req.SubjectType,
+		SubjectID:       req.SubjectID,
+		SubjectRelation: req.SubjectRelation,
+		Result:          entities.DecisionAllowed,
+		SchemaVersion:   req.SchemaVersion,
+		SnapToken:       req.SnapshotToken,
+	}
+}
+
+// setDecisionResultIDs records the IDs returned by a lookup, keeping at most
+// maxDecisionResultIDs of them
+func setDecisionResultIDs(decision *entities.Decision, ids []string) {
+	decision.ResultCount = len(ids)
+	if len(ids) > maxDecisionResultIDs {
+		ids = ids[:maxDecisionResultIDs]
+	}
+	decision.ResultIDs = ids
+}
+
+// checkResponseMetadata reports the evaluation statistics of a check result
+// to the stats recorder and returns them as response metadata
+func (h *PermissionHandler) checkResponseMetadata(resp *authorization.CheckResponse) *pb.PermissionCheckResponseMetadata {
+	stats := resp.Stats
+	if stats == nil {
+		stats = &authorization.EvaluationStats{}
+	}
+	if h.statsRecorder != nil {
+		h.statsRecorder.RecordCheckStats(stats.CheckCount, stats.QueryCount, stats.CELEvaluations)
+	}
+	return &pb.PermissionCheckResponseMetadata{
+		CheckCount:         int32(stats.CheckCount),
+		QueryCount:         int32(stats.QueryCount),
+		CelEvaluationCount: int32(stats.CELEvaluations),
+	}
+}
+
 // Check handles the Check RPC
 func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRequest) (*pb.PermissionCheckResponse, error) {
 	if req.Entity == nil {
@@ -48,6 +178,8 @@ func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRe
 		return nil, status.Error(codes.InvalidArgument, "subject is required")
 	}
 
+	start := time.Now()
+
 	tenantID := req.TenantId
 	if tenantID == "" {
 		tenantID = "default"
@@ -55,9 +187,18 @@ func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRe
 
 	schemaVersion := ""
 	snapToken := ""
+	debug := false
+	depth := 0
 	if req.Metadata != nil {
 		schemaVersion = req.Metadata.SchemaVersion
 		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
+		debug = req.Metadata.Debug
+	}
+
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
 	}
 
 	contextualTuples, contextualAttributes, err := protoContextToTuplesAndAttributes(req.Context)
@@ -65,6 +206,11 @@ func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRe
 		return nil, status.Errorf(codes.InvalidArgument, "invalid context: %v", err)
 	}
 
+	arguments, err := protoArgumentsToValues(req.Arguments)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+	}
+
 	checkReq := &authorization.CheckRequest{
 		TenantID:             tenantID,
 		SchemaVersion:        schemaVersion,
@@ -77,11 +223,15 @@ func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRe
 		ContextualTuples:     contextualTuples,
 		ContextualAttributes: contextualAttributes,
 		SnapshotToken:        snapToken,
+		Arguments:            arguments,
+		Debug:                debug,
+		DepthLimit:           depth,
 	}
 
 	checkResp, err := h.checker.Check(ctx, checkReq)
+	h.logDecision(checkDecision("Check", checkReq, checkResp), err, start)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "check failed: %v", err)
+		return nil, status.Errorf(evaluationErrorCode(err), "check failed: %v", err)
 	}
 
 	result := pb.CheckResult_CHECK_RESULT_DENIED
@@ -89,21 +239,42 @@ func (h *PermissionHandler) Check(ctx context.Context, req *pb.PermissionCheckRe
 		result = pb.CheckResult_CHECK_RESULT_ALLOWED
 	}
 
+	metadata := h.checkResponseMetadata(checkResp)
+	metadata.Trace = traceToProto(checkResp.Trace)
+
 	return &pb.PermissionCheckResponse{
-		Can: result,
-		Metadata: &pb.PermissionCheckResponseMetadata{
-			CheckCount: 1,
-		},
+		Can:      result,
+		Metadata: metadata,
 	}, nil
 }
 
-// Expand handles the Expand RPC
-func (h *PermissionHandler) Expand(ctx context.Context, req *pb.PermissionExpandRequest) (*pb.PermissionExpandResponse, error) {
-	if req.Entity == nil {
-		return nil, status.Error(codes.InvalidArgument, "entity is required")
+// BulkCheck handles the BulkCheck RPC
+func (h *PermissionHandler) BulkCheck(ctx context.Context, req *pb.PermissionBulkCheckRequest) (*pb.PermissionBulkCheckResponse, error) {
+	start := time.Now()
+
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items are required")
 	}
-	if req.Permission == "" {
-		return nil, status.Error(codes.InvalidArgument, "permission is required")
+
+	items := make([]*authorization.BulkCheckItem, len(req.Items))
+	for i, item := range req.Items {
+		if item.Entity == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "items[%d]: entity is required", i)
+		}
+		if item.Permission == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "items[%d]: permission is required", i)
+		}
+		if item.Subject == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "items[%d]: subject is required", i)
+		}
+		items[i] = &authorization.BulkCheckItem{
+			EntityType:      item.Entity.Type,
+			EntityID:        item.Entity.Id,
+			Permission:      item.Permission,
+			SubjectType:     item.Subject.Type,
+			SubjectID:       item.Subject.Id,
+			SubjectRelation: item.Subject.GetRelation(),
+		}
 	}
 
 	tenantID := req.TenantId
@@ -113,49 +284,88 @@ func (h *PermissionHandler) Expand(ctx context.Context, req *pb.PermissionExpand
 
 	schemaVersion := ""
 	snapToken := ""
+	depth := 0
 	if req.Metadata != nil {
 		schemaVersion = req.Metadata.SchemaVersion
 		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
 	}
 
-	contextualTuples, err := protoContextToTuples(req.Context)
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	contextualTuples, contextualAttributes, err := protoContextToTuplesAndAttributes(req.Context)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid context: %v", err)
 	}
 
-	expandReq := &authorization.ExpandRequest{
-		TenantID:         tenantID,
-		SchemaVersion:    schemaVersion,
-		EntityType:       req.Entity.Type,
-		EntityID:         req.Entity.Id,
-		Permission:       req.Permission,
-		ContextualTuples: contextualTuples,
-		SnapshotToken:    snapToken,
+	arguments, err := protoArgumentsToValues(req.Arguments)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
 	}
 
-	expandResp, err := h.expander.Expand(ctx, expandReq)
+	bulkReq := &authorization.BulkCheckRequest{
+		TenantID:             tenantID,
+		SchemaVersion:        schemaVersion,
+		Items:                items,
+		ContextualTuples:     contextualTuples,
+		ContextualAttributes: contextualAttributes,
+		SnapshotToken:        snapToken,
+		Arguments:            arguments,
+		DepthLimit:           depth,
+	}
+	bulkResp, err := h.checker.BulkCheck(ctx, bulkReq)
+	if h.decisionLogger != nil {
+		for i, item := range items {
+			var itemResp *authorization.CheckResponse
+			if err == nil {
+				itemResp = bulkResp.Results[i]
+			}
+			h.logDecision(checkDecision("BulkCheck", &authorization.CheckRequest{
+				TenantID:        tenantID,
+				SchemaVersion:   schemaVersion,
+				EntityType:      item.EntityType,
+				EntityID:        item.EntityID,
+				Permission:      item.Permission,
+				SubjectType:     item.SubjectType,
+				SubjectID:       item.SubjectID,
+				SubjectRelation: item.SubjectRelation,
+				SnapshotToken:   snapToken,
+			}, itemResp), err, start)
+		}
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "expand failed: %v", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		return nil, status.Errorf(evaluationErrorCode(err), "bulk check failed: %v", err)
 	}
 
-	tree := expandNodeToProto(expandResp.Tree)
+	results := make([]*pb.PermissionCheckResponse, len(bulkResp.Results))
+	for i, r := range bulkResp.Results {
+		result := pb.CheckResult_CHECK_RESULT_DENIED
+		if r.Allowed {
+			result = pb.CheckResult_CHECK_RESULT_ALLOWED
+		}
+		results[i] = &pb.PermissionCheckResponse{
+			Can:      result,
+			Metadata: h.checkResponseMetadata(r),
+		}
+	}
 
-	return &pb.PermissionExpandResponse{
-		Tree: tree,
-	}, nil
+	return &pb.PermissionBulkCheckResponse{Results: results}, nil
 }
 
-// LookupEntity handles the LookupEntity RPC
-func (h *PermissionHandler) LookupEntity(ctx context.Context, req *pb.PermissionLookupEntityRequest) (*pb.PermissionLookupEntityResponse, error) {
-	if req.EntityType == "" {
-		return nil, status.Error(codes.InvalidArgument, "entity_type is required")
+// Expand handles the Expand RPC
+func (h *PermissionHandler) Expand(ctx context.Context, req *pb.PermissionExpandRequest) (*pb.PermissionExpandResponse, error) {
+	if req.Entity == nil {
+		return nil, status.Error(codes.InvalidArgument, "entity is required")
 	}
 	if req.Permission == "" {
 		return nil, status.Error(codes.InvalidArgument, "permission is required")
 	}
-	if req.Subject == nil {
-		return nil, status.Error(codes.InvalidArgument, "subject is required")
-	}
 
 	tenantID := req.TenantId
 	if tenantID == "" {
@@ -164,9 +374,16 @@ func (h *PermissionHandler) LookupEntity(ctx context.Context, req *pb.Permission
 
 	schemaVersion := ""
 	snapToken := ""
+	depth := 0
 	if req.Metadata != nil {
 		schemaVersion = req.Metadata.SchemaVersion
 		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
+	}
+
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
 	}
 
 	contextualTuples, contextualAttributes, err := protoContextToTuplesAndAttributes(req.Context)
@@ -174,24 +391,60 @@ func (h *PermissionHandler) LookupEntity(ctx context.Context, req *pb.Permission
 		return nil, status.Errorf(codes.InvalidArgument, "invalid context: %v", err)
 	}
 
-	lookupReq := &authorization.LookupEntityRequest{
+	arguments, err := protoArgumentsToValues(req.Arguments)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+	}
+
+	expandReq := &authorization.ExpandRequest{
 		TenantID:             tenantID,
 		SchemaVersion:        schemaVersion,
-		EntityType:           req.EntityType,
+		EntityType:           req.Entity.Type,
+		EntityID:             req.Entity.Id,
 		Permission:           req.Permission,
-		SubjectType:          req.Subject.Type,
-		SubjectID:            req.Subject.Id,
-		SubjectRelation:      req.Subject.GetRelation(),
 		ContextualTuples:     contextualTuples,
 		ContextualAttributes: contextualAttributes,
 		SnapshotToken:        snapToken,
-		PageSize:             int(req.PageSize),
-		PageToken:            req.ContinuousToken,
+		Arguments:            arguments,
+		DepthLimit:           depth,
+	}
+
+	expandResp, err := h.expander.Expand(ctx, expandReq)
+	if err != nil {
+		return nil, status.Errorf(evaluationErrorCode(err), "expand failed: %v", err)
+	}
+
+	tree := expandNodeToProto(expandResp.Tree)
+
+	return &pb.PermissionExpandResponse{
+		Tree: tree,
+	}, nil
+}
+
+// LookupEntity handles the LookupEntity RPC
+func (h *PermissionHandler) LookupEntity(ctx context.Context, req *pb.PermissionLookupEntityRequest) (*pb.PermissionLookupEntityResponse, error) {
+	start := time.Now()
+
+	lookupReq, err := toLookupEntityRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
 	}
 
 	lookupResp, err := h.lookup.LookupEntity(ctx, lookupReq)
+	decision := lookupEntityDecision("LookupEntity", lookupReq)
+	if err == nil {
+		setDecisionResultIDs(decision, lookupResp.EntityIDs)
+		decision.SchemaVersion = lookupResp.SchemaVersion
+		decision.SnapToken = lookupResp.SnapshotToken
+	}
+	h.logDecision(decision, err, start)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "lookup entity failed: %v", err)
+		return nil, status.Errorf(evaluationErrorCode(err), "lookup entity failed: %v", err)
 	}
 
 	return &pb.PermissionLookupEntityResponse{
@@ -202,6 +455,8 @@ func (h *PermissionHandler) LookupEntity(ctx context.Context, req *pb.Permission
 
 // LookupSubject handles the LookupSubject RPC
 func (h *PermissionHandler) LookupSubject(ctx context.Context, req *pb.PermissionLookupSubjectRequest) (*pb.PermissionLookupSubjectResponse, error) {
+	start := time.Now()
+
 	if req.Entity == nil {
 		return nil, status.Error(codes.InvalidArgument, "entity is required")
 	}
@@ -219,9 +474,16 @@ func (h *PermissionHandler) LookupSubject(ctx context.Context, req *pb.Permissio
 
 	schemaVersion := ""
 	snapToken := ""
+	depth := 0
 	if req.Metadata != nil {
 		schemaVersion = req.Metadata.SchemaVersion
 		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
+	}
+
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
 	}
 
 	contextualTuples, contextualAttributes, err := protoContextToTuplesAndAttributes(req.Context)
@@ -242,11 +504,30 @@ func (h *PermissionHandler) LookupSubject(ctx context.Context, req *pb.Permissio
 		SnapshotToken:        snapToken,
 		PageSize:             int(req.PageSize),
 		PageToken:            req.ContinuousToken,
+		DepthLimit:           depth,
 	}
 
 	lookupResp, err := h.lookup.LookupSubject(ctx, lookupReq)
+	decision := &entities.Decision{
+		TenantID:        tenantID,
+		Method:          "LookupSubject",
+		EntityType:      lookupReq.EntityType,
+		EntityID:        lookupReq.EntityID,
+		Permission:      lookupReq.Permission,
+		SubjectType:     lookupReq.SubjectType,
+		SubjectRelation: lookupReq.SubjectRelation,
+		Result:          entities.DecisionAllowed,
+		SchemaVersion:   schemaVersion,
+		SnapToken:       snapToken,
+	}
+	if err == nil {
+		setDecisionResultIDs(decision, lookupResp.SubjectIDs)
+		decision.SchemaVersion = lookupResp.SchemaVersion
+		decision.SnapToken = lookupResp.SnapshotToken
+	}
+	h.logDecision(decision, err, start)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "lookup subject failed: %v", err)
+		return nil, status.Errorf(evaluationErrorCode(err), "lookup subject failed: %v", err)
 	}
 
 	return &pb.PermissionLookupSubjectResponse{
@@ -255,9 +536,122 @@ func (h *PermissionHandler) LookupSubject(ctx context.Context, req *pb.Permissio
 	}, nil
 }
 
-// LookupEntityStream handles the LookupEntityStream RPC
+// LookupEntityStream handles the LookupEntityStream RPC.
+// Each entity is sent as soon as it is verified. stream.Send blocks while the
+// client's flow-control window is full, which in turn pauses the lookup.
 func (h *PermissionHandler) LookupEntityStream(req *pb.PermissionLookupEntityRequest, stream pb.Permission_LookupEntityStreamServer) error {
-	return status.Error(codes.Unimplemented, "LookupEntityStream not implemented")
+	start := time.Now()
+
+	lookupReq, err := toLookupEntityRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := withReadConsistency(stream.Context(), req.Metadata)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	// Sample up front: the stream is unbounded, so unsampled calls collect
+	// nothing and sampled ones keep only the first maxDecisionResultIDs IDs
+	sampled := h.decisionSampled(lookupReq.TenantID)
+	var sentIDs []string
+	sentCount := 0
+	lookupResp, err := h.lookup.LookupEntityStream(ctx, lookupReq, func(entityID, continuousToken string) error {
+		if err := stream.Send(&pb.PermissionLookupEntityStreamResponse{
+			EntityId:        entityID,
+			ContinuousToken: continuousToken,
+		}); err != nil {
+			return err
+		}
+		if sampled {
+			sentCount++
+			if len(sentIDs) < maxDecisionResultIDs {
+				sentIDs = append(sentIDs, entityID)
+			}
+		}
+		return nil
+	})
+	if sampled {
+		decision := lookupEntityDecision("LookupEntityStream", lookupReq)
+		decision.ResultIDs = sentIDs
+		decision.ResultCount = sentCount
+		if err == nil {
+			decision.SchemaVersion = lookupResp.SchemaVersion
+			decision.SnapToken = lookupResp.SnapshotToken
+		}
+		h.recordDecision(decision, err, start)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		if _, ok := status.FromError(err); ok {
+			// Errors from stream.Send are already gRPC status errors
+			return err
+		}
+		return status.Errorf(evaluationErrorCode(err), "lookup entity stream failed: %v", err)
+	}
+
+	return nil
+}
+
+// toLookupEntityRequest validates a LookupEntity request and converts it to
+// the authorization layer's request type
+func toLookupEntityRequest(req *pb.PermissionLookupEntityRequest) (*authorization.LookupEntityRequest, error) {
+	if req.EntityType == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity_type is required")
+	}
+	if req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission is required")
+	}
+	if req.Subject == nil {
+		return nil, status.Error(codes.InvalidArgument, "subject is required")
+	}
+
+	tenantID := req.TenantId
+	if tenantID == "" {
+		tenantID = "default"
+	}
+
+	schemaVersion := ""
+	snapToken := ""
+	depth := 0
+	if req.Metadata != nil {
+		schemaVersion = req.Metadata.SchemaVersion
+		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
+	}
+
+	contextualTuples, contextualAttributes, err := protoContextToTuplesAndAttributes(req.Context)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid context: %v", err)
+	}
+
+	var scope map[string][]string
+	if len(req.Scope) > 0 {
+		scope = make(map[string][]string, len(req.Scope))
+		for entityType, ids := range req.Scope {
+			scope[entityType] = ids.GetData()
+		}
+	}
+
+	return &authorization.LookupEntityRequest{
+		TenantID:             tenantID,
+		SchemaVersion:        schemaVersion,
+		EntityType:           req.EntityType,
+		Permission:           req.Permission,
+		SubjectType:          req.Subject.Type,
+		SubjectID:            req.Subject.Id,
+		SubjectRelation:      req.Subject.GetRelation(),
+		ContextualTuples:     contextualTuples,
+		ContextualAttributes: contextualAttributes,
+		SnapshotToken:        snapToken,
+		PageSize:             int(req.PageSize),
+		PageToken:            req.ContinuousToken,
+		DepthLimit:           depth,
+		Scope:                scope,
+	}, nil
 }
 
 // SubjectPermission handles the SubjectPermission RPC
@@ -277,12 +671,19 @@ func (h *PermissionHandler) SubjectPermission(ctx context.Context, req *pb.Permi
 	schemaVersion := ""
 	snapToken := ""
 	onlyPermission := false
+	depth := 0
 	if req.Metadata != nil {
 		schemaVersion = req.Metadata.SchemaVersion
 		snapToken = req.Metadata.SnapToken
+		depth = int(req.Metadata.Depth)
 		onlyPermission = req.Metadata.OnlyPermission
 	}
 
+	ctx, err := withReadConsistency(ctx, req.Metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
 	schema, err := h.schemaService.GetSchemaEntity(ctx, tenantID, schemaVersion)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -317,11 +718,14 @@ func (h *PermissionHandler) SubjectPermission(ctx context.Context, req *pb.Permi
 			ContextualTuples:     contextualTuples,
 			ContextualAttributes: contextualAttributes,
 			SnapshotToken:        snapToken,
+			DepthLimit:           depth,
 		}
 
+		checkStart := time.Now()
 		checkResp, err := h.checker.Check(ctx, checkReq)
+		h.logDecision(checkDecision("SubjectPermission", checkReq, checkResp), err, checkStart)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to check permission %s: %v", permission.Name, err)
+			return nil, status.Errorf(evaluationErrorCode(err), "failed to check permission %s: %v", permission.Name, err)
 		}
 
 		if checkResp.Allowed {
@@ -352,11 +756,14 @@ func (h *PermissionHandler) SubjectPermission(ctx context.Context, req *pb.Permi
 			ContextualTuples:     contextualTuples,
 			ContextualAttributes: contextualAttributes,
 			SnapshotToken:        snapToken,
+			DepthLimit:           depth,
 		}
 
+		checkStart := time.Now()
 		checkResp, err := h.checker.Check(ctx, checkReq)
+		h.logDecision(checkDecision("SubjectPermission", checkReq, checkResp), err, checkStart)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to check relation %s: %v", relation.Name, err)
+			return nil, status.Errorf(evaluationErrorCode(err), "failed to check relation %s: %v", relation.Name, err)
 		}
 
 		if checkResp.Allowed {
@@ -8,10 +8,12 @@ import (
 
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
 	"github.com/asakaida/keruberosu/internal/repositories"
 	"github.com/asakaida/keruberosu/internal/services/authorization"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // === Authorization Tests ===
@@ -110,6 +112,398 @@ func TestPermissionHandler_Check_MissingEntity(t *testing.T) {
 	}
 }
 
+func TestPermissionHandler_Check_WithArguments(t *testing.T) {
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			if len(req.Arguments) != 2 {
+				t.Fatalf("expected 2 arguments, got %d", len(req.Arguments))
+			}
+			if req.Arguments[0] != nil {
+				t.Errorf("expected null argument to convert to nil, got %v", req.Arguments[0])
+			}
+			if v, ok := req.Arguments[1].(float64); !ok || v != 18 {
+				t.Errorf("expected second argument 18, got %v", req.Arguments[1])
+			}
+			return &authorization.CheckResponse{Allowed: true}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionCheckRequest{
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+		Arguments:  []*structpb.Value{structpb.NewNullValue(), structpb.NewNumberValue(18)},
+	}
+
+	if _, err := handler.Check(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPermissionHandler_Check_InvalidRuleArgument(t *testing.T) {
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			return nil, fmt.Errorf("failed to evaluate permission: %w", authorization.ErrInvalidRuleArgument)
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionCheckRequest{
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+		Arguments:  []*structpb.Value{structpb.NewStringValue("x")},
+	}
+
+	_, err := handler.Check(context.Background(), req)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestPermissionHandler_Check_DepthLimit(t *testing.T) {
+	var gotDepthLimit int
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			gotDepthLimit = req.DepthLimit
+			return nil, fmt.Errorf("failed to evaluate permission: %w (depth limit: 5)", authorization.ErrMaxDepthExceeded)
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionCheckRequest{
+		Metadata:   &pb.PermissionCheckMetadata{Depth: 5},
+		Entity:     &pb.Entity{Type: "folder", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	_, err := handler.Check(context.Background(), req)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition error, got %v", err)
+	}
+	if gotDepthLimit != 5 {
+		t.Errorf("expected depth limit 5 to be passed to checker, got %d", gotDepthLimit)
+	}
+}
+
+func TestPermissionHandler_Check_ReadConsistency(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *pb.PermissionCheckMetadata
+		want     database.ReadConsistency
+		wantCode codes.Code
+	}{
+		{
+			name: "no metadata",
+			want: database.ReadConsistency{Mode: database.ConsistencyDefault},
+		},
+		{
+			name:     "snap token implies at least as fresh",
+			metadata: &pb.PermissionCheckMetadata{SnapToken: "100:101:"},
+			want:     database.ReadConsistency{Mode: database.ConsistencyAtLeastAsFresh, MinTxID: 100},
+		},
+		{
+			name:     "full",
+			metadata: &pb.PermissionCheckMetadata{SnapToken: "100:101:", Consistency: pb.Consistency_CONSISTENCY_FULL},
+			want:     database.ReadConsistency{Mode: database.ConsistencyFull},
+		},
+		{
+			name:     "minimize latency",
+			metadata: &pb.PermissionCheckMetadata{Consistency: pb.Consistency_CONSISTENCY_MINIMIZE_LATENCY},
+			want:     database.ReadConsistency{Mode: database.ConsistencyMinimizeLatency},
+		},
+		{
+			name:     "at least as fresh without snap token",
+			metadata: &pb.PermissionCheckMetadata{Consistency: pb.Consistency_CONSISTENCY_AT_LEAST_AS_FRESH},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid snap token",
+			metadata: &pb.PermissionCheckMetadata{SnapToken: "not-a-token"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got database.ReadConsistency
+			mockChecker := &mockChecker{
+				checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+					got = database.ReadConsistencyFromContext(ctx)
+					return &authorization.CheckResponse{Allowed: true}, nil
+				},
+			}
+			handler := NewPermissionHandler(mockChecker, &mockExpander{}, &mockLookup{}, &mockSchemaService{})
+
+			_, err := handler.Check(context.Background(), &pb.PermissionCheckRequest{
+				Metadata:   tt.metadata,
+				Entity:     &pb.Entity{Type: "document", Id: "1"},
+				Permission: "view",
+				Subject:    &pb.Subject{Type: "user", Id: "alice"},
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %v, got %v", tt.wantCode, err)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("expected read consistency %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// recordedCheckStats captures RecordCheckStats calls
+type recordedCheckStats struct {
+	calls [][3]int
+}
+
+func (r *recordedCheckStats) RecordCheckStats(checkCount, queryCount, celEvaluations int) {
+	r.calls = append(r.calls, [3]int{checkCount, queryCount, celEvaluations})
+}
+
+func TestPermissionHandler_Check_Stats(t *testing.T) {
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			return &authorization.CheckResponse{
+				Allowed: true,
+				Stats:   &authorization.EvaluationStats{CheckCount: 5, QueryCount: 4, CELEvaluations: 2},
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+	recorder := &recordedCheckStats{}
+	handler.SetStatsRecorder(recorder)
+
+	req := &pb.PermissionCheckRequest{
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	resp, err := handler.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Metadata.CheckCount != 5 || resp.Metadata.QueryCount != 4 || resp.Metadata.CelEvaluationCount != 2 {
+		t.Errorf("unexpected metadata: %v", resp.Metadata)
+	}
+	if len(recorder.calls) != 1 || recorder.calls[0] != [3]int{5, 4, 2} {
+		t.Errorf("expected stats {5 4 2} to be recorded once, got %v", recorder.calls)
+	}
+}
+
+func TestPermissionHandler_Check_Debug(t *testing.T) {
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			if !req.Debug {
+				t.Error("expected Debug to be set from metadata")
+			}
+			return &authorization.CheckResponse{
+				Allowed: true,
+				Trace: &authorization.Trace{
+					Nodes: []*authorization.TraceNode{
+						{
+							Level:   0,
+							Type:    authorization.TraceTypeABAC,
+							Rule:    "resource.public == true",
+							Entity:  "document:1",
+							Subject: "user:alice",
+							Result:  true,
+							Values:  map[string]interface{}{"resource": map[string]interface{}{"public": true}},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionCheckRequest{
+		Metadata:   &pb.PermissionCheckMetadata{Debug: true},
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	resp, err := handler.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	trace := resp.Metadata.GetTrace()
+	if len(trace) != 1 {
+		t.Fatalf("expected 1 trace node, got %d", len(trace))
+	}
+	if trace[0].Type != "abac" || trace[0].Rule != "resource.public == true" || !trace[0].Result {
+		t.Errorf("unexpected trace node: %v", trace[0])
+	}
+	public := trace[0].Values.GetFields()["resource"].GetStructValue().GetFields()["public"]
+	if !public.GetBoolValue() {
+		t.Errorf("expected resource.public=true in trace values, got %v", trace[0].Values)
+	}
+}
+
+func TestPermissionHandler_BulkCheck_Success(t *testing.T) {
+	mockChecker := &mockChecker{
+		bulkCheckFunc: func(ctx context.Context, req *authorization.BulkCheckRequest) (*authorization.BulkCheckResponse, error) {
+			if req.TenantID != "default" {
+				t.Errorf("expected tenant ID 'default', got %s", req.TenantID)
+			}
+			if req.SnapshotToken != "100:101:" {
+				t.Errorf("expected snapshot token '100:101:', got %s", req.SnapshotToken)
+			}
+			if len(req.ContextualTuples) != 1 {
+				t.Errorf("expected 1 contextual tuple, got %d", len(req.ContextualTuples))
+			}
+			if len(req.Items) != 2 {
+				t.Fatalf("expected 2 items, got %d", len(req.Items))
+			}
+			if req.Items[1].EntityID != "2" || req.Items[1].Permission != "edit" || req.Items[1].SubjectRelation != "member" {
+				t.Errorf("unexpected second item: %+v", req.Items[1])
+			}
+			return &authorization.BulkCheckResponse{
+				Results: []*authorization.CheckResponse{{Allowed: true}, {Allowed: false}},
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionBulkCheckRequest{
+		Metadata: &pb.PermissionCheckMetadata{SnapToken: "100:101:"},
+		Items: []*pb.PermissionBulkCheckRequestItem{
+			{
+				Entity:     &pb.Entity{Type: "document", Id: "1"},
+				Permission: "view",
+				Subject:    &pb.Subject{Type: "user", Id: "alice"},
+			},
+			{
+				Entity:     &pb.Entity{Type: "document", Id: "2"},
+				Permission: "edit",
+				Subject:    &pb.Subject{Type: "team", Id: "eng", Relation: "member"},
+			},
+		},
+		Context: &pb.Context{
+			Tuples: []*pb.Tuple{
+				{
+					Entity:   &pb.Entity{Type: "document", Id: "1"},
+					Relation: "viewer",
+					Subject:  &pb.Subject{Type: "user", Id: "alice"},
+				},
+			},
+		},
+	}
+
+	resp, err := handler.BulkCheck(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BulkCheck failed: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(resp.Results))
+	}
+	if resp.Results[0].Can != pb.CheckResult_CHECK_RESULT_ALLOWED {
+		t.Errorf("expected first result ALLOWED, got %v", resp.Results[0].Can)
+	}
+	if resp.Results[1].Can != pb.CheckResult_CHECK_RESULT_DENIED {
+		t.Errorf("expected second result DENIED, got %v", resp.Results[1].Can)
+	}
+}
+
+func TestPermissionHandler_BulkCheck_InvalidItem(t *testing.T) {
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionBulkCheckRequest{
+		Items: []*pb.PermissionBulkCheckRequestItem{
+			{
+				Entity:     &pb.Entity{Type: "document", Id: "1"},
+				Permission: "view",
+				Subject:    &pb.Subject{Type: "user", Id: "alice"},
+			},
+			{
+				Entity:     &pb.Entity{Type: "document", Id: "2"},
+				Permission: "view",
+			},
+		},
+	}
+
+	_, err := handler.BulkCheck(context.Background(), req)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestPermissionHandler_BulkCheck_CheckerError(t *testing.T) {
+	mockChecker := &mockChecker{
+		bulkCheckFunc: func(ctx context.Context, req *authorization.BulkCheckRequest) (*authorization.BulkCheckResponse, error) {
+			return nil, fmt.Errorf("database error")
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionBulkCheckRequest{
+		Items: []*pb.PermissionBulkCheckRequestItem{
+			{
+				Entity:     &pb.Entity{Type: "document", Id: "1"},
+				Permission: "view",
+				Subject:    &pb.Subject{Type: "user", Id: "alice"},
+			},
+		},
+	}
+
+	_, err := handler.BulkCheck(context.Background(), req)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal error, got %v", err)
+	}
+}
+
 func TestPermissionHandler_Expand_Success(t *testing.T) {
 	mockExpander := &mockExpander{
 		expandFunc: func(ctx context.Context, req *authorization.ExpandRequest) (*authorization.ExpandResponse, error) {
@@ -157,6 +551,238 @@ func TestPermissionHandler_Expand_Success(t *testing.T) {
 	}
 }
 
+func TestPermissionHandler_Expand_ConditionLeaf(t *testing.T) {
+	mockExpander := &mockExpander{
+		expandFunc: func(ctx context.Context, req *authorization.ExpandRequest) (*authorization.ExpandResponse, error) {
+			if len(req.ContextualAttributes) != 1 {
+				t.Errorf("expected 1 contextual attribute, got %d", len(req.ContextualAttributes))
+			}
+			return &authorization.ExpandResponse{
+				Tree: &authorization.ExpandNode{
+					Type:        "leaf",
+					Relation:    "abac",
+					Values:      map[string]interface{}{"resource": map[string]interface{}{"is_public": true}},
+					Satisfiable: true,
+				},
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		mockExpander,
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionExpandRequest{
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Context: &pb.Context{
+			Attributes: []*pb.Attribute{
+				{Entity: &pb.Entity{Type: "document", Id: "1"}, Attribute: "is_public", Value: structpb.NewBoolValue(true)},
+			},
+		},
+	}
+
+	resp, err := handler.Expand(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	leaf := resp.Tree.GetLeaf()
+	if leaf == nil {
+		t.Fatal("expected leaf node to be set")
+	}
+	if !leaf.Satisfiable {
+		t.Error("expected leaf to be satisfiable")
+	}
+	resource := leaf.GetValues().GetValues()["resource"].GetStructValue()
+	if resource == nil || !resource.Fields["is_public"].GetBoolValue() {
+		t.Errorf("expected resource.is_public=true in values, got %v", leaf.GetValues())
+	}
+}
+
+// recordedDecisions captures DecisionLogger.Log calls.
+// It samples every decision unless unsampled is set.
+type recordedDecisions struct {
+	unsampled    bool
+	sampledCalls int
+	decisions    []*entities.Decision
+}
+
+func (r *recordedDecisions) Sampled(tenantID string) bool {
+	r.sampledCalls++
+	return !r.unsampled
+}
+
+func (r *recordedDecisions) Log(decision *entities.Decision) {
+	r.decisions = append(r.decisions, decision)
+}
+
+func TestPermissionHandler_Check_DecisionLog(t *testing.T) {
+	mockChecker := &mockChecker{
+		checkFunc: func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
+			if req.EntityID == "broken" {
+				return nil, errors.New("storage unavailable")
+			}
+			return &authorization.CheckResponse{
+				Allowed:       true,
+				SchemaVersion: "v3",
+				SnapshotToken: "100:101:",
+				CacheHit:      true,
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		mockChecker,
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+	logger := &recordedDecisions{}
+	handler.SetDecisionLogger(logger)
+
+	req := &pb.PermissionCheckRequest{
+		TenantId:   "tenant1",
+		Entity:     &pb.Entity{Type: "document", Id: "1"},
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+	if _, err := handler.Check(context.Background(), req); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	req.Entity.Id = "broken"
+	if _, err := handler.Check(context.Background(), req); err == nil {
+		t.Fatal("expected check error")
+	}
+
+	if len(logger.decisions) != 2 {
+		t.Fatalf("expected 2 decisions, got %d", len(logger.decisions))
+	}
+	allowed := logger.decisions[0]
+	if allowed.TenantID != "tenant1" || allowed.Method != "Check" || allowed.EntityID != "1" ||
+		allowed.Permission != "view" || allowed.SubjectID != "alice" {
+		t.Errorf("unexpected decision: %+v", allowed)
+	}
+	if allowed.Result != entities.DecisionAllowed || allowed.SchemaVersion != "v3" ||
+		allowed.SnapToken != "100:101:" || !allowed.CacheHit {
+		t.Errorf("unexpected decision outcome: %+v", allowed)
+	}
+	failed := logger.decisions[1]
+	if failed.Result != entities.DecisionError || failed.Error != "storage unavailable" {
+		t.Errorf("expected error decision, got %+v", failed)
+	}
+}
+
+func TestPermissionHandler_LookupEntity_DecisionLog(t *testing.T) {
+	mockLookup := &mockLookup{
+		lookupEntityFunc: func(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error) {
+			return &authorization.LookupEntityResponse{
+				EntityIDs:     []string{"doc1", "doc2"},
+				SchemaVersion: "v3",
+				SnapshotToken: "100:101:",
+			}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		mockLookup,
+		&mockSchemaService{},
+	)
+	logger := &recordedDecisions{}
+	handler.SetDecisionLogger(logger)
+
+	_, err := handler.LookupEntity(context.Background(), &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(logger.decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(logger.decisions))
+	}
+	decision := logger.decisions[0]
+	if decision.Method != "LookupEntity" || decision.TenantID != "default" || decision.Result != entities.DecisionAllowed {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+	if len(decision.ResultIDs) != 2 || decision.ResultIDs[0] != "doc1" {
+		t.Errorf("expected result IDs [doc1 doc2], got %v", decision.ResultIDs)
+	}
+	// The schema version and snapshot token the lookup resolved are recorded,
+	// not the empty ones of the request
+	if decision.SchemaVersion != "v3" || decision.SnapToken != "100:101:" {
+		t.Errorf("expected decision evaluated at v3/100:101:, got %s/%s", decision.SchemaVersion, decision.SnapToken)
+	}
+}
+
+func TestPermissionHandler_LookupEntityStream_DecisionLog(t *testing.T) {
+	total := maxDecisionResultIDs + 5
+	mockLookup := &mockLookup{
+		lookupEntityStreamFunc: func(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error) {
+			for i := 0; i < total; i++ {
+				id := fmt.Sprintf("doc%05d", i)
+				if err := send(id, id); err != nil {
+					return nil, err
+				}
+			}
+			return &authorization.LookupEntityStreamResponse{SchemaVersion: "v3", SnapshotToken: "100:101:"}, nil
+		},
+	}
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	t.Run("sampled streams record a capped list of IDs", func(t *testing.T) {
+		handler := NewPermissionHandler(&mockChecker{}, &mockExpander{}, mockLookup, &mockSchemaService{})
+		logger := &recordedDecisions{}
+		handler.SetDecisionLogger(logger)
+
+		if err := handler.LookupEntityStream(req, &mockLookupEntityStream{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if logger.sampledCalls != 1 || len(logger.decisions) != 1 {
+			t.Fatalf("expected one sampling decision and one logged decision, got %d and %d", logger.sampledCalls, len(logger.decisions))
+		}
+		decision := logger.decisions[0]
+		if len(decision.ResultIDs) != maxDecisionResultIDs || decision.ResultCount != total {
+			t.Errorf("expected %d of %d result IDs, got %d of %d", maxDecisionResultIDs, total, len(decision.ResultIDs), decision.ResultCount)
+		}
+		if decision.ResultIDs[0] != "doc00000" {
+			t.Errorf("expected the first streamed IDs to be kept, got %s", decision.ResultIDs[0])
+		}
+		if decision.SchemaVersion != "v3" || decision.SnapToken != "100:101:" {
+			t.Errorf("expected decision evaluated at v3/100:101:, got %s/%s", decision.SchemaVersion, decision.SnapToken)
+		}
+	})
+
+	t.Run("unsampled streams record nothing", func(t *testing.T) {
+		handler := NewPermissionHandler(&mockChecker{}, &mockExpander{}, mockLookup, &mockSchemaService{})
+		logger := &recordedDecisions{unsampled: true}
+		handler.SetDecisionLogger(logger)
+
+		stream := &mockLookupEntityStream{}
+		if err := handler.LookupEntityStream(req, stream); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(stream.sent) != total {
+			t.Errorf("expected %d streamed entities, got %d", total, len(stream.sent))
+		}
+		if logger.sampledCalls != 1 || len(logger.decisions) != 0 {
+			t.Errorf("expected one sampling decision and no logged decision, got %d and %d", logger.sampledCalls, len(logger.decisions))
+		}
+	})
+}
+
 func TestPermissionHandler_LookupEntity_Success(t *testing.T) {
 	mockLookup := &mockLookup{
 		lookupEntityFunc: func(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error) {
@@ -193,6 +819,160 @@ func TestPermissionHandler_LookupEntity_Success(t *testing.T) {
 	}
 }
 
+func TestPermissionHandler_LookupEntity_Scope(t *testing.T) {
+	mockLookup := &mockLookup{
+		lookupEntityFunc: func(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error) {
+			ids := req.Scope["document"]
+			if len(ids) != 2 || ids[0] != "doc1" || ids[1] != "doc2" {
+				t.Errorf("expected document scope [doc1 doc2], got %v", ids)
+			}
+			return &authorization.LookupEntityResponse{EntityIDs: ids}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		mockLookup,
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+		Scope: map[string]*pb.StringArrayValue{
+			"document": {Data: []string{"doc1", "doc2"}},
+		},
+	}
+
+	if _, err := handler.LookupEntity(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPermissionHandler_LookupEntityStream_Success(t *testing.T) {
+	mockLookup := &mockLookup{
+		lookupEntityStreamFunc: func(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error) {
+			if req.TenantID != "default" {
+				t.Errorf("expected tenant ID 'default', got %s", req.TenantID)
+			}
+			if req.PageToken != "doc0" {
+				t.Errorf("expected page token 'doc0', got %s", req.PageToken)
+			}
+			for _, id := range []string{"doc1", "doc2"} {
+				if err := send(id, id); err != nil {
+					return nil, err
+				}
+			}
+			return &authorization.LookupEntityStreamResponse{}, nil
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		mockLookup,
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType:      "document",
+		Permission:      "view",
+		Subject:         &pb.Subject{Type: "user", Id: "alice"},
+		ContinuousToken: "doc0",
+	}
+
+	stream := &mockLookupEntityStream{}
+	if err := handler.LookupEntityStream(req, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stream.sent) != 2 {
+		t.Fatalf("expected 2 streamed entities, got %d", len(stream.sent))
+	}
+	if stream.sent[1].EntityId != "doc2" || stream.sent[1].ContinuousToken != "doc2" {
+		t.Errorf("unexpected second item: %v", stream.sent[1])
+	}
+}
+
+func TestPermissionHandler_LookupEntityStream_MissingSubject(t *testing.T) {
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		&mockLookup{},
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+	}
+
+	err := handler.LookupEntityStream(req, &mockLookupEntityStream{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestPermissionHandler_LookupEntityStream_ClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockLookup := &mockLookup{
+		lookupEntityStreamFunc: func(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error) {
+			if err := send("doc1", "doc1"); err != nil {
+				return nil, err
+			}
+			cancel()
+			return nil, ctx.Err()
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		mockLookup,
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	err := handler.LookupEntityStream(req, &mockLookupEntityStream{ctx: ctx})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("expected Canceled error, got %v", err)
+	}
+}
+
+func TestPermissionHandler_LookupEntityStream_LookupError(t *testing.T) {
+	mockLookup := &mockLookup{
+		lookupEntityStreamFunc: func(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	handler := NewPermissionHandler(
+		&mockChecker{},
+		&mockExpander{},
+		mockLookup,
+		&mockSchemaService{},
+	)
+
+	req := &pb.PermissionLookupEntityRequest{
+		EntityType: "document",
+		Permission: "view",
+		Subject:    &pb.Subject{Type: "user", Id: "alice"},
+	}
+
+	err := handler.LookupEntityStream(req, &mockLookupEntityStream{})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal error, got %v", err)
+	}
+}
+
 func TestPermissionHandler_LookupSubject_Success(t *testing.T) {
 	mockLookup := &mockLookup{
 		lookupSubjectFunc: func(ctx context.Context, req *authorization.LookupSubjectRequest) (*authorization.LookupSubjectResponse, error) {
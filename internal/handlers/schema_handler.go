@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
 	"github.com/asakaida/keruberosu/internal/repositories"
 	"github.com/asakaida/keruberosu/internal/services"
 	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
@@ -17,6 +20,10 @@ type SchemaHandler struct {
 	pb.UnimplementedSchemaServer
 	schemaService services.SchemaServiceInterface
 	schemaRepo    repositories.SchemaRepository
+	auditRepo     repositories.AuditRepository  // Optional: records an audit log for every Write
+	db            *sql.DB                       // Optional: transaction for the write, its audit log and the tenant lock
+	tenantRepo    repositories.TenantRepository // Optional: rejects writes to unknown tenants
+	writeRecorder WriteRecorder                 // Optional: records writes committed in the handler's transactions
 }
 
 // NewSchemaHandler creates a new SchemaHandler
@@ -27,6 +34,29 @@ func NewSchemaHandler(schemaService services.SchemaServiceInterface, schemaRepo
 	}
 }
 
+// SetAuditRepository enables automatic audit logs for Write.
+// Each audit log is written in the same transaction (on db) as the new schema version.
+func (h *SchemaHandler) SetAuditRepository(auditRepo repositories.AuditRepository, db *sql.DB) {
+	h.auditRepo = auditRepo
+	h.db = db
+}
+
+// SetTenantRepository rejects Write for tenants that have not been created
+// through the Tenancy service. With db, the tenant is checked in the write's
+// transaction, so a concurrent Tenancy.Delete cannot leave an orphaned version.
+func (h *SchemaHandler) SetTenantRepository(tenantRepo repositories.TenantRepository, db *sql.DB) {
+	h.tenantRepo = tenantRepo
+	if db != nil {
+		h.db = db
+	}
+}
+
+// SetWriteRecorder records the schema versions committed in the handler's own
+// transactions, for read-your-writes consistency
+func (h *SchemaHandler) SetWriteRecorder(writeRecorder WriteRecorder) {
+	h.writeRecorder = writeRecorder
+}
+
 // Write handles the Write RPC
 func (h *SchemaHandler) Write(ctx context.Context, req *pb.SchemaWriteRequest) (*pb.SchemaWriteResponse, error) {
 	if req.Schema == "" {
@@ -37,20 +67,75 @@ func (h *SchemaHandler) Write(ctx context.Context, req *pb.SchemaWriteRequest) (
 	if tenantID == "" {
 		tenantID = "default"
 	}
+	if err := requireTenant(ctx, h.tenantRepo, tenantID); err != nil {
+		return nil, err
+	}
 
-	version, err := h.schemaService.WriteSchema(ctx, tenantID, req.Schema)
+	var version string
+	var err error
+	if h.auditRepo != nil && h.db != nil {
+		auditLog := newMutationAuditLog(ctx, auditEventSchemaWrite, "write", "schema", nil)
+		version, err = h.writeInTx(ctx, tenantID, req.Schema, auditLog)
+		if err != nil {
+			recordMutationFailure(ctx, h.auditRepo, tenantID, auditLog, err)
+		}
+	} else if h.tenantRepo != nil && h.db != nil {
+		version, err = h.writeInTx(ctx, tenantID, req.Schema, nil)
+	} else {
+		version, err = h.schemaService.WriteSchema(ctx, tenantID, req.Schema)
+	}
 	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
 		if strings.Contains(err.Error(), "parse") || strings.Contains(err.Error(), "validation") {
 			return nil, status.Errorf(codes.InvalidArgument, "failed to write schema: %v", err)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to write schema: %v", err)
 	}
+	logging.SetSchemaVersion(ctx, version)
 
 	return &pb.SchemaWriteResponse{
 		SchemaVersion: version,
 	}, nil
 }
 
+// writeInTx checks the tenant, creates the schema version and records auditLog
+// (if non-nil) in one transaction
+func (h *SchemaHandler) writeInTx(ctx context.Context, tenantID string, schemaDSL string, auditLog *entities.AuditLog) (string, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockTenant(ctx, h.tenantRepo, tx, tenantID); err != nil {
+		return "", err
+	}
+
+	version, err := h.schemaService.WriteSchemaInTx(ctx, tx, tenantID, schemaDSL)
+	if err != nil {
+		return "", err
+	}
+
+	if auditLog != nil {
+		auditLog.ResourceID = version
+		auditLog.Details["schema_version"] = version
+		auditLog.Details["outcome"] = auditOutcomeSuccess
+		if err := h.auditRepo.WriteInTx(ctx, tx, tenantID, auditLog); err != nil {
+			return "", fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if h.writeRecorder != nil {
+		h.writeRecorder.RecordWrite(tenantID)
+	}
+	return version, nil
+}
+
 // Read handles the Read RPC
 func (h *SchemaHandler) Read(ctx context.Context, req *pb.SchemaReadRequest) (*pb.SchemaReadResponse, error) {
 	tenantID := req.TenantId
@@ -46,6 +46,20 @@ func TestSchemaHandler_Write_Success(t *testing.T) {
 	}
 }
 
+func TestSchemaHandler_Write_AuditedRecordsWrite(t *testing.T) {
+	recorder := &mockWriteRecorder{}
+	handler := NewSchemaHandler(&mockSchemaService{}, &mockSchemaRepository{})
+	handler.SetAuditRepository(&mockAuditRepository{}, newFakeTxDB())
+	handler.SetWriteRecorder(recorder)
+
+	if _, err := handler.Write(context.Background(), &pb.SchemaWriteRequest{TenantId: "tenant1", Schema: `entity user {}`}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.tenants) != 1 || recorder.tenants[0] != "tenant1" {
+		t.Errorf("expected the audited schema write to be recorded for tenant1, got %v", recorder.tenants)
+	}
+}
+
 func TestSchemaHandler_Write_EmptySchema(t *testing.T) {
 	mockService := &mockSchemaService{}
 	mockRepo := &mockSchemaRepository{}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TenancyHandler handles Tenancy service gRPC requests
+type TenancyHandler struct {
+	pb.UnimplementedTenancyServer
+	tenantRepo    repositories.TenantRepository
+	auditRepo     repositories.AuditRepository // Optional: records an audit log for every Create and Delete
+	db            *sql.DB                      // Required with auditRepo: transaction for the mutation and its audit log
+	writeRecorder WriteRecorder                // Optional: records mutations committed in the handler's transactions
+}
+
+// NewTenancyHandler creates a new TenancyHandler
+func NewTenancyHandler(tenantRepo repositories.TenantRepository) *TenancyHandler {
+	return &TenancyHandler{
+		tenantRepo: tenantRepo,
+	}
+}
+
+// SetAuditRepository enables automatic audit logs for Create and Delete.
+// Each audit log is written in the same transaction (on db) as the mutation.
+func (h *TenancyHandler) SetAuditRepository(auditRepo repositories.AuditRepository, db *sql.DB) {
+	h.auditRepo = auditRepo
+	h.db = db
+}
+
+// SetWriteRecorder records the tenants created and deleted in the handler's own
+// (audited) transactions, for read-your-writes consistency
+func (h *TenancyHandler) SetWriteRecorder(writeRecorder WriteRecorder) {
+	h.writeRecorder = writeRecorder
+}
+
+// Create handles the Create RPC
+func (h *TenancyHandler) Create(ctx context.Context, req *pb.TenantCreateRequest) (*pb.TenantCreateResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	tenant := &entities.Tenant{ID: req.Id, Name: req.Name}
+	var err error
+	if h.auditRepo != nil && h.db != nil {
+		auditLog := newMutationAuditLog(ctx, auditEventTenantCreate, "create", "tenant", map[string]interface{}{
+			"name": req.Name,
+		})
+		auditLog.ResourceID = tenant.ID
+		err = h.mutateAudited(ctx, tenant.ID, auditLog, func(tx *sql.Tx) error {
+			return h.tenantRepo.CreateInTx(ctx, tx, tenant)
+		})
+		if err != nil {
+			recordMutationFailure(ctx, h.auditRepo, tenant.ID, auditLog, err)
+		}
+	} else {
+		err = h.tenantRepo.Create(ctx, tenant)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrAlreadyExists) {
+			return nil, status.Errorf(codes.AlreadyExists, "tenant %s already exists", tenant.ID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create tenant: %v", err)
+	}
+
+	return &pb.TenantCreateResponse{
+		Tenant: tenantToProto(tenant),
+	}, nil
+}
+
+// Delete handles the Delete RPC. The tenant's schemas, relations, attributes and
+// change history are deleted with it, in one transaction.
+func (h *TenancyHandler) Delete(ctx context.Context, req *pb.TenantDeleteRequest) (*pb.TenantDeleteResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.Id == "default" {
+		return nil, status.Error(codes.InvalidArgument, "the default tenant cannot be deleted")
+	}
+
+	var err error
+	if h.auditRepo != nil && h.db != nil {
+		auditLog := newMutationAuditLog(ctx, auditEventTenantDelete, "delete", "tenant", nil)
+		auditLog.ResourceID = req.Id
+		err = h.mutateAudited(ctx, req.Id, auditLog, func(tx *sql.Tx) error {
+			return h.tenantRepo.DeleteInTx(ctx, tx, req.Id)
+		})
+		if err != nil {
+			recordMutationFailure(ctx, h.auditRepo, req.Id, auditLog, err)
+		}
+	} else {
+		err = h.tenantRepo.Delete(ctx, req.Id)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "tenant %s not found", req.Id)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete tenant: %v", err)
+	}
+
+	return &pb.TenantDeleteResponse{
+		TenantId: req.Id,
+	}, nil
+}
+
+// mutateAudited runs mutate and records auditLog in one transaction
+func (h *TenancyHandler) mutateAudited(ctx context.Context, tenantID string, auditLog *entities.AuditLog, mutate func(tx *sql.Tx) error) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := mutate(tx); err != nil {
+		return err
+	}
+
+	auditLog.Details["outcome"] = auditOutcomeSuccess
+	if err := h.auditRepo.WriteInTx(ctx, tx, tenantID, auditLog); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if h.writeRecorder != nil {
+		h.writeRecorder.RecordWrite(tenantID)
+	}
+	return nil
+}
+
+// List handles the List RPC. Tenants are returned in ID order;
+// continuous_token is empty on the last page.
+func (h *TenancyHandler) List(ctx context.Context, req *pb.TenantListRequest) (*pb.TenantListResponse, error) {
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	// Fetch one extra to determine if there's a next page
+	tenants, err := h.tenantRepo.List(ctx, pageSize+1, req.ContinuousToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tenants: %v", err)
+	}
+
+	var continuousToken string
+	if len(tenants) > pageSize {
+		tenants = tenants[:pageSize]
+		continuousToken = tenants[pageSize-1].ID
+	}
+
+	protoTenants := make([]*pb.Tenant, len(tenants))
+	for i, tenant := range tenants {
+		protoTenants[i] = tenantToProto(tenant)
+	}
+
+	return &pb.TenantListResponse{
+		Tenants:         protoTenants,
+		ContinuousToken: continuousToken,
+	}, nil
+}
+
+func tenantToProto(tenant *entities.Tenant) *pb.Tenant {
+	return &pb.Tenant{
+		Id:        tenant.ID,
+		Name:      tenant.Name,
+		CreatedAt: tenant.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// lockTenant is requireTenant within the write transaction tx. It also keeps the
+// tenant from being deleted until tx ends, so a write racing Tenancy.Delete cannot
+// commit rows of a deleted tenant. It is a no-op when tenantRepo is nil.
+func lockTenant(ctx context.Context, tenantRepo repositories.TenantRepository, tx *sql.Tx, tenantID string) error {
+	if tenantRepo == nil {
+		return nil
+	}
+	if err := tenantRepo.LockInTx(ctx, tx, tenantID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return status.Errorf(codes.NotFound, "tenant %s not found", tenantID)
+		}
+		return status.Errorf(codes.Internal, "failed to check tenant: %v", err)
+	}
+	return nil
+}
+
+// requireTenant rejects writes to tenants that have not been created through the
+// Tenancy service. It is a no-op when tenantRepo is nil.
+func requireTenant(ctx context.Context, tenantRepo repositories.TenantRepository, tenantID string) error {
+	if tenantRepo == nil {
+		return nil
+	}
+	exists, err := tenantRepo.Exists(ctx, tenantID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check tenant: %v", err)
+	}
+	if !exists {
+		return status.Errorf(codes.NotFound, "tenant %s not found", tenantID)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/repositories"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenancyHandler_Create(t *testing.T) {
+	handler := NewTenancyHandler(newMockTenantRepository("default"))
+
+	t.Run("正常系: テナント作成", func(t *testing.T) {
+		resp, err := handler.Create(context.Background(), &pb.TenantCreateRequest{Id: "acme", Name: "Acme"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Tenant.Id != "acme" || resp.Tenant.Name != "Acme" || resp.Tenant.CreatedAt == "" {
+			t.Errorf("unexpected tenant: %v", resp.Tenant)
+		}
+	})
+
+	tests := []struct {
+		name string
+		req  *pb.TenantCreateRequest
+		code codes.Code
+	}{
+		{"IDなし", &pb.TenantCreateRequest{Name: "Acme"}, codes.InvalidArgument},
+		{"名前なし", &pb.TenantCreateRequest{Id: "other"}, codes.InvalidArgument},
+		{"既に存在する", &pb.TenantCreateRequest{Id: "acme", Name: "Acme"}, codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run("異常系: "+tt.name, func(t *testing.T) {
+			_, err := handler.Create(context.Background(), tt.req)
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTenancyHandler_Delete(t *testing.T) {
+	tenantRepo := newMockTenantRepository("default", "acme")
+	handler := NewTenancyHandler(tenantRepo)
+
+	t.Run("正常系: テナント削除", func(t *testing.T) {
+		resp, err := handler.Delete(context.Background(), &pb.TenantDeleteRequest{Id: "acme"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.TenantId != "acme" {
+			t.Errorf("expected tenant ID acme, got %s", resp.TenantId)
+		}
+		if _, ok := tenantRepo.tenants["acme"]; ok {
+			t.Error("expected acme to be deleted")
+		}
+	})
+
+	tests := []struct {
+		name string
+		id   string
+		code codes.Code
+	}{
+		{"IDなし", "", codes.InvalidArgument},
+		{"defaultテナント", "default", codes.InvalidArgument},
+		{"存在しない", "acme", codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run("異常系: "+tt.name, func(t *testing.T) {
+			_, err := handler.Delete(context.Background(), &pb.TenantDeleteRequest{Id: tt.id})
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTenancyHandler_List_WithPagination(t *testing.T) {
+	handler := NewTenancyHandler(newMockTenantRepository("default", "tenant-a", "tenant-b"))
+
+	first, err := handler.List(context.Background(), &pb.TenantListRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Tenants) != 2 || first.Tenants[0].Id != "default" || first.ContinuousToken != "tenant-a" {
+		t.Fatalf("unexpected first page: %v", first)
+	}
+
+	rest, err := handler.List(context.Background(), &pb.TenantListRequest{PageSize: 2, ContinuousToken: first.ContinuousToken})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rest.Tenants) != 1 || rest.Tenants[0].Id != "tenant-b" || rest.ContinuousToken != "" {
+		t.Errorf("unexpected last page: %v", rest)
+	}
+}
+
+func TestRequireTenant(t *testing.T) {
+	tenantRepo := newMockTenantRepository("default")
+
+	if err := requireTenant(context.Background(), nil, "unknown"); err != nil {
+		t.Errorf("expected no check without a tenant repository, got %v", err)
+	}
+	if err := requireTenant(context.Background(), tenantRepo, "default"); err != nil {
+		t.Errorf("expected registered tenant to pass, got %v", err)
+	}
+	if err := requireTenant(context.Background(), tenantRepo, "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	tenantRepo.err = errors.New("connection refused")
+	if err := requireTenant(context.Background(), tenantRepo, "default"); status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+}
+
+func TestWrite_UnknownTenantRejected(t *testing.T) {
+	tenantRepo := newMockTenantRepository("default")
+
+	schemaHandler := NewSchemaHandler(&mockSchemaService{}, &mockSchemaRepository{})
+	schemaHandler.SetTenantRepository(tenantRepo, nil)
+	_, err := schemaHandler.Write(context.Background(), &pb.SchemaWriteRequest{TenantId: "unknown", Schema: "entity user {}"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Schema.Write to return NotFound, got %v", err)
+	}
+
+	dataHandler := NewDataHandler(&mockRelationRepository{}, &mockAttributeRepository{})
+	dataHandler.SetTenantRepository(tenantRepo)
+	_, err = dataHandler.Write(context.Background(), &pb.DataWriteRequest{
+		TenantId: "unknown",
+		Tuples: []*pb.Tuple{{
+			Entity:   &pb.Entity{Type: "document", Id: "1"},
+			Relation: "owner",
+			Subject:  &pb.Subject{Type: "user", Id: "alice"},
+		}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Data.Write to return NotFound, got %v", err)
+	}
+	_, err = dataHandler.Delete(context.Background(), &pb.DataDeleteRequest{
+		TenantId: "unknown",
+		Filter:   &pb.TupleFilter{Entity: &pb.EntityFilter{Type: "document"}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Data.Delete to return NotFound, got %v", err)
+	}
+
+	// The default tenant is registered, so writes without a tenant ID succeed
+	if _, err := schemaHandler.Write(context.Background(), &pb.SchemaWriteRequest{Schema: "entity user {}"}); err != nil {
+		t.Errorf("expected write to the default tenant to succeed, got %v", err)
+	}
+}
+
+// deletingTenantRepository reports its tenants as existing, but deleted by the
+// time a write transaction locks them, as when Tenancy.Delete commits in between
+type deletingTenantRepository struct {
+	*mockTenantRepository
+}
+
+func (m *deletingTenantRepository) LockInTx(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	return repositories.ErrNotFound
+}
+
+func TestWrite_TenantDeletedBeforeTransaction(t *testing.T) {
+	tenantRepo := &deletingTenantRepository{newMockTenantRepository("tenant1")}
+
+	schemaWritten := false
+	schemaHandler := NewSchemaHandler(&mockSchemaService{
+		writeSchemaFunc: func(ctx context.Context, tenantID string, schemaDSL string) (string, error) {
+			schemaWritten = true
+			return "v1", nil
+		},
+	}, &mockSchemaRepository{})
+	schemaHandler.SetTenantRepository(tenantRepo, newFakeTxDB())
+	_, err := schemaHandler.Write(context.Background(), &pb.SchemaWriteRequest{TenantId: "tenant1", Schema: "entity user {}"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Schema.Write to return NotFound, got %v", err)
+	}
+	if schemaWritten {
+		t.Error("expected the schema not to be written to the deleted tenant")
+	}
+
+	dataHandler := NewDataHandlerWithTokenGenerator(&mockRelationRepository{}, &mockAttributeRepository{}, nil, newFakeTxDB())
+	dataHandler.SetTenantRepository(tenantRepo)
+	_, err = dataHandler.Write(context.Background(), &pb.DataWriteRequest{
+		TenantId: "tenant1",
+		Tuples: []*pb.Tuple{{
+			Entity:   &pb.Entity{Type: "document", Id: "1"},
+			Relation: "owner",
+			Subject:  &pb.Subject{Type: "user", Id: "alice"},
+		}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Data.Write to return NotFound, got %v", err)
+	}
+	_, err = dataHandler.Delete(context.Background(), &pb.DataDeleteRequest{
+		TenantId: "tenant1",
+		Filter:   &pb.TupleFilter{Entity: &pb.EntityFilter{Type: "document"}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected Data.Delete to return NotFound, got %v", err)
+	}
+}
+
+func TestTenancyHandler_AuditedMutations_RecordWrite(t *testing.T) {
+	recorder := &mockWriteRecorder{}
+	handler := NewTenancyHandler(newMockTenantRepository())
+	handler.SetAuditRepository(&mockAuditRepository{}, newFakeTxDB())
+	handler.SetWriteRecorder(recorder)
+
+	if _, err := handler.Create(context.Background(), &pb.TenantCreateRequest{Id: "tenant1", Name: "Tenant 1"}); err != nil {
+		t.Fatalf("unexpected create error: %v", err)
+	}
+	if _, err := handler.Delete(context.Background(), &pb.TenantDeleteRequest{Id: "tenant1"}); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if len(recorder.tenants) != 2 || recorder.tenants[0] != "tenant1" || recorder.tenants[1] != "tenant1" {
+		t.Errorf("expected both mutations to be recorded for tenant1, got %v", recorder.tenants)
+	}
+}
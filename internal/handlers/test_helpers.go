@@ -3,10 +3,17 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/repositories"
 	"github.com/asakaida/keruberosu/internal/services/authorization"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc"
 )
 
 // Mock SchemaService
@@ -23,6 +30,10 @@ func (m *mockSchemaService) WriteSchema(ctx context.Context, tenantID string, sc
 	return "v1", nil
 }
 
+func (m *mockSchemaService) WriteSchemaInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	return m.WriteSchema(ctx, tenantID, schemaDSL)
+}
+
 func (m *mockSchemaService) ReadSchema(ctx context.Context, tenantID string) (*entities.Schema, error) {
 	if m.readSchemaFunc != nil {
 		return m.readSchemaFunc(ctx, tenantID)
@@ -47,9 +58,10 @@ func (m *mockSchemaService) GetSchemaEntity(ctx context.Context, tenantID string
 
 // Mock RelationRepository
 type mockRelationRepository struct {
-	batchWriteFunc   func(ctx context.Context, tenantID string, tuples []*entities.RelationTuple) error
-	batchDeleteFunc  func(ctx context.Context, tenantID string, tuples []*entities.RelationTuple) error
-	readByFilterFunc func(ctx context.Context, tenantID string, filter *repositories.RelationFilter, pageSize int, pageToken string) ([]*entities.RelationTuple, string, error)
+	batchWriteFunc     func(ctx context.Context, tenantID string, tuples []*entities.RelationTuple) error
+	batchDeleteFunc    func(ctx context.Context, tenantID string, tuples []*entities.RelationTuple) error
+	deleteByFilterFunc func(ctx context.Context, tenantID string, filter *repositories.RelationFilter) error
+	readByFilterFunc   func(ctx context.Context, tenantID string, filter *repositories.RelationFilter, pageSize int, pageToken string) ([]*entities.RelationTuple, string, error)
 }
 
 func (m *mockRelationRepository) Write(ctx context.Context, tenantID string, tuple *entities.RelationTuple) error {
@@ -87,6 +99,13 @@ func (m *mockRelationRepository) BatchDelete(ctx context.Context, tenantID strin
 }
 
 func (m *mockRelationRepository) DeleteByFilter(ctx context.Context, tenantID string, filter *repositories.RelationFilter) error {
+	if m.deleteByFilterFunc != nil {
+		return m.deleteByFilterFunc(ctx, tenantID, filter)
+	}
+	return nil
+}
+
+func (m *mockRelationRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.RelationFilter) error {
 	return nil
 }
 
@@ -121,7 +140,7 @@ func (m *mockRelationRepository) RebuildClosure(ctx context.Context, tenantID st
 	return nil
 }
 
-func (m *mockRelationRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, cursor string, limit int) ([]string, error) {
+func (m *mockRelationRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	return nil, nil
 }
 
@@ -129,7 +148,7 @@ func (m *mockRelationRepository) GetSortedSubjectIDs(ctx context.Context, tenant
 	return nil, nil
 }
 
-func (m *mockRelationRepository) LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string, entityType string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+func (m *mockRelationRepository) LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
 	return nil, nil
 }
 
@@ -139,8 +158,9 @@ func (m *mockRelationRepository) LookupAccessibleSubjectsComplex(ctx context.Con
 
 // Mock AttributeRepository
 type mockAttributeRepository struct {
-	writeFunc func(ctx context.Context, tenantID string, attr *entities.Attribute) error
-	readFunc  func(ctx context.Context, tenantID string, entityType string, entityID string) (map[string]interface{}, error)
+	writeFunc          func(ctx context.Context, tenantID string, attr *entities.Attribute) error
+	readFunc           func(ctx context.Context, tenantID string, entityType string, entityID string) (map[string]interface{}, error)
+	deleteByFilterFunc func(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error
 }
 
 func (m *mockAttributeRepository) Write(ctx context.Context, tenantID string, attr *entities.Attribute) error {
@@ -161,6 +181,17 @@ func (m *mockAttributeRepository) Delete(ctx context.Context, tenantID string, e
 	return nil
 }
 
+func (m *mockAttributeRepository) DeleteByFilter(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error {
+	if m.deleteByFilterFunc != nil {
+		return m.deleteByFilterFunc(ctx, tenantID, filter)
+	}
+	return nil
+}
+
+func (m *mockAttributeRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.AttributeFilter) error {
+	return nil
+}
+
 func (m *mockAttributeRepository) GetValue(ctx context.Context, tenantID string, entityType string, entityID string, attrName string) (interface{}, error) {
 	return nil, nil
 }
@@ -169,13 +200,14 @@ func (m *mockAttributeRepository) WriteInTx(ctx context.Context, tx *sql.Tx, ten
 	return nil
 }
 
-func (m *mockAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, cursor string, limit int) ([]string, error) {
+func (m *mockAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	return nil, nil
 }
 
 // Mock Checker - implements authorization.CheckerInterface
 type mockChecker struct {
-	checkFunc func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error)
+	checkFunc     func(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error)
+	bulkCheckFunc func(ctx context.Context, req *authorization.BulkCheckRequest) (*authorization.BulkCheckResponse, error)
 }
 
 func (m *mockChecker) Check(ctx context.Context, req *authorization.CheckRequest) (*authorization.CheckResponse, error) {
@@ -189,6 +221,17 @@ func (m *mockChecker) CheckMultiple(ctx context.Context, req *authorization.Chec
 	return nil, nil
 }
 
+func (m *mockChecker) BulkCheck(ctx context.Context, req *authorization.BulkCheckRequest) (*authorization.BulkCheckResponse, error) {
+	if m.bulkCheckFunc != nil {
+		return m.bulkCheckFunc(ctx, req)
+	}
+	results := make([]*authorization.CheckResponse, len(req.Items))
+	for i := range results {
+		results[i] = &authorization.CheckResponse{Allowed: false}
+	}
+	return &authorization.BulkCheckResponse{Results: results}, nil
+}
+
 // Mock Expander - implements authorization.ExpanderInterface
 type mockExpander struct {
 	expandFunc func(ctx context.Context, req *authorization.ExpandRequest) (*authorization.ExpandResponse, error)
@@ -203,8 +246,9 @@ func (m *mockExpander) Expand(ctx context.Context, req *authorization.ExpandRequ
 
 // Mock Lookup - implements authorization.LookupInterface
 type mockLookup struct {
-	lookupEntityFunc  func(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error)
-	lookupSubjectFunc func(ctx context.Context, req *authorization.LookupSubjectRequest) (*authorization.LookupSubjectResponse, error)
+	lookupEntityFunc       func(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error)
+	lookupEntityStreamFunc func(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error)
+	lookupSubjectFunc      func(ctx context.Context, req *authorization.LookupSubjectRequest) (*authorization.LookupSubjectResponse, error)
 }
 
 func (m *mockLookup) LookupEntity(ctx context.Context, req *authorization.LookupEntityRequest) (*authorization.LookupEntityResponse, error) {
@@ -214,6 +258,13 @@ func (m *mockLookup) LookupEntity(ctx context.Context, req *authorization.Lookup
 	return &authorization.LookupEntityResponse{EntityIDs: []string{}}, nil
 }
 
+func (m *mockLookup) LookupEntityStream(ctx context.Context, req *authorization.LookupEntityRequest, send authorization.LookupEntityStreamFunc) (*authorization.LookupEntityStreamResponse, error) {
+	if m.lookupEntityStreamFunc != nil {
+		return m.lookupEntityStreamFunc(ctx, req, send)
+	}
+	return &authorization.LookupEntityStreamResponse{}, nil
+}
+
 func (m *mockLookup) LookupSubject(ctx context.Context, req *authorization.LookupSubjectRequest) (*authorization.LookupSubjectResponse, error) {
 	if m.lookupSubjectFunc != nil {
 		return m.lookupSubjectFunc(ctx, req)
@@ -221,6 +272,62 @@ func (m *mockLookup) LookupSubject(ctx context.Context, req *authorization.Looku
 	return &authorization.LookupSubjectResponse{SubjectIDs: []string{}}, nil
 }
 
+// Mock LookupEntityStream server
+type mockLookupEntityStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     []*pb.PermissionLookupEntityStreamResponse
+	sendFunc func(resp *pb.PermissionLookupEntityStreamResponse) error
+}
+
+func (m *mockLookupEntityStream) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+func (m *mockLookupEntityStream) Send(resp *pb.PermissionLookupEntityStreamResponse) error {
+	if m.sendFunc != nil {
+		if err := m.sendFunc(resp); err != nil {
+			return err
+		}
+	}
+	m.sent = append(m.sent, resp)
+	return nil
+}
+
+// Mock WatchService - implements services.WatchServiceInterface
+type mockWatchService struct {
+	watchFunc func(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error
+}
+
+func (m *mockWatchService) Watch(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error {
+	if m.watchFunc != nil {
+		return m.watchFunc(ctx, tenantID, afterTxID, send)
+	}
+	return nil
+}
+
+// Mock Data Watch server
+type mockDataWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.DataWatchResponse
+}
+
+func (m *mockDataWatchStream) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+func (m *mockDataWatchStream) Send(resp *pb.DataWatchResponse) error {
+	m.sent = append(m.sent, resp)
+	return nil
+}
+
 // Mock SchemaRepository
 type mockSchemaRepository struct {
 	getLatestVersionFunc func(ctx context.Context, tenantID string) (*entities.Schema, error)
@@ -232,6 +339,10 @@ func (m *mockSchemaRepository) Create(ctx context.Context, tenantID string, sche
 	return "v1", nil
 }
 
+func (m *mockSchemaRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	return "v1", nil
+}
+
 func (m *mockSchemaRepository) GetLatestVersion(ctx context.Context, tenantID string) (*entities.Schema, error) {
 	if m.getLatestVersionFunc != nil {
 		return m.getLatestVersionFunc(ctx, tenantID)
@@ -260,3 +371,181 @@ func (m *mockSchemaRepository) GetByTenant(ctx context.Context, tenantID string)
 func (m *mockSchemaRepository) Delete(ctx context.Context, tenantID string) error {
 	return nil
 }
+
+// Mock AuditRepository
+type mockAuditRepository struct {
+	logs      []*entities.AuditLog // Stored logs, oldest first
+	writeFunc func(ctx context.Context, tenantID string, log *entities.AuditLog) error
+}
+
+func (m *mockAuditRepository) Write(ctx context.Context, tenantID string, log *entities.AuditLog) error {
+	if m.writeFunc != nil {
+		return m.writeFunc(ctx, tenantID, log)
+	}
+	log.ID = int64(len(m.logs) + 1)
+	m.logs = append(m.logs, log)
+	return nil
+}
+
+func (m *mockAuditRepository) WriteInTx(ctx context.Context, tx *sql.Tx, tenantID string, log *entities.AuditLog) error {
+	return m.Write(ctx, tenantID, log)
+}
+
+func (m *mockAuditRepository) List(ctx context.Context, tenantID string, filter *repositories.AuditLogFilter, limit int, cursor string) ([]*entities.AuditLog, error) {
+	cursorID, _ := strconv.ParseInt(cursor, 10, 64)
+	var result []*entities.AuditLog
+	for i := len(m.logs) - 1; i >= 0 && len(result) < limit; i-- {
+		log := m.logs[i]
+		if cursor != "" && log.ID >= cursorID {
+			continue
+		}
+		if filter.EventType != "" && log.EventType != filter.EventType {
+			continue
+		}
+		result = append(result, log)
+	}
+	return result, nil
+}
+
+func (m *mockAuditRepository) Count(ctx context.Context, tenantID string, filter *repositories.AuditLogFilter) (int, error) {
+	count := 0
+	for _, log := range m.logs {
+		if filter.EventType == "" || log.EventType == filter.EventType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Mock TenantRepository
+type mockTenantRepository struct {
+	tenants map[string]*entities.Tenant
+	err     error // Returned by every method when set
+}
+
+func newMockTenantRepository(ids ...string) *mockTenantRepository {
+	m := &mockTenantRepository{tenants: map[string]*entities.Tenant{}}
+	for _, id := range ids {
+		m.tenants[id] = &entities.Tenant{ID: id, Name: id, CreatedAt: time.Now()}
+	}
+	return m
+}
+
+func (m *mockTenantRepository) Create(ctx context.Context, tenant *entities.Tenant) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.tenants[tenant.ID]; ok {
+		return repositories.ErrAlreadyExists
+	}
+	tenant.CreatedAt = time.Now()
+	m.tenants[tenant.ID] = tenant
+	return nil
+}
+
+func (m *mockTenantRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenant *entities.Tenant) error {
+	return m.Create(ctx, tenant)
+}
+
+func (m *mockTenantRepository) Exists(ctx context.Context, tenantID string) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	_, ok := m.tenants[tenantID]
+	return ok, nil
+}
+
+func (m *mockTenantRepository) LockInTx(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.tenants[tenantID]; !ok {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+func (m *mockTenantRepository) List(ctx context.Context, limit int, cursor string) ([]*entities.Tenant, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	result := make([]*entities.Tenant, len(ids))
+	for i, id := range ids {
+		result[i] = m.tenants[id]
+	}
+	return result, nil
+}
+
+func (m *mockTenantRepository) Delete(ctx context.Context, tenantID string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.tenants[tenantID]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(m.tenants, tenantID)
+	return nil
+}
+
+func (m *mockTenantRepository) DeleteInTx(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	return m.Delete(ctx, tenantID)
+}
+
+// newFakeTxDB returns a *sql.DB whose transactions commit and roll back without a
+// database, for handlers that run repository mocks in their own transaction
+func newFakeTxDB() *sql.DB {
+	return sql.OpenDB(fakeTxConnector{})
+}
+
+type fakeTxConnector struct{}
+
+func (fakeTxConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeTxConn{}, nil
+}
+
+func (fakeTxConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeTxConn struct{}
+
+func (fakeTxConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fakeTxConn) Close() error {
+	return nil
+}
+
+func (fakeTxConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+// Mock WriteRecorder
+type mockWriteRecorder struct {
+	tenants []string // Tenants passed to RecordWrite, in call order
+}
+
+func (m *mockWriteRecorder) RecordWrite(tenantID string) {
+	m.tenants = append(m.tenants, tenantID)
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+)
+
+// Scopes granted to credentials
+const (
+	ScopeAll            = "*"
+	ScopePermissionRead = "permission:read"
+	ScopeDataRead       = "data:read"
+	ScopeDataWrite      = "data:write"
+	ScopeSchemaRead     = "schema:read"
+	ScopeSchemaWrite    = "schema:write"
+	ScopeAuditRead      = "audit:read"
+	ScopeAuditWrite     = "audit:write"
+	ScopeTenantRead     = "tenant:read"
+	ScopeTenantWrite    = "tenant:write"
+)
+
+// methodScopes is the scope each RPC requires. RPCs not listed here (and not
+// public) are denied, so a new RPC cannot be exposed without choosing its scope.
+var methodScopes = map[string]string{
+	pb.Permission_Check_FullMethodName:              ScopePermissionRead,
+	pb.Permission_Expand_FullMethodName:             ScopePermissionRead,
+	pb.Permission_LookupEntity_FullMethodName:       ScopePermissionRead,
+	pb.Permission_LookupSubject_FullMethodName:      ScopePermissionRead,
+	pb.Permission_LookupEntityStream_FullMethodName: ScopePermissionRead,
+	pb.Permission_SubjectPermission_FullMethodName:  ScopePermissionRead,
+	pb.Permission_BulkCheck_FullMethodName:          ScopePermissionRead,
+	pb.Data_Write_FullMethodName:                    ScopeDataWrite,
+	pb.Data_Delete_FullMethodName:                   ScopeDataWrite,
+	pb.Data_Read_FullMethodName:                     ScopeDataRead,
+	pb.Data_ReadAttributes_FullMethodName:           ScopeDataRead,
+	pb.Data_Watch_FullMethodName:                    ScopeDataRead,
+	pb.Schema_Write_FullMethodName:                  ScopeSchemaWrite,
+	pb.Schema_Read_FullMethodName:                   ScopeSchemaRead,
+	pb.Schema_List_FullMethodName:                   ScopeSchemaRead,
+	pb.AuditService_WriteAuditLog_FullMethodName:    ScopeAuditWrite,
+	pb.AuditService_ReadAuditLogs_FullMethodName:    ScopeAuditRead,
+	pb.Tenancy_Create_FullMethodName:                ScopeTenantWrite,
+	pb.Tenancy_Delete_FullMethodName:                ScopeTenantWrite,
+	pb.Tenancy_List_FullMethodName:                  ScopeTenantRead,
+}
+
+// crossTenantMethods manage tenants themselves, so they are only allowed for
+// principals that are not restricted to particular tenants
+var crossTenantMethods = map[string]bool{
+	pb.Tenancy_Create_FullMethodName: true,
+	pb.Tenancy_Delete_FullMethodName: true,
+	pb.Tenancy_List_FullMethodName:   true,
+}
+
+// publicServices are callable without credentials (health probes and reflection)
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// ErrInvalidCredentials is returned by an Authenticator for unknown or invalid credentials
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator verifies a bearer token and returns the principal it identifies
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Name    string          // Key name or token subject, for logs and audit
+	Scopes  map[string]bool // Granted scopes; ScopeAll grants every scope
+	Tenants map[string]bool // Allowed tenants; empty allows every tenant
+	Tenant  string          // Tenant the credential is bound to; requests without tenant_id use it
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope string) bool {
+	return p.Scopes[ScopeAll] || p.Scopes[scope]
+}
+
+// CanAccessTenant reports whether the principal may access the tenant
+func (p *Principal) CanAccessTenant(tenantID string) bool {
+	return len(p.Tenants) == 0 || p.Tenants[tenantID]
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
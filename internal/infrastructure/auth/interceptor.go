@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/asakaida/keruberosu/internal/infrastructure/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that authenticates
+// the bearer token in the authorization metadata and checks the scope required by
+// the method and the tenant of the request.
+func UnaryServerInterceptor(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		principal, err := authorize(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTenant(principal, req); err != nil {
+			return nil, err
+		}
+		return handler(WithPrincipal(ctx, principal), req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor equivalent to
+// UnaryServerInterceptor. The tenant is checked (and bound) on every received message.
+func StreamServerInterceptor(authenticator Authenticator) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		principal, err := authorize(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{
+			ServerStream: ss,
+			ctx:          WithPrincipal(ss.Context(), principal),
+			principal:    principal,
+		})
+	}
+}
+
+// authenticatedStream carries the principal and checks the tenant of each request
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	principal *Principal
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authenticatedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkTenant(s.principal, m)
+}
+
+// authorize authenticates the caller and checks that it holds the method's scope
+func authorize(ctx context.Context, authenticator Authenticator, fullMethod string) (*Principal, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	principal, err := authenticator.Authenticate(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s is not allowed", fullMethod)
+	}
+	if !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "scope %s is required", scope)
+	}
+	if crossTenantMethods[fullMethod] && len(principal.Tenants) > 0 {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s requires access to every tenant", fullMethod)
+	}
+	return principal, nil
+}
+
+// bearerToken extracts the token from "authorization: Bearer <token>" metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return "", status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// checkTenant denies requests for tenants the principal is not allowed to access.
+// For a principal bound to a tenant, an omitted tenant_id is set to that tenant
+// instead of the default tenant.
+func checkTenant(principal *Principal, req interface{}) error {
+	if principal.Tenant != "" && tenant.Bind(req, principal.Tenant) {
+		return nil
+	}
+	tenantID, ok := tenant.FromRequest(req)
+	if !ok {
+		return nil
+	}
+	if !principal.CanAccessTenant(tenantID) {
+		return status.Errorf(codes.PermissionDenied, "access to tenant %s is not allowed", tenantID)
+	}
+	return nil
+}
+
+// isPublicMethod reports whether the method can be called without credentials
+func isPublicMethod(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
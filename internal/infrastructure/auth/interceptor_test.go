@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// staticAuthenticator authenticates fixed tokens
+type staticAuthenticator map[string]*Principal
+
+func (a staticAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if principal, ok := a[token]; ok {
+		return principal, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+var testAuthenticator = staticAuthenticator{
+	"reader": {Name: "reader", Scopes: toSet([]string{ScopePermissionRead}), Tenants: toSet([]string{"t1"})},
+	"writer": {Name: "writer", Scopes: toSet([]string{ScopeDataWrite, ScopeDataRead})},
+	"bound":  {Name: "bound", Scopes: toSet([]string{ScopeAll}), Tenants: toSet([]string{"t1"}), Tenant: "t1"},
+	"admin":  {Name: "admin", Scopes: toSet([]string{ScopeTenantRead, ScopeTenantWrite})},
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(testAuthenticator)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		req      interface{}
+		wantCode codes.Code
+	}{
+		{"正常系: スコープとテナントが一致", withToken("reader"), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{TenantId: "t1"}, codes.OK},
+		{"正常系: テナント制限なし", withToken("writer"), pb.Data_Write_FullMethodName, &pb.DataWriteRequest{TenantId: "t2"}, codes.OK},
+		{"正常系: テナント管理", withToken("admin"), pb.Tenancy_Create_FullMethodName, &pb.TenantCreateRequest{Id: "t3", Name: "t3"}, codes.OK},
+		{"正常系: ヘルスチェックは認証不要", context.Background(), "/grpc.health.v1.Health/Check", nil, codes.OK},
+		{"異常系: メタデータなし", context.Background(), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{TenantId: "t1"}, codes.Unauthenticated},
+		{"異常系: Bearer以外の形式", metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic reader")), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{TenantId: "t1"}, codes.Unauthenticated},
+		{"異常系: 未登録のキー", withToken("unknown"), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{TenantId: "t1"}, codes.Unauthenticated},
+		{"異常系: スコープ不足", withToken("reader"), pb.Data_Write_FullMethodName, &pb.DataWriteRequest{TenantId: "t1"}, codes.PermissionDenied},
+		{"異常系: 許可されていないテナント", withToken("reader"), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{TenantId: "t2"}, codes.PermissionDenied},
+		{"異常系: 省略時はdefaultテナント", withToken("reader"), pb.Permission_Check_FullMethodName, &pb.PermissionCheckRequest{}, codes.PermissionDenied},
+		{"異常系: テナント制限付きキーでのテナント管理", withToken("bound"), pb.Tenancy_List_FullMethodName, &pb.TenantListRequest{}, codes.PermissionDenied},
+		{"異常系: テナント管理のスコープ不足", withToken("writer"), pb.Tenancy_Delete_FullMethodName, &pb.TenantDeleteRequest{Id: "t2"}, codes.PermissionDenied},
+		{"異常系: 未知のメソッド", withToken("writer"), "/keruberosu.v1.Unknown/Call", nil, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPrincipal *Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotPrincipal, _ = PrincipalFromContext(ctx)
+				return "ok", nil
+			}
+			_, err := interceptor(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("Expected code %v, got %v (%v)", tt.wantCode, code, err)
+			}
+			if tt.wantCode == codes.OK && tt.ctx != context.Background() && gotPrincipal == nil {
+				t.Error("expected principal in handler context")
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_BoundTenant(t *testing.T) {
+	interceptor := UnaryServerInterceptor(testAuthenticator)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_Check_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+
+	t.Run("正常系: tenant_id省略時はトークンのテナントを使用", func(t *testing.T) {
+		req := &pb.PermissionCheckRequest{}
+		if _, err := interceptor(withToken("bound"), req, info, handler); err != nil {
+			t.Fatalf("expected request to succeed, got %v", err)
+		}
+		if req.TenantId != "t1" {
+			t.Errorf("Expected tenant_id t1, got %q", req.TenantId)
+		}
+	})
+
+	t.Run("異常系: 他テナントのtenant_id", func(t *testing.T) {
+		req := &pb.PermissionCheckRequest{TenantId: "t2"}
+		_, err := interceptor(withToken("bound"), req, info, handler)
+		if code := status.Code(err); code != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", code)
+		}
+	})
+}
+
+// fakeServerStream delivers queued requests
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*pb.PermissionLookupEntityRequest
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	m.(*pb.PermissionLookupEntityRequest).TenantId = req.TenantId
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(testAuthenticator)
+	info := &grpc.StreamServerInfo{FullMethod: pb.Permission_LookupEntityStream_FullMethodName}
+
+	run := func(ctx context.Context, tenantID string) error {
+		stream := &fakeServerStream{ctx: ctx, reqs: []*pb.PermissionLookupEntityRequest{{TenantId: tenantID}}}
+		return interceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+			if _, ok := PrincipalFromContext(ss.Context()); !ok {
+				t.Error("expected principal in stream context")
+			}
+			return ss.RecvMsg(&pb.PermissionLookupEntityRequest{})
+		})
+	}
+
+	t.Run("正常系: 許可されたテナント", func(t *testing.T) {
+		if err := run(withToken("reader"), "t1"); err != nil {
+			t.Errorf("expected stream to succeed, got %v", err)
+		}
+	})
+
+	t.Run("異常系: 許可されていないテナント", func(t *testing.T) {
+		if code := status.Code(run(withToken("reader"), "t2")); code != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", code)
+		}
+	})
+
+	t.Run("異常系: バインドされたテナント以外", func(t *testing.T) {
+		if code := status.Code(run(withToken("bound"), "t2")); code != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", code)
+		}
+	})
+
+	t.Run("異常系: スコープ不足", func(t *testing.T) {
+		if code := status.Code(run(withToken("writer"), "t1")); code != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", code)
+		}
+	})
+
+	t.Run("異常系: 認証情報なし", func(t *testing.T) {
+		err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, func(interface{}, grpc.ServerStream) error {
+			t.Error("handler must not be called")
+			return nil
+		})
+		if code := status.Code(err); code != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", code)
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// signatureAlgorithms are the accepted JWT signature algorithms. Symmetric (HS*)
+// algorithms are not accepted, since JWKS keys are public.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+const (
+	// clockSkew is the leeway allowed when checking exp, nbf and iat
+	clockSkew = 30 * time.Second
+	// minKeyRefreshInterval limits JWKS refreshes triggered by unknown key IDs
+	minKeyRefreshInterval = 10 * time.Second
+	// maxJWKSSize is the maximum size of a JWKS document
+	maxJWKSSize = 1 << 20
+)
+
+// JWTConfig represents the configuration of JWTAuthenticator
+type JWTConfig struct {
+	JWKSFile        string        // Local JWKS file (either JWKSFile or JWKSURL)
+	JWKSURL         string        // JWKS endpoint of the OIDC provider
+	Issuer          string        // Required "iss" claim
+	Audience        string        // Required "aud" claim
+	TenantClaim     string        // Claim holding the tenant the token is bound to
+	ScopeClaim      string        // Claim holding scopes; empty grants every scope
+	RefreshInterval time.Duration // How often the JWKS is reloaded
+}
+
+// JWTAuthenticator authenticates callers by JWTs (e.g. OIDC ID or access tokens)
+// signed by a key in a JWKS. Every token is bound to the tenant in its tenant claim.
+type JWTAuthenticator struct {
+	config     JWTConfig
+	httpClient *http.Client
+
+	mu          sync.RWMutex
+	keys        *jose.JSONWebKeySet
+	lastRefresh time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewJWTAuthenticator loads the JWKS and returns an authenticator
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if (config.JWKSFile == "") == (config.JWKSURL == "") {
+		return nil, fmt.Errorf("exactly one of JWKS file and JWKS URL is required")
+	}
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, fmt.Errorf("issuer and audience are required")
+	}
+	if config.TenantClaim == "" {
+		return nil, fmt.Errorf("tenant claim is required")
+	}
+	a := &JWTAuthenticator{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		stopCh:     make(chan struct{}),
+	}
+	if err := a.refreshKeys(context.Background()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Start reloads the JWKS every RefreshInterval until Stop
+func (a *JWTAuthenticator) Start() {
+	if a.config.RefreshInterval <= 0 {
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.config.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.refreshKeys(context.Background()); err != nil {
+					slog.Warn("Failed to refresh JWKS, keeping the previous keys", "error", err)
+				}
+			case <-a.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading the JWKS
+func (a *JWTAuthenticator) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopCh)
+	})
+	a.wg.Wait()
+}
+
+// tokenClaims are the non-registered claims read from a token
+type tokenClaims map[string]interface{}
+
+// Authenticate verifies the token signature, issuer, audience and expiry, and
+// returns a principal bound to the tenant in the tenant claim
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+	key, err := a.verificationKey(ctx, parsed.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var registered jwt.Claims
+	var custom tokenClaims
+	if err := parsed.Claims(key, &registered, &custom); err != nil {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidCredentials)
+	}
+	if registered.Expiry == nil {
+		return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+	expected := jwt.Expected{
+		Issuer:      a.config.Issuer,
+		AnyAudience: jwt.Audience{a.config.Audience},
+		Time:        time.Now(),
+	}
+	if err := registered.ValidateWithLeeway(expected, clockSkew); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	tenantID, _ := custom[a.config.TenantClaim].(string)
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: token has no %s claim", ErrInvalidCredentials, a.config.TenantClaim)
+	}
+
+	scopes := map[string]bool{ScopeAll: true}
+	if a.config.ScopeClaim != "" {
+		scopes = toSet(claimStrings(custom[a.config.ScopeClaim]))
+	}
+
+	return &Principal{
+		Name:    registered.Subject,
+		Scopes:  scopes,
+		Tenants: map[string]bool{tenantID: true},
+		Tenant:  tenantID,
+	}, nil
+}
+
+// verificationKey returns the JWKS key with the key ID. An unknown key ID
+// triggers a JWKS reload, so keys rotated by the provider are picked up.
+func (a *JWTAuthenticator) verificationKey(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	if key := a.lookupKey(keyID); key != nil {
+		return key, nil
+	}
+
+	a.mu.Lock()
+	canRefresh := time.Since(a.lastRefresh) >= minKeyRefreshInterval
+	if canRefresh {
+		a.lastRefresh = time.Now()
+	}
+	a.mu.Unlock()
+	if canRefresh {
+		if err := a.refreshKeys(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to refresh JWKS for an unknown key ID", "kid", keyID, "error", err)
+		} else if key := a.lookupKey(keyID); key != nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidCredentials, keyID)
+}
+
+// lookupKey returns the public signing key with the key ID (any key if keyID is
+// empty and the JWKS has exactly one), or nil
+func (a *JWTAuthenticator) lookupKey(keyID string) *jose.JSONWebKey {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var candidates []jose.JSONWebKey
+	if keyID != "" {
+		candidates = a.keys.Key(keyID)
+	} else if len(a.keys.Keys) == 1 {
+		candidates = a.keys.Keys
+	}
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			continue
+		}
+		return &key
+	}
+	return nil
+}
+
+// refreshKeys loads the JWKS from the file or URL
+func (a *JWTAuthenticator) refreshKeys(ctx context.Context) error {
+	a.mu.Lock()
+	a.lastRefresh = time.Now()
+	a.mu.Unlock()
+
+	data, err := a.readJWKS(ctx)
+	if err != nil {
+		return err
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	if len(keys.Keys) == 0 {
+		return fmt.Errorf("JWKS has no keys")
+	}
+
+	a.mu.Lock()
+	a.keys = &keys
+	a.mu.Unlock()
+	return nil
+}
+
+// readJWKS reads the JWKS document from the file or URL
+func (a *JWTAuthenticator) readJWKS(ctx context.Context) ([]byte, error) {
+	if a.config.JWKSFile != "" {
+		data, err := os.ReadFile(a.config.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.config.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS response: %w", err)
+	}
+	return data, nil
+}
+
+// claimStrings converts a scope claim to a list. Both a space-separated string
+// (OAuth 2.0 "scope") and an array of strings (e.g. "scp") are accepted.
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
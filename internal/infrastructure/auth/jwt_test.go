@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "keruberosu"
+)
+
+// testSigningKey signs test tokens
+type testSigningKey struct {
+	keyID  string
+	key    *ecdsa.PrivateKey
+	signer jose.Signer
+}
+
+func newTestSigningKey(t *testing.T, keyID string) *testSigningKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID),
+	)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return &testSigningKey{keyID: keyID, key: key, signer: signer}
+}
+
+func (k *testSigningKey) publicJWK() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: &k.key.PublicKey, KeyID: k.keyID, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+// sign returns a token with the registered claims and extra claims
+func (k *testSigningKey) sign(t *testing.T, claims jwt.Claims, extra map[string]interface{}) string {
+	t.Helper()
+	token, err := jwt.Signed(k.signer).Claims(claims).Claims(extra).Serialize()
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func validClaims() jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Audience: jwt.Audience{testAudience},
+		Subject:  "alice",
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...*testSigningKey) []byte {
+	t.Helper()
+	set := jose.JSONWebKeySet{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.publicJWK())
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+	return data
+}
+
+func newFileJWTAuthenticator(t *testing.T, config JWTConfig, keys ...*testSigningKey) *JWTAuthenticator {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, keys...), 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	config.JWKSFile = path
+	authenticator, err := NewJWTAuthenticator(config)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return authenticator
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	signingKey := newTestSigningKey(t, "key-1")
+	authenticator := newFileJWTAuthenticator(t, JWTConfig{
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		TenantClaim: "tenant_id",
+		ScopeClaim:  "scope",
+	}, signingKey)
+
+	t.Run("正常系: テナントとスコープをクレームから取得", func(t *testing.T) {
+		token := signingKey.sign(t, validClaims(), map[string]interface{}{
+			"tenant_id": "tenant-a",
+			"scope":     "openid permission:read data:read",
+		})
+		principal, err := authenticator.Authenticate(context.Background(), token)
+		if err != nil {
+			t.Fatalf("expected token to authenticate, got %v", err)
+		}
+		if principal.Name != "alice" || principal.Tenant != "tenant-a" {
+			t.Errorf("Expected alice bound to tenant-a, got %s bound to %s", principal.Name, principal.Tenant)
+		}
+		if !principal.HasScope(ScopePermissionRead) || principal.HasScope(ScopeDataWrite) {
+			t.Errorf("Expected scopes from the scope claim, got %v", principal.Scopes)
+		}
+		if principal.CanAccessTenant("tenant-b") {
+			t.Error("expected token for tenant-a not to access tenant-b")
+		}
+	})
+
+	t.Run("正常系: 配列形式のスコープ", func(t *testing.T) {
+		token := signingKey.sign(t, validClaims(), map[string]interface{}{
+			"tenant_id": "tenant-a",
+			"scope":     []string{"schema:write"},
+		})
+		principal, err := authenticator.Authenticate(context.Background(), token)
+		if err != nil {
+			t.Fatalf("expected token to authenticate, got %v", err)
+		}
+		if !principal.HasScope(ScopeSchemaWrite) {
+			t.Errorf("Expected schema:write, got %v", principal.Scopes)
+		}
+	})
+
+	otherKey := newTestSigningKey(t, "key-1")
+	expired := validClaims()
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := validClaims()
+	noExpiry.Expiry = nil
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://other.example.com"
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.Audience{"other"}
+	tenantClaim := map[string]interface{}{"tenant_id": "tenant-a", "scope": "permission:read"}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"不正な形式", "not-a-jwt"},
+		{"署名が不正", otherKey.sign(t, validClaims(), tenantClaim)},
+		{"期限切れ", signingKey.sign(t, expired, tenantClaim)},
+		{"有効期限なし", signingKey.sign(t, noExpiry, tenantClaim)},
+		{"発行者が不一致", signingKey.sign(t, wrongIssuer, tenantClaim)},
+		{"オーディエンスが不一致", signingKey.sign(t, wrongAudience, tenantClaim)},
+		{"テナントクレームなし", signingKey.sign(t, validClaims(), map[string]interface{}{"scope": "permission:read"})},
+		{"未知の鍵ID", newTestSigningKey(t, "unknown").sign(t, validClaims(), tenantClaim)},
+	}
+	for _, tt := range tests {
+		t.Run("異常系: "+tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(context.Background(), tt.token)
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_NoScopeClaimGrantsAllScopes(t *testing.T) {
+	signingKey := newTestSigningKey(t, "key-1")
+	authenticator := newFileJWTAuthenticator(t, JWTConfig{
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		TenantClaim: "org",
+	}, signingKey)
+
+	token := signingKey.sign(t, validClaims(), map[string]interface{}{"org": "tenant-a"})
+	principal, err := authenticator.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("expected token to authenticate, got %v", err)
+	}
+	if !principal.HasScope(ScopeSchemaWrite) || principal.Tenant != "tenant-a" {
+		t.Errorf("Expected every scope bound to tenant-a, got %v bound to %s", principal.Scopes, principal.Tenant)
+	}
+}
+
+func TestJWTAuthenticator_JWKSURLKeyRotation(t *testing.T) {
+	oldKey := newTestSigningKey(t, "old")
+	newKey := newTestSigningKey(t, "new")
+
+	var current atomic.Value
+	current.Store(jwksJSON(t, oldKey))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	authenticator, err := NewJWTAuthenticator(JWTConfig{
+		JWKSURL:     server.URL,
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		TenantClaim: "tenant_id",
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	claims := map[string]interface{}{"tenant_id": "tenant-a"}
+
+	if _, err := authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims(), claims)); err != nil {
+		t.Fatalf("expected token signed by the old key to authenticate, got %v", err)
+	}
+
+	// The provider rotates to a new key; an unknown key ID triggers a reload
+	current.Store(jwksJSON(t, newKey))
+	authenticator.mu.Lock()
+	authenticator.lastRefresh = time.Time{}
+	authenticator.mu.Unlock()
+
+	if _, err := authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims(), claims)); err != nil {
+		t.Fatalf("expected token signed by the rotated key to authenticate, got %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("Expected 2 JWKS fetches, got %d", fetches.Load())
+	}
+
+	// Reloads triggered by unknown key IDs are rate limited
+	unknown := newTestSigningKey(t, "unknown")
+	if _, err := authenticator.Authenticate(context.Background(), unknown.sign(t, validClaims(), claims)); err == nil {
+		t.Error("expected token signed by an unknown key to fail")
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("Expected no additional JWKS fetch, got %d", fetches.Load())
+	}
+}
+
+func TestNewJWTAuthenticator_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config JWTConfig
+	}{
+		{"JWKSの指定なし", JWTConfig{Issuer: testIssuer, Audience: testAudience, TenantClaim: "tenant_id"}},
+		{"JWKSを両方指定", JWTConfig{JWKSFile: "jwks.json", JWKSURL: "https://example.com/jwks", Issuer: testIssuer, Audience: testAudience, TenantClaim: "tenant_id"}},
+		{"発行者なし", JWTConfig{JWKSFile: "jwks.json", Audience: testAudience, TenantClaim: "tenant_id"}},
+		{"テナントクレームなし", JWTConfig{JWKSFile: "jwks.json", Issuer: testIssuer, Audience: testAudience}},
+		{"JWKSファイルが存在しない", JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json"), Issuer: testIssuer, Audience: testAudience, TenantClaim: "tenant_id"}},
+	}
+	for _, tt := range tests {
+		t.Run("異常系: "+tt.name, func(t *testing.T) {
+			if _, err := NewJWTAuthenticator(tt.config); err == nil {
+				t.Error("expected error for invalid config")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keyFile is the JSON layout of the pre-shared keys file.
+// Keys are stored as SHA-256 hashes, never in plain text.
+type keyFile struct {
+	Keys []keyEntry `json:"keys"`
+}
+
+type keyEntry struct {
+	Name    string   `json:"name"`
+	Hash    string   `json:"hash"`    // Hex-encoded SHA-256 of the key
+	Scopes  []string `json:"scopes"`  // e.g. ["permission:read", "data:write"]
+	Tenants []string `json:"tenants"` // Allowed tenants (empty = all)
+}
+
+// PresharedKeyAuthenticator authenticates callers by pre-shared keys
+type PresharedKeyAuthenticator struct {
+	keys map[string]*Principal // key hash -> principal
+}
+
+// NewPresharedKeyAuthenticator loads keys from a JSON keys file
+func NewPresharedKeyAuthenticator(path string) (*PresharedKeyAuthenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keys file: %w", err)
+	}
+
+	keys := make(map[string]*Principal, len(file.Keys))
+	for i, entry := range file.Keys {
+		hash := strings.ToLower(strings.TrimSpace(entry.Hash))
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("key %d (%s): hash must be a hex-encoded SHA-256", i, entry.Name)
+		}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("key %d: name is required", i)
+		}
+		if len(entry.Scopes) == 0 {
+			return nil, fmt.Errorf("key %s: at least one scope is required", entry.Name)
+		}
+		if _, exists := keys[hash]; exists {
+			return nil, fmt.Errorf("key %s: duplicate hash", entry.Name)
+		}
+		keys[hash] = &Principal{
+			Name:    entry.Name,
+			Scopes:  toSet(entry.Scopes),
+			Tenants: toSet(entry.Tenants),
+		}
+	}
+	return &PresharedKeyAuthenticator{keys: keys}, nil
+}
+
+// Authenticate returns the principal of the key
+func (a *PresharedKeyAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	principal, ok := a.keys[HashKey(token)]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+// HashKey returns the hex-encoded SHA-256 of a key, as stored in the keys file
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateKey returns a new random key (256 bits, URL-safe base64)
+func GenerateKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// toSet converts a list to a set, skipping empty values
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeKeysFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write keys file: %v", err)
+	}
+	return path
+}
+
+func TestPresharedKeyAuthenticator_Authenticate(t *testing.T) {
+	path := writeKeysFile(t, `{"keys": [
+		{"name": "frontend", "hash": "`+HashKey("frontend-key")+`", "scopes": ["permission:read"], "tenants": ["t1"]},
+		{"name": "admin", "hash": "`+HashKey("admin-key")+`", "scopes": ["*"]}
+	]}`)
+	authenticator, err := NewPresharedKeyAuthenticator(path)
+	if err != nil {
+		t.Fatalf("failed to load keys: %v", err)
+	}
+
+	t.Run("正常系: スコープとテナントを持つキー", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(context.Background(), "frontend-key")
+		if err != nil {
+			t.Fatalf("expected key to authenticate, got %v", err)
+		}
+		if principal.Name != "frontend" {
+			t.Errorf("Expected name frontend, got %s", principal.Name)
+		}
+		if !principal.HasScope(ScopePermissionRead) || principal.HasScope(ScopeDataWrite) {
+			t.Errorf("Expected only permission:read, got %v", principal.Scopes)
+		}
+		if !principal.CanAccessTenant("t1") || principal.CanAccessTenant("t2") {
+			t.Errorf("Expected only tenant t1, got %v", principal.Tenants)
+		}
+	})
+
+	t.Run("正常系: ワイルドカードスコープ", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(context.Background(), "admin-key")
+		if err != nil {
+			t.Fatalf("expected key to authenticate, got %v", err)
+		}
+		if !principal.HasScope(ScopeSchemaWrite) || !principal.CanAccessTenant("any") {
+			t.Error("expected admin key to have every scope and tenant")
+		}
+	})
+
+	t.Run("異常系: 未登録のキー", func(t *testing.T) {
+		_, err := authenticator.Authenticate(context.Background(), "unknown-key")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+}
+
+func TestNewPresharedKeyAuthenticator_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"不正なJSON", `{"keys": [`},
+		{"不正なハッシュ", `{"keys": [{"name": "a", "hash": "plain-text-key", "scopes": ["*"]}]}`},
+		{"名前なし", `{"keys": [{"hash": "` + HashKey("a") + `", "scopes": ["*"]}]}`},
+		{"スコープなし", `{"keys": [{"name": "a", "hash": "` + HashKey("a") + `"}]}`},
+		{"重複したハッシュ", `{"keys": [
+			{"name": "a", "hash": "` + HashKey("a") + `", "scopes": ["*"]},
+			{"name": "b", "hash": "` + HashKey("a") + `", "scopes": ["*"]}
+		]}`},
+	}
+	for _, tt := range tests {
+		t.Run("異常系: "+tt.name, func(t *testing.T) {
+			if _, err := NewPresharedKeyAuthenticator(writeKeysFile(t, tt.content)); err == nil {
+				t.Error("expected error for invalid keys file")
+			}
+		})
+	}
+
+	if _, err := NewPresharedKeyAuthenticator(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing keys file")
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	b, _ := GenerateKey()
+	if a == b || len(a) < 40 {
+		t.Errorf("expected distinct random keys, got %q and %q", a, b)
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asakaida/keruberosu/internal/repositories/postgres"
@@ -24,6 +25,7 @@ type SnapshotManager struct {
 	connStr      string
 	stopCh       chan struct{}
 	stopped      bool
+	connected    atomic.Bool // Whether the LISTEN connection is currently established
 }
 
 // NewSnapshotManager creates a new SnapshotManager.
@@ -136,9 +138,15 @@ func (m *SnapshotManager) fetchLatestToken(ctx context.Context) (string, error)
 // startListener starts the PostgreSQL LISTEN/NOTIFY listener.
 func (m *SnapshotManager) startListener() error {
 	reportProblem := func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventConnected, pq.ListenerEventReconnected:
+			m.connected.Store(true)
+		case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+			m.connected.Store(false)
+		}
 		if err != nil {
 			// Log error but don't fail - we have TTL fallback
-			log.Printf("SnapshotManager listener error: %v", err)
+			slog.Warn("Snapshot manager listener error", "error", err)
 		}
 	}
 
@@ -182,7 +190,7 @@ func (m *SnapshotManager) handleNotifications() {
 		case <-pingTimer.C:
 			go func() {
 				if err := m.listener.Ping(); err != nil {
-					log.Printf("SnapshotManager ping error: %v", err)
+					slog.Warn("Snapshot manager ping failed", "error", err)
 				}
 			}()
 			pingTimer.Reset(90 * time.Second)
@@ -190,6 +198,15 @@ func (m *SnapshotManager) handleNotifications() {
 	}
 }
 
+// CheckListener reports an error unless the LISTEN connection is established.
+// While it is down, cached results are only invalidated by the refresh TTL.
+func (m *SnapshotManager) CheckListener(ctx context.Context) error {
+	if !m.connected.Load() {
+		return fmt.Errorf("snapshot listener is not connected")
+	}
+	return nil
+}
+
 // SetToken manually sets the current token.
 // This is primarily used for testing.
 func (m *SnapshotManager) SetToken(token string) {
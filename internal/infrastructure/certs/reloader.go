@@ -0,0 +1,166 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a TLS certificate (and optional client CA pool for mTLS) from
+// files, reloading them when they change. A failed reload keeps the previous
+// certificate, so a half-written file never takes the server down.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string // Empty disables client certificate verification
+	interval     time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time // Last loaded modification time per file
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewReloader loads the certificate, key and optional client CA bundle.
+// interval is how often the files are checked for changes.
+func NewReloader(certFile, keyFile, clientCAFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Start checks the files for changes every interval until Stop
+func (r *Reloader) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.reloadIfChanged()
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the files
+func (r *Reloader) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// TLSConfig returns a server TLS configuration that always uses the latest
+// loaded certificate. With a client CA, clients must present a certificate signed by it.
+func (r *Reloader) TLSConfig(minVersion uint16) *tls.Config {
+	return &tls.Config{
+		MinVersion: minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   minVersion,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				cfg.ClientCAs = r.clientCAs
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// reloadIfChanged reloads the files if any of them was modified since the last load
+func (r *Reloader) reloadIfChanged() {
+	changed := false
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			slog.Warn("Failed to stat TLS file", "file", file, "error", err)
+			return
+		}
+		r.mu.RLock()
+		loaded := r.modTimes[file]
+		r.mu.RUnlock()
+		if !info.ModTime().Equal(loaded) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		slog.Warn("Failed to reload TLS certificate, keeping the previous one", "error", err)
+		return
+	}
+	slog.Info("Reloaded TLS certificate", "cert_file", r.certFile)
+}
+
+// load reads the certificate, key and client CA bundle and swaps them in
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// files returns the watched files
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
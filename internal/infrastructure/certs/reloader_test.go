@@ -0,0 +1,210 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for commonName
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set mtime of %s: %v", path, err)
+	}
+}
+
+// handshake connects a TLS client to a server using serverCfg and returns the
+// serial number of the server certificate
+func handshake(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) (int64, error) {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		server := tls.Server(serverConn, serverCfg)
+		err := server.Handshake()
+		if err == nil {
+			// Complete the client's read of the session so it observes the result
+			_, err = server.Write([]byte{0})
+		}
+		serverErr <- err
+	}()
+
+	client := tls.Client(clientConn, clientCfg)
+	if err := client.Handshake(); err != nil {
+		return 0, err
+	}
+	if _, err := client.Read(make([]byte, 1)); err != nil {
+		return 0, err
+	}
+	if err := <-serverErr; err != nil {
+		return 0, err
+	}
+	return client.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestReloader_MutualTLSAndReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCA(t)
+	base := time.Now().Add(-time.Minute)
+	certPEM, keyPEM := ca.issue(t, "localhost", 10, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, base)
+	writeFile(t, keyFile, keyPEM, base)
+	writeFile(t, caFile, ca.pem, base)
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create reloader: %v", err)
+	}
+	serverCfg := reloader.TLSConfig(tls.VersionTLS12)
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	clientCertPEM, clientKeyPEM := ca.issue(t, "client", 20, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	clientCfg := &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCert}}
+
+	t.Run("正常系: クライアント証明書あり", func(t *testing.T) {
+		serial, err := handshake(t, serverCfg, clientCfg)
+		if err != nil {
+			t.Fatalf("expected handshake to succeed, got %v", err)
+		}
+		if serial != 10 {
+			t.Errorf("expected serial 10, got %d", serial)
+		}
+	})
+
+	t.Run("異常系: クライアント証明書なし", func(t *testing.T) {
+		noCert := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if _, err := handshake(t, serverCfg, noCert); err == nil {
+			t.Error("expected handshake without a client certificate to fail")
+		}
+	})
+
+	t.Run("正常系: 変更された証明書を再読み込み", func(t *testing.T) {
+		certPEM, keyPEM := ca.issue(t, "localhost", 11, x509.ExtKeyUsageServerAuth)
+		writeFile(t, certFile, certPEM, base.Add(time.Second))
+		writeFile(t, keyFile, keyPEM, base.Add(time.Second))
+		reloader.reloadIfChanged()
+
+		serial, err := handshake(t, serverCfg, clientCfg)
+		if err != nil {
+			t.Fatalf("expected handshake to succeed, got %v", err)
+		}
+		if serial != 11 {
+			t.Errorf("expected reloaded serial 11, got %d", serial)
+		}
+	})
+
+	t.Run("異常系: 不正なファイルでは以前の証明書を維持", func(t *testing.T) {
+		writeFile(t, certFile, []byte("not a certificate"), base.Add(2*time.Second))
+		reloader.reloadIfChanged()
+
+		serial, err := handshake(t, serverCfg, clientCfg)
+		if err != nil {
+			t.Fatalf("expected handshake to succeed, got %v", err)
+		}
+		if serial != 11 {
+			t.Errorf("expected previous serial 11, got %d", serial)
+		}
+	})
+}
+
+func TestNewReloader_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewReloader(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"), "", time.Hour); err == nil {
+		t.Error("expected error for missing files")
+	}
+
+	ca := newTestCA(t)
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	certPEM, keyPEM := ca.issue(t, "localhost", 1, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, time.Now())
+	writeFile(t, keyFile, keyPEM, time.Now())
+	writeFile(t, caFile, []byte("no certificates"), time.Now())
+	if _, err := NewReloader(certFile, keyFile, caFile, time.Hour); err == nil {
+		t.Error("expected error for a client CA file without certificates")
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -11,9 +14,15 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Cache    CacheConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Cache       CacheConfig
+	DecisionLog DecisionLogConfig
+	Auth        AuthConfig
+	Tenant      TenantConfig
+	RateLimit   RateLimitConfig
+	Tracing     TracingConfig
+	Log         LogConfig
 }
 
 // ServerConfig represents server configuration
@@ -21,6 +30,41 @@ type ServerConfig struct {
 	Host        string
 	Port        int
 	MetricsPort int // Port for Prometheus metrics HTTP server
+	HTTPPort    int // Port for the HTTP/JSON REST gateway (0 = disabled)
+
+	// BulkCheckConcurrency is the maximum number of BulkCheck items evaluated in parallel
+	BulkCheckConcurrency int
+
+	// WatchPollIntervalMillis is how often Data.Watch streams poll the change log
+	// when no change notification arrives
+	WatchPollIntervalMillis int
+
+	// WatchChangeRetentionHours is how long the change log read by Data.Watch is kept.
+	// Watch resume tokens older than this fail with OutOfRange (0 = keep forever).
+	WatchChangeRetentionHours int
+
+	// AuditMutations records an audit log for every Schema.Write, Data.Write and Data.Delete
+	AuditMutations bool
+
+	// HealthCheckIntervalSeconds is how often dependency health checks run
+	HealthCheckIntervalSeconds int
+
+	// ShutdownDrainSeconds is how long to keep serving after reporting NOT_SERVING
+	// on shutdown, so load balancers can stop routing traffic first
+	ShutdownDrainSeconds int
+
+	// TLS configures transport security for the gRPC server and HTTP gateway
+	TLS TLSConfig
+}
+
+// TLSConfig represents TLS configuration of the servers
+type TLSConfig struct {
+	Enabled               bool
+	CertFile              string // Server certificate (PEM)
+	KeyFile               string // Server private key (PEM)
+	ClientCAFile          string // CA bundle for client certificates; set to require mTLS
+	MinVersion            string // Minimum TLS version: "1.2" or "1.3"
+	ReloadIntervalSeconds int    // How often the files are checked for changes
 }
 
 // CacheConfig represents cache configuration
@@ -31,6 +75,91 @@ type CacheConfig struct {
 	BufferItems    int64
 	Metrics        bool
 	TTLMinutes     int // Time-to-live for cache entries in minutes
+
+	// Backend selects where check results are cached: "memory" (in-process),
+	// "redis" (shared by all replicas) or "tiered" (memory in front of Redis)
+	Backend            string
+	RedisAddr          string // host:port of the Redis server
+	RedisPassword      string
+	RedisDB            int
+	RedisTLS           bool
+	RedisKeyPrefix     string // Prefix of cache keys, so the Redis database can be shared
+	RedisTimeoutMillis int    // Per-command timeout; a slow Redis degrades to cache misses
+	LocalTTLSeconds    int    // Maximum TTL of entries in the memory tier of the tiered backend
+}
+
+// DecisionLogConfig represents the Permission service decision log configuration
+type DecisionLogConfig struct {
+	Enabled           bool
+	Sink              string  // "file" (rotating JSON lines) or "postgres" (decision_logs table)
+	FilePath          string  // Log file path for the file sink
+	FileMaxSizeMB     int     // Rotate the log file once it reaches this size (0 = never)
+	FileMaxBackups    int     // Number of rotated log files kept
+	SampleRate        float64 // Fraction of decisions logged (0.0 to 1.0)
+	TenantSampleRates string  // Per-tenant overrides, e.g. "tenant1=1.0,tenant2=0.1"
+	BufferSize        int     // Decisions queued for the sink before new ones are dropped
+}
+
+// AuthConfig represents API authentication configuration
+type AuthConfig struct {
+	Enabled  bool
+	Mode     string // "preshared" (pre-shared keys, default) or "jwt" (JWT/OIDC tokens)
+	KeysFile string // JSON file of pre-shared keys (SHA-256 hashes, scopes and tenants)
+
+	// JWT mode
+	JWKSFile                   string // Local JWKS file (either JWKSFile or JWKSURL)
+	JWKSURL                    string // JWKS endpoint of the OIDC provider
+	JWKSRefreshIntervalSeconds int    // How often the JWKS is reloaded
+	JWTIssuer                  string // Required "iss" claim
+	JWTAudience                string // Required "aud" claim
+	JWTTenantClaim             string // Claim holding the tenant the token is bound to
+	JWTScopeClaim              string // Claim holding scopes (empty = every scope)
+}
+
+// TenantConfig represents how the tenant of a request is resolved
+type TenantConfig struct {
+	Header string // Header (gRPC metadata key) read after x-tenant-id; empty reads only x-tenant-id
+	Strict bool   // Reject requests without a tenant instead of using the default tenant
+}
+
+// RateLimitConfig represents per-tenant admission control of the gRPC server.
+// Each tenant gets its own token bucket and in-flight limit per RPC class.
+type RateLimitConfig struct {
+	Enabled      bool
+	Check        RateLimit // Permission Check, BulkCheck, Expand and SubjectPermission
+	Lookup       RateLimit // Permission LookupEntity, LookupEntityStream and LookupSubject
+	Write        RateLimit // Schema Write, Data Write and Data Delete
+	TenantLimits string    // Per-tenant overrides, e.g. "tenant1:lookup=5/10/2,tenant2:write=0/0/4"
+
+	// Interval of reloading registered tenants (0 = only at startup).
+	// Unregistered tenants without overrides share one bucket per class.
+	TenantRefreshIntervalSeconds int
+}
+
+// RateLimit is the limit of one RPC class. Zero values mean unlimited.
+type RateLimit struct {
+	RPS         float64 // Sustained requests per second
+	Burst       int     // Token bucket size (0 = same as RPS)
+	MaxInFlight int     // Requests processed concurrently
+}
+
+// TracingConfig represents OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled      bool
+	Exporter     string  // "otlp" (OTLP over gRPC) or "stdout" (JSON spans, for local testing)
+	OTLPEndpoint string  // host:port of the OTLP receiver (empty = OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317)
+	OTLPInsecure bool    // Connect to the OTLP receiver without TLS
+	SampleRatio  float64 // Fraction of new traces sampled (0.0 to 1.0)
+	ServiceName  string  // service.name of the exported spans
+}
+
+// LogConfig represents logging configuration
+type LogConfig struct {
+	Level string // Initial log level: "debug", "info", "warn" or "error"
+
+	// Allow changing the level at runtime with PUT /loglevel on the metrics port,
+	// which is unauthenticated
+	LevelChangeEnabled bool
 }
 
 // DatabaseConfig represents database configuration
@@ -44,6 +173,7 @@ type DatabaseConfig struct {
 	ReplicaHost               string // empty means no replica
 	ReplicaPort               int    // 0 means same as primary Port
 	WriteTrackerWindowSeconds int    // seconds to route reads to primary after a write
+	ReplicaWaitMillis         int    // milliseconds to wait for the replica to replay a snap token before reading from primary
 	ClosureExcludedRelations  string // comma-separated relation names to exclude from closure updates
 }
 
@@ -98,6 +228,51 @@ func InitConfig(env string) error {
 	viper.SetDefault("SERVER_HOST", "0.0.0.0")
 	viper.SetDefault("SERVER_PORT", 50051)
 	viper.SetDefault("METRICS_PORT", 9090)
+	viper.SetDefault("HTTP_PORT", 8080)
+	viper.SetDefault("BULK_CHECK_CONCURRENCY", 10)
+	viper.SetDefault("WATCH_POLL_INTERVAL_MS", 1000)
+	viper.SetDefault("WATCH_CHANGE_RETENTION_HOURS", 24)
+	viper.SetDefault("AUDIT_MUTATIONS", true)
+	viper.SetDefault("HEALTH_CHECK_INTERVAL_SECONDS", 5)
+	viper.SetDefault("SHUTDOWN_DRAIN_SECONDS", 0)
+	viper.SetDefault("TLS_ENABLED", false)
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("TLS_MIN_VERSION", "1.2")
+	viper.SetDefault("TLS_RELOAD_INTERVAL_SECONDS", 30)
+	viper.SetDefault("AUTH_ENABLED", false)
+	viper.SetDefault("AUTH_MODE", "preshared")
+	viper.SetDefault("AUTH_KEYS_FILE", "")
+	viper.SetDefault("AUTH_JWKS_FILE", "")
+	viper.SetDefault("AUTH_JWKS_URL", "")
+	viper.SetDefault("AUTH_JWKS_REFRESH_INTERVAL_SECONDS", 300)
+	viper.SetDefault("AUTH_JWT_ISSUER", "")
+	viper.SetDefault("AUTH_JWT_AUDIENCE", "")
+	viper.SetDefault("AUTH_JWT_TENANT_CLAIM", "tenant_id")
+	viper.SetDefault("AUTH_JWT_SCOPE_CLAIM", "scope")
+	viper.SetDefault("TENANT_HEADER", "")
+	viper.SetDefault("TENANT_STRICT", false)
+	viper.SetDefault("RATE_LIMIT_ENABLED", false)
+	viper.SetDefault("RATE_LIMIT_CHECK_RPS", 1000)
+	viper.SetDefault("RATE_LIMIT_CHECK_BURST", 2000)
+	viper.SetDefault("RATE_LIMIT_CHECK_MAX_INFLIGHT", 200)
+	viper.SetDefault("RATE_LIMIT_LOOKUP_RPS", 50)
+	viper.SetDefault("RATE_LIMIT_LOOKUP_BURST", 100)
+	viper.SetDefault("RATE_LIMIT_LOOKUP_MAX_INFLIGHT", 10)
+	viper.SetDefault("RATE_LIMIT_WRITE_RPS", 200)
+	viper.SetDefault("RATE_LIMIT_WRITE_BURST", 400)
+	viper.SetDefault("RATE_LIMIT_WRITE_MAX_INFLIGHT", 50)
+	viper.SetDefault("RATE_LIMIT_TENANT_LIMITS", "")
+	viper.SetDefault("RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS", 30)
+	viper.SetDefault("TRACING_ENABLED", false)
+	viper.SetDefault("TRACING_EXPORTER", "otlp")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	viper.SetDefault("TRACING_OTLP_INSECURE", true)
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("TRACING_SERVICE_NAME", "keruberosu")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_LEVEL_CHANGE_ENABLED", false)
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", 15432)
 	viper.SetDefault("DB_USER", "keruberosu")
@@ -106,6 +281,7 @@ func InitConfig(env string) error {
 	viper.SetDefault("DB_REPLICA_HOST", "")
 	viper.SetDefault("DB_REPLICA_PORT", 0)
 	viper.SetDefault("WRITE_TRACKER_WINDOW_SECONDS", 1)
+	viper.SetDefault("REPLICA_WAIT_MS", 50)
 	viper.SetDefault("CLOSURE_EXCLUDED_RELATIONS", "")
 
 	// Cache defaults
@@ -115,6 +291,24 @@ func InitConfig(env string) error {
 	viper.SetDefault("CACHE_BUFFER_ITEMS", 64)
 	viper.SetDefault("CACHE_METRICS", true)
 	viper.SetDefault("CACHE_TTL_MINUTES", 5) // 5 minutes TTL
+	viper.SetDefault("CACHE_BACKEND", "memory")
+	viper.SetDefault("CACHE_REDIS_ADDR", "localhost:6379")
+	viper.SetDefault("CACHE_REDIS_PASSWORD", "")
+	viper.SetDefault("CACHE_REDIS_DB", 0)
+	viper.SetDefault("CACHE_REDIS_TLS", false)
+	viper.SetDefault("CACHE_REDIS_KEY_PREFIX", "keruberosu:cache:")
+	viper.SetDefault("CACHE_REDIS_TIMEOUT_MS", 100)
+	viper.SetDefault("CACHE_LOCAL_TTL_SECONDS", 60)
+
+	// Decision log defaults
+	viper.SetDefault("DECISION_LOG_ENABLED", false)
+	viper.SetDefault("DECISION_LOG_SINK", "file")
+	viper.SetDefault("DECISION_LOG_FILE_PATH", "logs/decisions.jsonl")
+	viper.SetDefault("DECISION_LOG_FILE_MAX_SIZE_MB", 100)
+	viper.SetDefault("DECISION_LOG_FILE_MAX_BACKUPS", 5)
+	viper.SetDefault("DECISION_LOG_SAMPLE_RATE", 1.0)
+	viper.SetDefault("DECISION_LOG_TENANT_SAMPLE_RATES", "")
+	viper.SetDefault("DECISION_LOG_BUFFER_SIZE", 10000)
 
 	return nil
 }
@@ -129,9 +323,24 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		Server: ServerConfig{
-			Host:        viper.GetString("SERVER_HOST"),
-			Port:        viper.GetInt("SERVER_PORT"),
-			MetricsPort: viper.GetInt("METRICS_PORT"),
+			Host:                       viper.GetString("SERVER_HOST"),
+			Port:                       viper.GetInt("SERVER_PORT"),
+			MetricsPort:                viper.GetInt("METRICS_PORT"),
+			HTTPPort:                   viper.GetInt("HTTP_PORT"),
+			BulkCheckConcurrency:       viper.GetInt("BULK_CHECK_CONCURRENCY"),
+			WatchPollIntervalMillis:    viper.GetInt("WATCH_POLL_INTERVAL_MS"),
+			WatchChangeRetentionHours:  viper.GetInt("WATCH_CHANGE_RETENTION_HOURS"),
+			AuditMutations:             viper.GetBool("AUDIT_MUTATIONS"),
+			HealthCheckIntervalSeconds: viper.GetInt("HEALTH_CHECK_INTERVAL_SECONDS"),
+			ShutdownDrainSeconds:       viper.GetInt("SHUTDOWN_DRAIN_SECONDS"),
+			TLS: TLSConfig{
+				Enabled:               viper.GetBool("TLS_ENABLED"),
+				CertFile:              viper.GetString("TLS_CERT_FILE"),
+				KeyFile:               viper.GetString("TLS_KEY_FILE"),
+				ClientCAFile:          viper.GetString("TLS_CLIENT_CA_FILE"),
+				MinVersion:            viper.GetString("TLS_MIN_VERSION"),
+				ReloadIntervalSeconds: viper.GetInt("TLS_RELOAD_INTERVAL_SECONDS"),
+			},
 		},
 		Database: DatabaseConfig{
 			Host:                      viper.GetString("DB_HOST"),
@@ -143,6 +352,7 @@ func Load() (*Config, error) {
 			ReplicaHost:               viper.GetString("DB_REPLICA_HOST"),
 			ReplicaPort:               viper.GetInt("DB_REPLICA_PORT"),
 			WriteTrackerWindowSeconds: viper.GetInt("WRITE_TRACKER_WINDOW_SECONDS"),
+			ReplicaWaitMillis:         viper.GetInt("REPLICA_WAIT_MS"),
 			ClosureExcludedRelations:  viper.GetString("CLOSURE_EXCLUDED_RELATIONS"),
 		},
 		Cache: CacheConfig{
@@ -152,12 +362,135 @@ func Load() (*Config, error) {
 			BufferItems:    viper.GetInt64("CACHE_BUFFER_ITEMS"),
 			Metrics:        viper.GetBool("CACHE_METRICS"),
 			TTLMinutes:     viper.GetInt("CACHE_TTL_MINUTES"),
+
+			Backend:            viper.GetString("CACHE_BACKEND"),
+			RedisAddr:          viper.GetString("CACHE_REDIS_ADDR"),
+			RedisPassword:      viper.GetString("CACHE_REDIS_PASSWORD"),
+			RedisDB:            viper.GetInt("CACHE_REDIS_DB"),
+			RedisTLS:           viper.GetBool("CACHE_REDIS_TLS"),
+			RedisKeyPrefix:     viper.GetString("CACHE_REDIS_KEY_PREFIX"),
+			RedisTimeoutMillis: viper.GetInt("CACHE_REDIS_TIMEOUT_MS"),
+			LocalTTLSeconds:    viper.GetInt("CACHE_LOCAL_TTL_SECONDS"),
 		},
+		DecisionLog: DecisionLogConfig{
+			Enabled:           viper.GetBool("DECISION_LOG_ENABLED"),
+			Sink:              viper.GetString("DECISION_LOG_SINK"),
+			FilePath:          viper.GetString("DECISION_LOG_FILE_PATH"),
+			FileMaxSizeMB:     viper.GetInt("DECISION_LOG_FILE_MAX_SIZE_MB"),
+			FileMaxBackups:    viper.GetInt("DECISION_LOG_FILE_MAX_BACKUPS"),
+			SampleRate:        viper.GetFloat64("DECISION_LOG_SAMPLE_RATE"),
+			TenantSampleRates: viper.GetString("DECISION_LOG_TENANT_SAMPLE_RATES"),
+			BufferSize:        viper.GetInt("DECISION_LOG_BUFFER_SIZE"),
+		},
+		Auth: AuthConfig{
+			Enabled:                    viper.GetBool("AUTH_ENABLED"),
+			Mode:                       viper.GetString("AUTH_MODE"),
+			KeysFile:                   viper.GetString("AUTH_KEYS_FILE"),
+			JWKSFile:                   viper.GetString("AUTH_JWKS_FILE"),
+			JWKSURL:                    viper.GetString("AUTH_JWKS_URL"),
+			JWKSRefreshIntervalSeconds: viper.GetInt("AUTH_JWKS_REFRESH_INTERVAL_SECONDS"),
+			JWTIssuer:                  viper.GetString("AUTH_JWT_ISSUER"),
+			JWTAudience:                viper.GetString("AUTH_JWT_AUDIENCE"),
+			JWTTenantClaim:             viper.GetString("AUTH_JWT_TENANT_CLAIM"),
+			JWTScopeClaim:              viper.GetString("AUTH_JWT_SCOPE_CLAIM"),
+		},
+		Tenant: TenantConfig{
+			Header: viper.GetString("TENANT_HEADER"),
+			Strict: viper.GetBool("TENANT_STRICT"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:                      viper.GetBool("RATE_LIMIT_ENABLED"),
+			Check:                        rateLimitFromViper("CHECK"),
+			Lookup:                       rateLimitFromViper("LOOKUP"),
+			Write:                        rateLimitFromViper("WRITE"),
+			TenantLimits:                 viper.GetString("RATE_LIMIT_TENANT_LIMITS"),
+			TenantRefreshIntervalSeconds: viper.GetInt("RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS"),
+		},
+		Tracing: TracingConfig{
+			Enabled:      viper.GetBool("TRACING_ENABLED"),
+			Exporter:     viper.GetString("TRACING_EXPORTER"),
+			OTLPEndpoint: viper.GetString("TRACING_OTLP_ENDPOINT"),
+			OTLPInsecure: viper.GetBool("TRACING_OTLP_INSECURE"),
+			SampleRatio:  viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+			ServiceName:  viper.GetString("TRACING_SERVICE_NAME"),
+		},
+		Log: LogConfig{
+			Level:              viper.GetString("LOG_LEVEL"),
+			LevelChangeEnabled: viper.GetBool("LOG_LEVEL_CHANGE_ENABLED"),
+		},
+	}
+
+	if config.Server.WatchChangeRetentionHours < 0 {
+		return nil, fmt.Errorf("WATCH_CHANGE_RETENTION_HOURS must not be negative, got %d", config.Server.WatchChangeRetentionHours)
+	}
+
+	if config.Cache.Enabled {
+		switch config.Cache.Backend {
+		case "", "memory":
+		case "redis", "tiered":
+			if config.Cache.RedisAddr == "" {
+				return nil, fmt.Errorf("CACHE_REDIS_ADDR is required when CACHE_BACKEND is %s", config.Cache.Backend)
+			}
+		default:
+			return nil, fmt.Errorf("invalid CACHE_BACKEND %q (expected memory, redis or tiered)", config.Cache.Backend)
+		}
+	}
+
+	if config.DecisionLog.Enabled {
+		if _, err := config.DecisionLog.ParseTenantSampleRates(); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Server.TLS.Enabled {
+		if config.Server.TLS.CertFile == "" || config.Server.TLS.KeyFile == "" {
+			return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE are required when TLS_ENABLED is true")
+		}
+		if _, err := config.Server.TLS.ParseMinVersion(); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Auth.Enabled {
+		if err := config.Auth.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.RateLimit.Enabled {
+		if err := config.RateLimit.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Tracing.Enabled {
+		switch config.Tracing.Exporter {
+		case "otlp", "stdout":
+		default:
+			return nil, fmt.Errorf("invalid TRACING_EXPORTER %q (expected otlp or stdout)", config.Tracing.Exporter)
+		}
+		if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+			return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO %v (must be 0.0 to 1.0)", config.Tracing.SampleRatio)
+		}
+	}
+
+	if _, err := config.Log.ParseLevel(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// rateLimitFromViper reads RATE_LIMIT_<class>_RPS, _BURST and _MAX_INFLIGHT
+func rateLimitFromViper(class string) RateLimit {
+	prefix := "RATE_LIMIT_" + class
+	return RateLimit{
+		RPS:         viper.GetFloat64(prefix + "_RPS"),
+		Burst:       viper.GetInt(prefix + "_BURST"),
+		MaxInFlight: viper.GetInt(prefix + "_MAX_INFLIGHT"),
+	}
+}
+
 // ConnectionString returns PostgreSQL connection string for the primary.
 func (c *DatabaseConfig) ConnectionString() string {
 	return fmt.Sprintf(
@@ -215,3 +548,165 @@ func (c *DatabaseConfig) ParseClosureExcludedRelations() map[string]bool {
 	}
 	return result
 }
+
+// ParseTenantSampleRates parses the comma-separated "tenant=rate" sampling overrides.
+// Rates must be between 0.0 and 1.0.
+func (c *DecisionLogConfig) ParseTenantSampleRates() (map[string]float64, error) {
+	result := make(map[string]float64)
+	if c.TenantSampleRates == "" {
+		return result, nil
+	}
+	for _, entry := range strings.Split(c.TenantSampleRates, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, rateStr, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("invalid DECISION_LOG_TENANT_SAMPLE_RATES entry %q: expected tenant=rate", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid DECISION_LOG_TENANT_SAMPLE_RATES rate for tenant %s: %q (must be 0.0 to 1.0)", tenantID, rateStr)
+		}
+		result[tenantID] = rate
+	}
+	return result, nil
+}
+
+// ParseTenantLimits parses the comma-separated "tenant:class=rps/burst/max_inflight"
+// overrides into tenant ID -> class ("check", "lookup" or "write") -> limit.
+// The tenant ID is everything before the last colon of the key.
+func (c *RateLimitConfig) ParseTenantLimits() (map[string]map[string]RateLimit, error) {
+	result := make(map[string]map[string]RateLimit)
+	if c.TenantLimits == "" {
+		return result, nil
+	}
+	for _, entry := range strings.Split(c.TenantLimits, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		sep := strings.LastIndex(key, ":")
+		if !ok || sep <= 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_TENANT_LIMITS entry %q: expected tenant:class=rps/burst/max_inflight", entry)
+		}
+		tenantID := strings.TrimSpace(key[:sep])
+		class := strings.TrimSpace(key[sep+1:])
+		switch class {
+		case "check", "lookup", "write":
+		default:
+			return nil, fmt.Errorf("invalid RATE_LIMIT_TENANT_LIMITS class for tenant %s: %q (expected check, lookup or write)", tenantID, class)
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_TENANT_LIMITS limit for %s:%s: %w", tenantID, class, err)
+		}
+		if result[tenantID] == nil {
+			result[tenantID] = make(map[string]RateLimit)
+		}
+		result[tenantID][class] = limit
+	}
+	return result, nil
+}
+
+// parseRateLimit parses "rps/burst/max_inflight"
+func parseRateLimit(value string) (RateLimit, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 3 {
+		return RateLimit{}, fmt.Errorf("%q: expected rps/burst/max_inflight", value)
+	}
+	rps, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("%q: invalid rps", value)
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("%q: invalid burst", value)
+	}
+	maxInFlight, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("%q: invalid max_inflight", value)
+	}
+	limit := RateLimit{RPS: rps, Burst: burst, MaxInFlight: maxInFlight}
+	if err := limit.validate(); err != nil {
+		return RateLimit{}, fmt.Errorf("%q: %w", value, err)
+	}
+	return limit, nil
+}
+
+// validate checks the default limits and the per-tenant overrides
+func (c *RateLimitConfig) validate() error {
+	if err := c.Check.validate(); err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_CHECK limit: %w", err)
+	}
+	if err := c.Lookup.validate(); err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_LOOKUP limit: %w", err)
+	}
+	if err := c.Write.validate(); err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_WRITE limit: %w", err)
+	}
+	if c.TenantRefreshIntervalSeconds < 0 {
+		return fmt.Errorf("RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS must not be negative, got %d", c.TenantRefreshIntervalSeconds)
+	}
+	_, err := c.ParseTenantLimits()
+	return err
+}
+
+// validate rejects negative limits
+func (l RateLimit) validate() error {
+	if l.RPS < 0 || l.Burst < 0 || l.MaxInFlight < 0 {
+		return fmt.Errorf("rps, burst and max_inflight must not be negative")
+	}
+	return nil
+}
+
+// ParseMinVersion returns the crypto/tls constant of the minimum TLS version.
+// An empty value defaults to TLS 1.2.
+func (c *TLSConfig) ParseMinVersion() (uint16, error) {
+	switch c.MinVersion {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS_MIN_VERSION %q (expected 1.2 or 1.3)", c.MinVersion)
+	}
+}
+
+// ParseLevel returns the slog level of Level (info if empty)
+func (c *LogConfig) ParseLevel() (slog.Level, error) {
+	if c.Level == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return 0, fmt.Errorf("invalid LOG_LEVEL %q (expected debug, info, warn or error)", c.Level)
+	}
+	return level, nil
+}
+
+// validate checks that the settings required by the authentication mode are set
+func (c *AuthConfig) validate() error {
+	switch c.Mode {
+	case "", "preshared":
+		if c.KeysFile == "" {
+			return fmt.Errorf("AUTH_KEYS_FILE is required when AUTH_MODE is preshared")
+		}
+	case "jwt":
+		if (c.JWKSFile == "") == (c.JWKSURL == "") {
+			return fmt.Errorf("exactly one of AUTH_JWKS_FILE and AUTH_JWKS_URL is required when AUTH_MODE is jwt")
+		}
+		if c.JWTIssuer == "" || c.JWTAudience == "" {
+			return fmt.Errorf("AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE are required when AUTH_MODE is jwt")
+		}
+		if c.JWTTenantClaim == "" {
+			return fmt.Errorf("AUTH_JWT_TENANT_CLAIM is required when AUTH_MODE is jwt")
+		}
+	default:
+		return fmt.Errorf("invalid AUTH_MODE %q (expected preshared or jwt)", c.Mode)
+	}
+	return nil
+}
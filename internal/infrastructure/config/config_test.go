@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/tls"
+	"log/slog"
 	"os"
 	"testing"
 
@@ -261,3 +263,312 @@ func TestFindProjectRoot(t *testing.T) {
 		t.Errorf("findProjectRoot() returned %v, but go.mod does not exist at %v", root, goModPath)
 	}
 }
+
+func TestDecisionLogConfig_ParseTenantSampleRates(t *testing.T) {
+	t.Run("valid overrides", func(t *testing.T) {
+		cfg := DecisionLogConfig{TenantSampleRates: " tenant1=1.0, tenant2=0.25 ,"}
+		rates, err := cfg.ParseTenantSampleRates()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rates) != 2 || rates["tenant1"] != 1.0 || rates["tenant2"] != 0.25 {
+			t.Errorf("unexpected rates: %v", rates)
+		}
+	})
+
+	for _, value := range []string{"tenant1", "=0.5", "tenant1=abc", "tenant1=1.5"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			cfg := DecisionLogConfig{TenantSampleRates: value}
+			if _, err := cfg.ParseTenantSampleRates(); err == nil {
+				t.Errorf("expected error for %q", value)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_ParseMinVersion(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    uint16
+		wantErr bool
+	}{
+		{"", tls.VersionTLS12, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"1.3", tls.VersionTLS13, false},
+		{"1.1", 0, true},
+		{"tls13", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg := TLSConfig{MinVersion: tt.value}
+			got, err := cfg.ParseMinVersion()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMinVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMinVersion() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_TLSRequiresCertificate(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("TLS_ENABLED", true)
+	viper.Set("TLS_CERT_FILE", "server.crt")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when TLS_KEY_FILE is missing")
+	}
+
+	viper.Set("TLS_KEY_FILE", "server.key")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Server.TLS.Enabled || cfg.Server.TLS.CertFile != "server.crt" || cfg.Server.TLS.KeyFile != "server.key" {
+		t.Errorf("unexpected TLS config: %+v", cfg.Server.TLS)
+	}
+}
+
+func TestLoad_AuthRequiresKeysFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("AUTH_ENABLED", true)
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when AUTH_KEYS_FILE is missing")
+	}
+
+	viper.Set("AUTH_KEYS_FILE", "keys.json")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Auth.Enabled || cfg.Auth.KeysFile != "keys.json" {
+		t.Errorf("unexpected auth config: %+v", cfg.Auth)
+	}
+}
+
+func TestLoad_AuthJWTMode(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("AUTH_ENABLED", true)
+	viper.Set("AUTH_MODE", "jwt")
+	viper.Set("AUTH_JWT_TENANT_CLAIM", "tenant_id")
+	viper.Set("AUTH_JWKS_URL", "https://issuer.example.com/.well-known/jwks.json")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE are missing")
+	}
+
+	viper.Set("AUTH_JWT_ISSUER", "https://issuer.example.com")
+	viper.Set("AUTH_JWT_AUDIENCE", "keruberosu")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Auth.Mode != "jwt" || cfg.Auth.JWTTenantClaim != "tenant_id" {
+		t.Errorf("unexpected auth config: %+v", cfg.Auth)
+	}
+
+	viper.Set("AUTH_JWKS_FILE", "jwks.json")
+	if _, err := Load(); err == nil {
+		t.Error("expected error when both AUTH_JWKS_FILE and AUTH_JWKS_URL are set")
+	}
+
+	viper.Set("AUTH_MODE", "basic")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for an unknown AUTH_MODE")
+	}
+}
+
+func TestLoad_TenantResolution(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("TENANT_HEADER", "X-Org-Id")
+	viper.Set("TENANT_STRICT", true)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tenant.Header != "X-Org-Id" || !cfg.Tenant.Strict {
+		t.Errorf("unexpected tenant config: %+v", cfg.Tenant)
+	}
+}
+
+func TestRateLimitConfig_ParseTenantLimits(t *testing.T) {
+	t.Run("valid overrides", func(t *testing.T) {
+		cfg := RateLimitConfig{TenantLimits: " noisy:lookup=5/10/2, org:a:write=0/0/4 ,noisy:check=100.5/200/0"}
+		limits, err := cfg.ParseTenantLimits()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := limits["noisy"]["lookup"]; got != (RateLimit{RPS: 5, Burst: 10, MaxInFlight: 2}) {
+			t.Errorf("unexpected noisy lookup limit: %+v", got)
+		}
+		if got := limits["noisy"]["check"]; got != (RateLimit{RPS: 100.5, Burst: 200}) {
+			t.Errorf("unexpected noisy check limit: %+v", got)
+		}
+		if got := limits["org:a"]["write"]; got != (RateLimit{MaxInFlight: 4}) {
+			t.Errorf("unexpected org:a write limit: %+v", got)
+		}
+	})
+
+	for _, value := range []string{"noisy", "noisy=5/10/2", ":lookup=5/10/2", "noisy:read=5/10/2", "noisy:lookup=5/10", "noisy:lookup=a/10/2", "noisy:lookup=-1/10/2"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			cfg := RateLimitConfig{TenantLimits: value}
+			if _, err := cfg.ParseTenantLimits(); err == nil {
+				t.Errorf("expected error for %q", value)
+			}
+		})
+	}
+}
+
+func TestLoad_RateLimit(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("RATE_LIMIT_ENABLED", true)
+	viper.Set("RATE_LIMIT_LOOKUP_RPS", 20)
+	viper.Set("RATE_LIMIT_LOOKUP_BURST", 40)
+	viper.Set("RATE_LIMIT_LOOKUP_MAX_INFLIGHT", 5)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RateLimit.Lookup != (RateLimit{RPS: 20, Burst: 40, MaxInFlight: 5}) {
+		t.Errorf("unexpected lookup limit: %+v", cfg.RateLimit.Lookup)
+	}
+
+	viper.Set("RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS", -1)
+	if _, err := Load(); err == nil {
+		t.Error("expected error for negative RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS")
+	}
+	viper.Set("RATE_LIMIT_TENANT_REFRESH_INTERVAL_SECONDS", 30)
+
+	viper.Set("RATE_LIMIT_TENANT_LIMITS", "noisy:lookup=1/1")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for invalid RATE_LIMIT_TENANT_LIMITS")
+	}
+}
+
+func TestLoad_CacheBackend(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("CACHE_ENABLED", true)
+	viper.Set("CACHE_BACKEND", "tiered")
+	viper.Set("CACHE_REDIS_ADDR", "redis:6379")
+	viper.Set("CACHE_REDIS_DB", 2)
+	viper.Set("CACHE_LOCAL_TTL_SECONDS", 10)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Cache.Backend != "tiered" || cfg.Cache.RedisAddr != "redis:6379" || cfg.Cache.RedisDB != 2 || cfg.Cache.LocalTTLSeconds != 10 {
+		t.Errorf("unexpected cache config: %+v", cfg.Cache)
+	}
+
+	viper.Set("CACHE_REDIS_ADDR", "")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for tiered backend without CACHE_REDIS_ADDR")
+	}
+
+	viper.Set("CACHE_BACKEND", "memcached")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown CACHE_BACKEND")
+	}
+
+	viper.Set("CACHE_ENABLED", false)
+	if _, err := Load(); err != nil {
+		t.Errorf("expected the backend to be ignored with the cache disabled, got %v", err)
+	}
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("TRACING_ENABLED", true)
+	viper.Set("TRACING_EXPORTER", "stdout")
+	viper.Set("TRACING_SAMPLE_RATIO", 0.25)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Tracing.Enabled || cfg.Tracing.Exporter != "stdout" || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("unexpected tracing config: %+v", cfg.Tracing)
+	}
+
+	viper.Set("TRACING_EXPORTER", "jaeger")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown TRACING_EXPORTER")
+	}
+
+	viper.Set("TRACING_EXPORTER", "otlp")
+	viper.Set("TRACING_SAMPLE_RATIO", 1.5)
+	if _, err := Load(); err == nil {
+		t.Error("expected error for TRACING_SAMPLE_RATIO above 1.0")
+	}
+}
+
+func TestLoad_LogLevel(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level, _ := cfg.Log.ParseLevel(); level != slog.LevelInfo {
+		t.Errorf("expected info level by default, got %v", level)
+	}
+	if cfg.Log.LevelChangeEnabled {
+		t.Error("expected runtime level changes to be disabled by default")
+	}
+
+	viper.Set("LOG_LEVEL", "DEBUG")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level, _ := cfg.Log.ParseLevel(); level != slog.LevelDebug {
+		t.Errorf("expected debug level, got %v", level)
+	}
+
+	viper.Set("LOG_LEVEL", "verbose")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown LOG_LEVEL")
+	}
+}
+
+func TestLoad_WatchChangeRetention(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("DB_PASSWORD", "testpassword")
+	viper.Set("WATCH_CHANGE_RETENTION_HOURS", 72)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.WatchChangeRetentionHours != 72 {
+		t.Errorf("expected retention of 72 hours, got %d", cfg.Server.WatchChangeRetentionHours)
+	}
+
+	viper.Set("WATCH_CHANGE_RETENTION_HOURS", -1)
+	if _, err := Load(); err == nil {
+		t.Error("expected error for negative WATCH_CHANGE_RETENTION_HOURS")
+	}
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ChangeNotifier wakes up subscribers whenever relations or attributes change.
+// It listens on the snapshot_changed channel, which is notified for every write
+// (see migration 000005). Notifications carry no data: subscribers re-read the
+// change log themselves, so a missed or coalesced wakeup only adds latency.
+type ChangeNotifier struct {
+	mu          sync.Mutex
+	connStr     string
+	listener    *pq.Listener
+	subscribers map[chan struct{}]struct{}
+	stopCh      chan struct{}
+	stopped     bool
+}
+
+// NewChangeNotifier creates a new ChangeNotifier.
+// connStr is the PostgreSQL connection string for LISTEN/NOTIFY.
+func NewChangeNotifier(connStr string) *ChangeNotifier {
+	return &ChangeNotifier{
+		connStr:     connStr,
+		subscribers: make(map[chan struct{}]struct{}),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start starts listening for change notifications
+func (n *ChangeNotifier) Start() error {
+	reportProblem := func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Warn("Change notifier listener error", "error", err)
+		}
+	}
+
+	n.listener = pq.NewListener(n.connStr, 10*time.Second, time.Minute, reportProblem)
+	if err := n.listener.Listen("snapshot_changed"); err != nil {
+		n.listener.Close()
+		n.listener = nil
+		return fmt.Errorf("failed to listen on snapshot_changed: %w", err)
+	}
+
+	go n.handleNotifications()
+	return nil
+}
+
+// Stop stops listening and closes the listener connection
+func (n *ChangeNotifier) Stop() error {
+	n.mu.Lock()
+	if n.stopped {
+		n.mu.Unlock()
+		return nil
+	}
+	n.stopped = true
+	close(n.stopCh)
+	n.mu.Unlock()
+
+	if n.listener != nil {
+		return n.listener.Close()
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives a value after each change, and a
+// function that cancels the subscription. Wakeups are coalesced: the channel
+// holds at most one pending value.
+func (n *ChangeNotifier) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subscribers, ch)
+		n.mu.Unlock()
+	}
+}
+
+// broadcast wakes up every subscriber without blocking
+func (n *ChangeNotifier) broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handleNotifications forwards NOTIFY events to subscribers.
+// A nil notification means the connection was re-established and events may
+// have been lost, so subscribers are woken up in that case too.
+func (n *ChangeNotifier) handleNotifications() {
+	pingTimer := time.NewTimer(90 * time.Second)
+	defer pingTimer.Stop()
+	for {
+		select {
+		case <-n.stopCh:
+			return
+		case <-n.listener.Notify:
+			n.broadcast()
+		case <-pingTimer.C:
+			go func() {
+				if err := n.listener.Ping(); err != nil {
+					slog.Warn("Change notifier ping failed", "error", err)
+				}
+			}()
+			pingTimer.Reset(90 * time.Second)
+		}
+	}
+}
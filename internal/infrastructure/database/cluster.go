@@ -1,19 +1,35 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/asakaida/keruberosu/internal/infrastructure/config"
 )
 
+// replicaPollInterval is how often ReaderFor re-checks replica replay progress
+// while waiting for a snap token's transaction.
+const replicaPollInterval = 10 * time.Millisecond
+
 // DBCluster manages primary and optional read replica database connections
 // with write tracking for replica consistency.
 type DBCluster struct {
 	primary      *ResilientDB
 	replica      *ResilientDB // nil if no replica configured
 	writeTracker *WriteTracker
+	replicaWait  time.Duration // how long to wait for the replica to catch up to a snap token
+
+	// replicaHorizon is the xmin of the last replica snapshot seen: every
+	// transaction below it has been replayed on the replica.
+	replicaHorizon atomic.Int64
+
+	// replayed reports whether the replica can see txid. Defaults to querying
+	// the replica; replaced in tests.
+	replayed func(ctx context.Context, txid int64) (bool, error)
 }
 
 // NewDBCluster creates a new DBCluster from configuration.
@@ -38,11 +54,14 @@ func NewDBCluster(cfg *config.DatabaseConfig) (*DBCluster, error) {
 
 	writeTracker := NewWriteTracker(cfg.WriteTrackerWindowSeconds)
 
-	return &DBCluster{
+	cluster := &DBCluster{
 		primary:      primary,
 		replica:      replica,
 		writeTracker: writeTracker,
-	}, nil
+		replicaWait:  time.Duration(cfg.ReplicaWaitMillis) * time.Millisecond,
+	}
+	cluster.replayed = cluster.replicaHasReplayed
+	return cluster, nil
 }
 
 // Writer returns the primary database for write operations.
@@ -50,16 +69,104 @@ func (c *DBCluster) Writer() DBTX {
 	return c.primary
 }
 
-// ReaderFor returns the appropriate database for read operations.
-// Returns primary if no replica is configured or if the tenant had a recent write.
-func (c *DBCluster) ReaderFor(tenantID string) DBTX {
+// ReaderFor returns the appropriate database for read operations, following the
+// read consistency carried by ctx (see WithReadConsistency):
+//   - ConsistencyFull: primary
+//   - ConsistencyMinimizeLatency: replica
+//   - ConsistencyAtLeastAsFresh: replica once it has replayed the snap token's
+//     transaction, waiting up to the configured replica wait; primary otherwise.
+//     The choice is made once per WithReadConsistency context and reused by
+//     every later query of the request
+//   - ConsistencyDefault: primary if the tenant had a recent write, replica otherwise
+//
+// Returns primary if no replica is configured.
+func (c *DBCluster) ReaderFor(ctx context.Context, tenantID string) DBTX {
 	if c.replica == nil {
 		return c.primary
 	}
-	if c.writeTracker.HasRecentWrite(tenantID) {
+
+	rc := ReadConsistencyFromContext(ctx)
+	switch rc.Mode {
+	case ConsistencyFull:
 		return c.primary
+	case ConsistencyMinimizeLatency:
+		return c.replica
+	case ConsistencyAtLeastAsFresh:
+		if c.replicaFresh(ctx, rc.MinTxID) {
+			return c.replica
+		}
+		return c.primary
+	default:
+		if c.writeTracker.HasRecentWrite(tenantID) {
+			return c.primary
+		}
+		return c.replica
+	}
+}
+
+// replicaFresh reports whether reads of the request may use the replica. The
+// first call waits for the replica; later calls reuse its answer.
+func (c *DBCluster) replicaFresh(ctx context.Context, txid int64) bool {
+	decision, ok := ctx.Value(replicaDecisionKey{}).(*replicaDecision)
+	if !ok {
+		return c.waitForReplica(ctx, txid)
 	}
-	return c.replica
+	decision.once.Do(func() {
+		decision.useReplica = c.waitForReplica(ctx, txid)
+	})
+	return decision.useReplica
+}
+
+// waitForReplica reports whether the replica has replayed txid, polling until
+// the replica wait elapses. Errors are logged and treated as not replayed so
+// that the read falls back to the primary.
+func (c *DBCluster) waitForReplica(ctx context.Context, txid int64) bool {
+	if txid < c.replicaHorizon.Load() {
+		return true
+	}
+
+	deadline := time.Now().Add(c.replicaWait)
+	for {
+		ok, err := c.replayed(ctx, txid)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to check replica replay progress", "error", err)
+			return false
+		}
+		if ok {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(replicaPollInterval):
+		}
+	}
+}
+
+// replicaHasReplayed reports whether txid is visible in the replica's current
+// snapshot, and advances the replica horizon.
+func (c *DBCluster) replicaHasReplayed(ctx context.Context, txid int64) (bool, error) {
+	var visible bool
+	var xmin int64
+	err := c.replica.QueryRowContext(ctx,
+		"SELECT txid_visible_in_snapshot($1, s), txid_snapshot_xmin(s) FROM txid_current_snapshot() AS s",
+		txid,
+	).Scan(&visible, &xmin)
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		horizon := c.replicaHorizon.Load()
+		if xmin <= horizon || c.replicaHorizon.CompareAndSwap(horizon, xmin) {
+			break
+		}
+	}
+	return visible, nil
 }
 
 // RecordWrite records a write for the given tenant.
@@ -100,17 +207,37 @@ func (c *DBCluster) Close() error {
 
 // HealthCheck verifies all database connections are healthy.
 func (c *DBCluster) HealthCheck() error {
-	if err := c.primary.DB().Ping(); err != nil {
+	ctx := context.Background()
+	if err := c.CheckPrimary(ctx); err != nil {
+		return err
+	}
+	return c.CheckReplica(ctx)
+}
+
+// CheckPrimary verifies the primary is reachable.
+func (c *DBCluster) CheckPrimary(ctx context.Context) error {
+	if err := c.primary.DB().PingContext(ctx); err != nil {
 		return fmt.Errorf("primary health check failed: %w", err)
 	}
-	if c.replica != nil {
-		if err := c.replica.DB().Ping(); err != nil {
-			return fmt.Errorf("replica health check failed: %w", err)
-		}
+	return nil
+}
+
+// CheckReplica verifies the replica is reachable. Always succeeds if no replica is configured.
+func (c *DBCluster) CheckReplica(ctx context.Context) error {
+	if c.replica == nil {
+		return nil
+	}
+	if err := c.replica.DB().PingContext(ctx); err != nil {
+		return fmt.Errorf("replica health check failed: %w", err)
 	}
 	return nil
 }
 
+// HasReplica reports whether a read replica is configured.
+func (c *DBCluster) HasReplica() bool {
+	return c.replica != nil
+}
+
 // newPostgresDB creates a *sql.DB with standard pool settings.
 func newPostgresDB(connStr string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", connStr)
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestReplicaCluster returns a cluster with distinct (unconnected) primary and
+// replica handles, and a replay check backed by replayedUpTo.
+func newTestReplicaCluster(replayedUpTo *int64, calls *int) *DBCluster {
+	c := &DBCluster{
+		primary:      NewResilientDB(nil, DefaultRetryConfig()),
+		replica:      NewResilientDB(nil, DefaultRetryConfig()),
+		writeTracker: NewWriteTracker(60),
+		replicaWait:  30 * time.Millisecond,
+	}
+	c.replayed = func(ctx context.Context, txid int64) (bool, error) {
+		*calls++
+		return txid <= *replayedUpTo, nil
+	}
+	return c
+}
+
+func TestDBCluster_ReaderFor_ConsistencyModes(t *testing.T) {
+	replayedUpTo := int64(100)
+	calls := 0
+	c := newTestReplicaCluster(&replayedUpTo, &calls)
+	c.RecordWrite("written")
+
+	tests := []struct {
+		name     string
+		tenantID string
+		rc       *ReadConsistency
+		want     *ResilientDB
+	}{
+		{"default without recent write", "idle", nil, c.replica},
+		{"default after recent write", "written", nil, c.primary},
+		{"minimize latency ignores recent write", "written", &ReadConsistency{Mode: ConsistencyMinimizeLatency}, c.replica},
+		{"full", "idle", &ReadConsistency{Mode: ConsistencyFull}, c.primary},
+		{"at least as fresh, replayed", "written", &ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 100}, c.replica},
+		{"at least as fresh, lagging", "idle", &ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 101}, c.primary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.rc != nil {
+				ctx = WithReadConsistency(ctx, *tt.rc)
+			}
+			if got := c.ReaderFor(ctx, tt.tenantID); got != tt.want {
+				t.Errorf("ReaderFor() returned the wrong database")
+			}
+		})
+	}
+}
+
+func TestDBCluster_ReaderFor_WaitsForReplica(t *testing.T) {
+	replayedUpTo := int64(100)
+	calls := 0
+	c := newTestReplicaCluster(&replayedUpTo, &calls)
+	c.replicaWait = time.Second
+	c.replayed = func(ctx context.Context, txid int64) (bool, error) {
+		calls++
+		// The replica catches up on the third check
+		return calls >= 3, nil
+	}
+
+	ctx := WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 200})
+	if got := c.ReaderFor(ctx, "t1"); got != c.replica {
+		t.Error("expected replica once it has replayed the transaction")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 replay checks, got %d", calls)
+	}
+}
+
+func TestDBCluster_ReaderFor_DecidesOncePerRequest(t *testing.T) {
+	replayedUpTo := int64(100)
+	calls := 0
+	c := newTestReplicaCluster(&replayedUpTo, &calls)
+	c.replicaWait = 50 * time.Millisecond
+
+	// The replica stays behind the token: the first query waits and falls back
+	ctx := WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 200})
+	if got := c.ReaderFor(ctx, "t1"); got != c.primary {
+		t.Fatal("expected primary while the replica lags")
+	}
+	firstCalls := calls
+
+	// Later queries of the same request go straight to the primary
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if got := c.ReaderFor(ctx, "t1"); got != c.primary {
+			t.Fatal("expected primary for later queries of the request")
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= c.replicaWait {
+		t.Errorf("expected later queries not to wait, took %v", elapsed)
+	}
+	if calls != firstCalls {
+		t.Errorf("expected no further replay checks, got %d more", calls-firstCalls)
+	}
+
+	// A new request decides again
+	replayedUpTo = 200
+	ctx = WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 200})
+	if got := c.ReaderFor(ctx, "t1"); got != c.replica {
+		t.Error("expected replica for a new request once the replica caught up")
+	}
+}
+
+func TestDBCluster_ReaderFor_ReplayCheckError(t *testing.T) {
+	c := &DBCluster{
+		primary:      NewResilientDB(nil, DefaultRetryConfig()),
+		replica:      NewResilientDB(nil, DefaultRetryConfig()),
+		writeTracker: NewWriteTracker(1),
+		replicaWait:  time.Second,
+	}
+	c.replayed = func(ctx context.Context, txid int64) (bool, error) {
+		return false, errors.New("replica unavailable")
+	}
+
+	ctx := WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 1})
+	if got := c.ReaderFor(ctx, "t1"); got != c.primary {
+		t.Error("expected primary when the replay check fails")
+	}
+}
+
+func TestDBCluster_ReaderFor_ReplicaHorizon(t *testing.T) {
+	replayedUpTo := int64(0)
+	calls := 0
+	c := newTestReplicaCluster(&replayedUpTo, &calls)
+	c.replicaHorizon.Store(500)
+
+	ctx := WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyAtLeastAsFresh, MinTxID: 499})
+	if got := c.ReaderFor(ctx, "t1"); got != c.replica {
+		t.Error("expected replica for a transaction below the replica horizon")
+	}
+	if calls != 0 {
+		t.Errorf("expected no replay check below the horizon, got %d", calls)
+	}
+}
+
+func TestDBCluster_ReaderFor_NoReplica(t *testing.T) {
+	c := NewSingleNodeCluster(nil)
+	ctx := WithReadConsistency(context.Background(), ReadConsistency{Mode: ConsistencyMinimizeLatency})
+	if got := c.ReaderFor(ctx, "t1"); got != c.primary {
+		t.Error("expected primary when no replica is configured")
+	}
+}
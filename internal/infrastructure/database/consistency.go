@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+// ConsistencyMode selects how fresh the data read by a request must be.
+type ConsistencyMode int
+
+const (
+	// ConsistencyDefault reads from the replica unless the tenant wrote recently
+	// (see WriteTracker).
+	ConsistencyDefault ConsistencyMode = iota
+
+	// ConsistencyMinimizeLatency always reads from the replica when one is configured.
+	ConsistencyMinimizeLatency
+
+	// ConsistencyAtLeastAsFresh reads from the replica only once it has replayed
+	// the transaction of a snap token, falling back to the primary otherwise.
+	ConsistencyAtLeastAsFresh
+
+	// ConsistencyFull always reads from the primary.
+	ConsistencyFull
+)
+
+// ReadConsistency is the read consistency requirement of a request.
+type ReadConsistency struct {
+	Mode    ConsistencyMode
+	MinTxID int64 // Newest transaction the reader must see (ConsistencyAtLeastAsFresh only)
+}
+
+type readConsistencyKey struct{}
+
+// replicaDecisionKey carries the replicaDecision of a ConsistencyAtLeastAsFresh request
+type replicaDecisionKey struct{}
+
+// replicaDecision is the replica-or-primary choice of one ConsistencyAtLeastAsFresh
+// request. It is made by the first query and reused by the rest, so a lagging
+// replica costs the request one replica wait rather than one per query.
+type replicaDecision struct {
+	once       sync.Once
+	useReplica bool
+}
+
+// WithReadConsistency returns a context carrying the read consistency requirement
+// used by DBCluster.ReaderFor. Queries under the returned context share one
+// ConsistencyAtLeastAsFresh routing decision.
+func WithReadConsistency(ctx context.Context, rc ReadConsistency) context.Context {
+	ctx = context.WithValue(ctx, readConsistencyKey{}, rc)
+	if rc.Mode == ConsistencyAtLeastAsFresh {
+		ctx = context.WithValue(ctx, replicaDecisionKey{}, &replicaDecision{})
+	}
+	return ctx
+}
+
+// ReadConsistencyFromContext returns the read consistency requirement of ctx,
+// or the default mode if none was set.
+func ReadConsistencyFromContext(ctx context.Context) ReadConsistency {
+	rc, _ := ctx.Value(readConsistencyKey{}).(ReadConsistency)
+	return rc
+}
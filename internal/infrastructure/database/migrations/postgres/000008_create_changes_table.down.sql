@@ -0,0 +1,11 @@
+-- Remove change triggers
+DROP TRIGGER IF EXISTS attributes_record_change ON attributes;
+DROP TRIGGER IF EXISTS relations_record_change ON relations;
+
+-- Remove functions
+DROP FUNCTION IF EXISTS record_attribute_change();
+DROP FUNCTION IF EXISTS record_relation_change();
+
+-- Remove index and table
+DROP INDEX IF EXISTS idx_changes_tenant_txid;
+DROP TABLE IF EXISTS changes;
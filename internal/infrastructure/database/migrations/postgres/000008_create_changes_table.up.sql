@@ -0,0 +1,62 @@
+-- Create changes table recording every relation and attribute change
+-- This is the change log streamed by the Data.Watch RPC
+
+CREATE TABLE IF NOT EXISTS changes (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    txid BIGINT NOT NULL DEFAULT txid_current(), -- Writing transaction (matches snap tokens)
+    operation VARCHAR(16) NOT NULL,              -- 'write' or 'delete'
+    entity_type VARCHAR(255) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    -- Relation changes
+    relation VARCHAR(255),
+    subject_type VARCHAR(255),
+    subject_id VARCHAR(255),
+    subject_relation VARCHAR(255),
+    -- Attribute changes
+    attribute VARCHAR(255),
+    value TEXT,                                  -- NULL for deletes
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for Watch: changes of a tenant in transaction order
+CREATE INDEX idx_changes_tenant_txid ON changes(tenant_id, txid, id);
+
+-- Create function to record relation changes
+CREATE OR REPLACE FUNCTION record_relation_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (tenant_id, operation, entity_type, entity_id, relation, subject_type, subject_id, subject_relation)
+        VALUES (OLD.tenant_id, 'delete', OLD.entity_type, OLD.entity_id, OLD.relation, OLD.subject_type, OLD.subject_id, OLD.subject_relation);
+        RETURN OLD;
+    END IF;
+    INSERT INTO changes (tenant_id, operation, entity_type, entity_id, relation, subject_type, subject_id, subject_relation)
+    VALUES (NEW.tenant_id, 'write', NEW.entity_type, NEW.entity_id, NEW.relation, NEW.subject_type, NEW.subject_id, NEW.subject_relation);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create function to record attribute changes
+CREATE OR REPLACE FUNCTION record_attribute_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (tenant_id, operation, entity_type, entity_id, attribute)
+        VALUES (OLD.tenant_id, 'delete', OLD.entity_type, OLD.entity_id, OLD.attribute);
+        RETURN OLD;
+    END IF;
+    INSERT INTO changes (tenant_id, operation, entity_type, entity_id, attribute, value)
+    VALUES (NEW.tenant_id, 'write', NEW.entity_type, NEW.entity_id, NEW.attribute, NEW.value);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Add change triggers to relations and attributes tables
+CREATE TRIGGER relations_record_change
+AFTER INSERT OR UPDATE OR DELETE ON relations
+FOR EACH ROW EXECUTE FUNCTION record_relation_change();
+
+CREATE TRIGGER attributes_record_change
+AFTER INSERT OR UPDATE OR DELETE ON attributes
+FOR EACH ROW EXECUTE FUNCTION record_attribute_change();
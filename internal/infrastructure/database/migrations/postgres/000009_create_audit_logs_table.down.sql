@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created;
+DROP INDEX IF EXISTS idx_audit_logs_tenant_id;
+DROP TABLE IF EXISTS audit_logs;
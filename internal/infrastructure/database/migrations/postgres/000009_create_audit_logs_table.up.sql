@@ -0,0 +1,18 @@
+-- Create audit_logs table backing the AuditService
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor_type VARCHAR(255) NOT NULL DEFAULT '',
+    resource_type VARCHAR(255) NOT NULL DEFAULT '',
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for ReadAuditLogs: newest first with cursor (id) pagination
+CREATE INDEX idx_audit_logs_tenant_id ON audit_logs(tenant_id, id DESC);
+-- Index for time-range queries
+CREATE INDEX idx_audit_logs_tenant_created ON audit_logs(tenant_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_decision_logs_tenant_subject;
+DROP INDEX IF EXISTS idx_decision_logs_tenant_entity;
+DROP TABLE IF EXISTS decision_logs;
@@ -0,0 +1,25 @@
+-- Create decision_logs table: the Postgres sink of the Permission service decision log
+CREATE TABLE IF NOT EXISTS decision_logs (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    method VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(255) NOT NULL DEFAULT '',
+    entity_id VARCHAR(255) NOT NULL DEFAULT '',
+    permission VARCHAR(255) NOT NULL DEFAULT '',
+    subject_type VARCHAR(255) NOT NULL DEFAULT '',
+    subject_id VARCHAR(255) NOT NULL DEFAULT '',
+    subject_relation VARCHAR(255) NOT NULL DEFAULT '',
+    result VARCHAR(16) NOT NULL,          -- 'allowed', 'denied' or 'error'
+    result_ids JSONB NOT NULL DEFAULT '[]', -- IDs returned by lookups
+    schema_version VARCHAR(255) NOT NULL DEFAULT '',
+    snap_token TEXT NOT NULL DEFAULT '',
+    cache_hit BOOLEAN NOT NULL DEFAULT FALSE,
+    latency_us BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for investigations: decisions on an entity within a time range
+CREATE INDEX idx_decision_logs_tenant_entity ON decision_logs(tenant_id, entity_type, entity_id, created_at);
+-- Index for investigations: decisions for a subject within a time range
+CREATE INDEX idx_decision_logs_tenant_subject ON decision_logs(tenant_id, subject_type, subject_id, created_at);
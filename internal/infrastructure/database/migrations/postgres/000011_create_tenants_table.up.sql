@@ -0,0 +1,19 @@
+-- Create tenants table backing the Tenancy service
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Register the default tenant and every tenant that already has data,
+-- since writes to unregistered tenants are rejected from now on
+INSERT INTO tenants (id, name) VALUES ('default', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO tenants (id, name)
+SELECT tenant_id, tenant_id FROM schemas
+UNION
+SELECT tenant_id, tenant_id FROM relations
+UNION
+SELECT tenant_id, tenant_id FROM attributes
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Remove change log retention state and index
+DROP TABLE IF EXISTS change_log_state;
+DROP INDEX IF EXISTS idx_changes_created_at;
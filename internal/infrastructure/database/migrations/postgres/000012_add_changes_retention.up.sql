@@ -0,0 +1,15 @@
+-- Add retention to the changes table
+-- Rows older than WATCH_CHANGE_RETENTION_HOURS are pruned periodically
+
+-- Index for pruning by age
+CREATE INDEX IF NOT EXISTS idx_changes_created_at ON changes(created_at);
+
+-- Single-row table holding the newest pruned transaction ID.
+-- Watch resume tokens below it may have missed pruned changes and are rejected.
+CREATE TABLE IF NOT EXISTS change_log_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    pruned_txid BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO change_log_state (id, pruned_txid) VALUES (TRUE, 0)
+ON CONFLICT (id) DO NOTHING;
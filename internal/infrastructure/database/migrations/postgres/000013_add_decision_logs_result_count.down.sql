@@ -0,0 +1,2 @@
+-- Remove the result count from decision logs
+ALTER TABLE decision_logs DROP COLUMN IF EXISTS result_count;
@@ -0,0 +1,3 @@
+-- Add the number of IDs returned by a lookup to decision logs
+-- result_ids keeps only the first IDs, so the total is recorded separately
+ALTER TABLE decision_logs ADD COLUMN IF NOT EXISTS result_count INTEGER NOT NULL DEFAULT 0;
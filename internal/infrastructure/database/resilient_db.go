@@ -11,8 +11,15 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer creates a span for every statement issued through ResilientDB
+var tracer = otel.Tracer("github.com/asakaida/keruberosu/internal/infrastructure/database")
+
 // RetryConfig configures retry behavior for transient DB errors.
 type RetryConfig struct {
 	MaxRetries int
@@ -49,7 +56,7 @@ func (r *ResilientDB) DB() *sql.DB {
 // ExecContext executes a query with retry on transient errors.
 func (r *ResilientDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	var result sql.Result
-	err := r.retry(ctx, func() error {
+	err := r.retry(ctx, "exec", query, func(ctx context.Context) error {
 		var execErr error
 		result, execErr = r.db.ExecContext(ctx, query, args...)
 		return execErr
@@ -60,7 +67,7 @@ func (r *ResilientDB) ExecContext(ctx context.Context, query string, args ...int
 // QueryContext executes a query returning rows with retry on transient errors.
 func (r *ResilientDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	var rows *sql.Rows
-	err := r.retry(ctx, func() error {
+	err := r.retry(ctx, "query", query, func(ctx context.Context) error {
 		var queryErr error
 		rows, queryErr = r.db.QueryContext(ctx, query, args...)
 		return queryErr
@@ -71,13 +78,18 @@ func (r *ResilientDB) QueryContext(ctx context.Context, query string, args ...in
 // QueryRowContext executes a query returning a single row.
 // This is passed through without retry since the error is deferred until Scan.
 func (r *ResilientDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return r.db.QueryRowContext(ctx, query, args...)
+	var row *sql.Row
+	_ = r.attempt(ctx, "query_row", query, 0, func(ctx context.Context) error {
+		row = r.db.QueryRowContext(ctx, query, args...)
+		return nil
+	})
+	return row
 }
 
 // BeginTx starts a transaction with retry on transient errors.
 func (r *ResilientDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
 	var tx *sql.Tx
-	err := r.retry(ctx, func() error {
+	err := r.retry(ctx, "begin", "", func(ctx context.Context) error {
 		var beginErr error
 		tx, beginErr = r.db.BeginTx(ctx, opts)
 		return beginErr
@@ -87,15 +99,17 @@ func (r *ResilientDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx
 
 // PingContext verifies the database connection.
 func (r *ResilientDB) PingContext(ctx context.Context) error {
-	return r.retry(ctx, func() error {
+	return r.retry(ctx, "ping", "", func(ctx context.Context) error {
 		return r.db.PingContext(ctx)
 	})
 }
 
-func (r *ResilientDB) retry(ctx context.Context, fn func() error) error {
+// retry runs fn until it succeeds, fails with a permanent error or runs out of
+// retries. Each attempt is traced as its own span.
+func (r *ResilientDB) retry(ctx context.Context, operation, query string, fn func(ctx context.Context) error) error {
 	var lastErr error
 	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
-		lastErr = fn()
+		lastErr = r.attempt(ctx, operation, query, attempt, fn)
 		if lastErr == nil {
 			return nil
 		}
@@ -115,6 +129,33 @@ func (r *ResilientDB) retry(ctx context.Context, fn func() error) error {
 	return lastErr
 }
 
+// attempt runs one attempt of an operation in a "db.<operation>" span.
+// Retries are marked with db.retry.attempt (1 for the first retry).
+func (r *ResilientDB) attempt(ctx context.Context, operation, query string, attempt int, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, "db."+operation, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+		)
+		if query != "" {
+			span.SetAttributes(attribute.String("db.statement", query))
+		}
+		if attempt > 0 {
+			span.SetAttributes(attribute.Int("db.retry.attempt", attempt))
+		}
+	}
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		span.SetAttributes(attribute.Bool("db.error.transient", isTransientError(err)))
+	}
+	return err
+}
+
 func (r *ResilientDB) backoffDelay(attempt int) time.Duration {
 	delay := float64(r.config.BaseDelay) * math.Pow(2, float64(attempt))
 	if delay > float64(r.config.MaxDelay) {
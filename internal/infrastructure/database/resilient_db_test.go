@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// flakyConnector opens connections whose Exec fails with a transient error
+// until failures run out
+type flakyConnector struct {
+	failures int
+}
+
+func (c *flakyConnector) Connect(context.Context) (driver.Conn, error) {
+	return &flakyConn{connector: c}, nil
+}
+
+func (c *flakyConnector) Driver() driver.Driver {
+	return nil
+}
+
+type flakyConn struct {
+	connector *flakyConnector
+}
+
+func (c *flakyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.connector.failures > 0 {
+		c.connector.failures--
+		return nil, errors.New("dial tcp: connection refused")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *flakyConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *flakyConn) Close() error {
+	return nil
+}
+
+func (c *flakyConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestResilientDB_TracesRetryAttempts(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	db := NewResilientDB(sql.OpenDB(&flakyConnector{failures: 2}), RetryConfig{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   time.Millisecond,
+	})
+	defer db.DB().Close()
+
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM relations"); err != nil {
+		t.Fatalf("Expected the retried statement to succeed, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 attempt spans, got %d", len(spans))
+	}
+	for i, span := range spans {
+		if span.Name != "db.exec" {
+			t.Errorf("span %d: expected name db.exec, got %s", i, span.Name)
+		}
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		if attrs["db.statement"].AsString() != "DELETE FROM relations" {
+			t.Errorf("span %d: expected db.statement, got %v", i, span.Attributes)
+		}
+		if got := attrs["db.retry.attempt"].AsInt64(); got != int64(i) {
+			t.Errorf("span %d: expected db.retry.attempt %d, got %d", i, i, got)
+		}
+		if wantError := i < 2; (len(span.Events) > 0) != wantError {
+			t.Errorf("span %d: expected recorded error %v, got events %v", i, wantError, span.Events)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// FileSink writes decisions as JSON lines to a file, rotating it once it
+// reaches a maximum size. Rotated files are named path.1 (newest) to path.N.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64 // Rotate once the file reaches this many bytes (0 = never)
+	maxBackups int   // Number of rotated files kept
+	file       *os.File
+	size       int64
+}
+
+// fileRecord is the JSON representation of a decision in a FileSink
+type fileRecord struct {
+	Timestamp       string   `json:"timestamp"`
+	TenantID        string   `json:"tenant_id"`
+	Method          string   `json:"method"`
+	EntityType      string   `json:"entity_type,omitempty"`
+	EntityID        string   `json:"entity_id,omitempty"`
+	Permission      string   `json:"permission,omitempty"`
+	SubjectType     string   `json:"subject_type,omitempty"`
+	SubjectID       string   `json:"subject_id,omitempty"`
+	SubjectRelation string   `json:"subject_relation,omitempty"`
+	Result          string   `json:"result"`
+	ResultIDs       []string `json:"result_ids,omitempty"`
+	ResultCount     int      `json:"result_count,omitempty"`
+	SchemaVersion   string   `json:"schema_version,omitempty"`
+	SnapToken       string   `json:"snap_token,omitempty"`
+	CacheHit        bool     `json:"cache_hit"`
+	LatencyMicros   int64    `json:"latency_us"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// NewFileSink opens (or creates) the log file at path for appending
+func NewFileSink(path string, maxSizeBytes int64, maxBackups int) (*FileSink, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create decision log directory: %w", err)
+		}
+	}
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSizeBytes,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write appends a decision as one JSON line
+func (s *FileSink) Write(ctx context.Context, tenantID string, decision *entities.Decision) error {
+	line, err := json.Marshal(&fileRecord{
+		Timestamp:       decision.CreatedAt.UTC().Format(time.RFC3339Nano),
+		TenantID:        tenantID,
+		Method:          decision.Method,
+		EntityType:      decision.EntityType,
+		EntityID:        decision.EntityID,
+		Permission:      decision.Permission,
+		SubjectType:     decision.SubjectType,
+		SubjectID:       decision.SubjectID,
+		SubjectRelation: decision.SubjectRelation,
+		Result:          string(decision.Result),
+		ResultIDs:       decision.ResultIDs,
+		ResultCount:     decision.ResultCount,
+		SchemaVersion:   decision.SchemaVersion,
+		SnapToken:       decision.SnapToken,
+		CacheHit:        decision.CacheHit,
+		LatencyMicros:   decision.Latency.Microseconds(),
+		Error:           decision.Error,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("decision log file is closed")
+	}
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write decision log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open decision log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat decision log file: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 → path.N, ..., path → path.1 and reopens an empty file.
+// The oldest backup is removed; with no backups the file is simply truncated.
+// The current file is closed only once the new one is open, so if rotation fails
+// the sink keeps its file and retries on the next write.
+func (s *FileSink) rotate() error {
+	if s.maxBackups == 0 {
+		if err := s.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate decision log file: %w", err)
+		}
+		s.size = 0
+		return nil
+	}
+
+	os.Remove(s.backupPath(s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate decision log file: %w", err)
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate decision log file: %w", err)
+	}
+
+	previous := s.file
+	if err := s.open(); err != nil {
+		return err
+	}
+	previous.Close()
+	return nil
+}
+
+// backupPath returns the path of the i-th rotated file
+func (s *FileSink) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
@@ -0,0 +1,146 @@
+package decisionlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+func TestFileSink_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+
+	decision := &entities.Decision{
+		TenantID:    "tenant1",
+		Method:      "Check",
+		EntityType:  "document",
+		EntityID:    "1",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+		Result:      entities.DecisionAllowed,
+		CacheHit:    true,
+		Latency:     1500 * time.Microsecond,
+		CreatedAt:   time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC),
+	}
+	if err := sink.Write(context.Background(), "tenant1", decision); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if record["tenant_id"] != "tenant1" || record["result"] != "allowed" || record["cache_hit"] != true {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if record["latency_us"] != 1500.0 || record["timestamp"] != "2026-01-06T09:00:00Z" {
+		t.Errorf("unexpected latency or timestamp: %v", record)
+	}
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	// Small enough that every write after the first rotates the file
+	sink, err := NewFileSink(path, 10, 2)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		decision := &entities.Decision{TenantID: "t", Method: "Check", EntityID: id, Result: entities.DecisionDenied}
+		if err := sink.Write(context.Background(), "t", decision); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{path: "4", path + ".1": "3", path + ".2": "2"}
+	for file, entityID := range expected {
+		lines := readLines(t, file)
+		if len(lines) != 1 {
+			t.Fatalf("expected 1 line in %s, got %d", file, len(lines))
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+			t.Fatalf("invalid JSON line: %v", err)
+		}
+		if record["entity_id"] != entityID {
+			t.Errorf("expected entity %s in %s, got %v", entityID, file, record["entity_id"])
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestFileSink_RotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	sink, err := NewFileSink(path, 10, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	write := func(id string) error {
+		decision := &entities.Decision{TenantID: "t", Method: "Check", EntityID: id, Result: entities.DecisionDenied}
+		return sink.Write(context.Background(), "t", decision)
+	}
+	if err := write("1"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// A non-empty directory in place of the backup makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := write("2"); err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if err := write("3"); err != nil {
+		t.Fatalf("expected the sink to recover once rotation succeeds, got %v", err)
+	}
+	if lines := readLines(t, path); len(lines) != 1 || !strings.Contains(lines[0], `"entity_id":"3"`) {
+		t.Errorf("expected the new file to hold decision 3, got %v", lines)
+	}
+	if lines := readLines(t, path+".1"); len(lines) != 1 || !strings.Contains(lines[0], `"entity_id":"1"`) {
+		t.Errorf("expected the backup to hold decision 1, got %v", lines)
+	}
+}
@@ -0,0 +1,128 @@
+package decisionlog
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// writeTimeout bounds a single sink write so a stalled sink cannot block shutdown
+const writeTimeout = 5 * time.Second
+
+// Sink stores decision logs.
+// repositories.DecisionRepository (the Postgres sink) satisfies this interface.
+type Sink interface {
+	Write(ctx context.Context, tenantID string, decision *entities.Decision) error
+}
+
+// Config configures a Logger
+type Config struct {
+	SampleRate        float64            // Fraction of decisions logged (0.0 to 1.0) for tenants without an override
+	TenantSampleRates map[string]float64 // Per-tenant sampling rate overrides
+	BufferSize        int                // Decisions queued for the sink before new ones are dropped
+}
+
+// Logger samples decisions and writes them to a sink in the background,
+// so logging never adds sink latency to permission checks.
+// Callers ask Sampled once per call before collecting anything for the
+// decision, and pass only sampled decisions to Log.
+// When the queue is full, decisions are dropped rather than blocking the caller.
+type Logger struct {
+	sink        Sink
+	sampleRate  float64
+	tenantRates map[string]float64
+	queue       chan *entities.Decision
+	dropped     atomic.Uint64
+	wg          sync.WaitGroup
+	closeOnce   sync.Once
+	mu          sync.RWMutex // Guards closed against Log racing with Close
+	closed      bool
+}
+
+// NewLogger creates a Logger and starts its background writer
+func NewLogger(sink Sink, cfg *Config) *Logger {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	l := &Logger{
+		sink:        sink,
+		sampleRate:  cfg.SampleRate,
+		tenantRates: cfg.TenantSampleRates,
+		queue:       make(chan *entities.Decision, bufferSize),
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+// Log queues a decision chosen by Sampled for the sink
+func (l *Logger) Log(decision *entities.Decision) {
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.queue <- decision:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of sampled decisions dropped because the queue was full
+func (l *Logger) Dropped() uint64 {
+	return l.dropped.Load()
+}
+
+// Close stops accepting decisions and waits until the queued ones are written
+func (l *Logger) Close() error {
+	l.closeOnce.Do(func() {
+		l.mu.Lock()
+		l.closed = true
+		close(l.queue)
+		l.mu.Unlock()
+	})
+	l.wg.Wait()
+	if dropped := l.Dropped(); dropped > 0 {
+		slog.Warn("Decision log dropped decisions because the queue was full", "dropped", dropped)
+	}
+	return nil
+}
+
+// Sampled reports whether a decision of the tenant should be logged.
+// Each call draws independently, so it must be asked once per decision.
+func (l *Logger) Sampled(tenantID string) bool {
+	rate, ok := l.tenantRates[tenantID]
+	if !ok {
+		rate = l.sampleRate
+	}
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return rand.Float64() < rate
+}
+
+// run writes queued decisions to the sink until the queue is closed
+func (l *Logger) run() {
+	defer l.wg.Done()
+	for decision := range l.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		if err := l.sink.Write(ctx, decision.TenantID, decision); err != nil {
+			slog.Warn("Failed to write decision log", "tenant_id", decision.TenantID, "error", err)
+		}
+		cancel()
+	}
+}
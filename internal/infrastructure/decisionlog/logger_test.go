@@ -0,0 +1,66 @@
+package decisionlog
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// memorySink stores written decisions in memory
+type memorySink struct {
+	mu        sync.Mutex
+	decisions []*entities.Decision
+}
+
+func (s *memorySink) Write(ctx context.Context, tenantID string, decision *entities.Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decisions = append(s.decisions, decision)
+	return nil
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	sink := &memorySink{}
+	logger := NewLogger(sink, &Config{
+		SampleRate:        0,
+		TenantSampleRates: map[string]float64{"audited": 1},
+	})
+
+	for i := 0; i < 10; i++ {
+		for _, tenantID := range []string{"default", "audited"} {
+			if logger.Sampled(tenantID) {
+				logger.Log(&entities.Decision{TenantID: tenantID, Method: "Check"})
+			}
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(sink.decisions) != 10 {
+		t.Fatalf("expected 10 decisions, got %d", len(sink.decisions))
+	}
+	for _, d := range sink.decisions {
+		if d.TenantID != "audited" {
+			t.Errorf("expected only the audited tenant to be sampled, got %s", d.TenantID)
+		}
+		if d.CreatedAt.IsZero() {
+			t.Error("expected CreatedAt to be set")
+		}
+	}
+}
+
+func TestLogger_LogAfterClose(t *testing.T) {
+	sink := &memorySink{}
+	logger := NewLogger(sink, &Config{SampleRate: 1})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	logger.Log(&entities.Decision{TenantID: "default", Method: "Check"})
+	if len(sink.decisions) != 0 {
+		t.Errorf("expected no decisions after Close, got %d", len(sink.decisions))
+	}
+}
@@ -0,0 +1,219 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// CheckFunc reports an error when a dependency is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+// check is a registered dependency check
+type check struct {
+	name     string
+	fn       CheckFunc
+	services []string // gRPC services that depend on the check (empty = all)
+}
+
+// Monitor runs dependency checks periodically and publishes the result as the
+// serving status of each gRPC service in a grpc.health.v1.Health server.
+// The overall status (service "") is SERVING only when every check passes.
+type Monitor struct {
+	server   *health.Server
+	services []string
+	interval time.Duration
+
+	mu           sync.RWMutex
+	checks       []check
+	results      map[string]error // Last result per check name
+	shuttingDown bool
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewMonitor creates a Monitor for the given fully-qualified gRPC service names
+// (e.g., "keruberosu.v1.Permission"). All services are NOT_SERVING until the first run.
+func NewMonitor(services []string, interval time.Duration) *Monitor {
+	server := health.NewServer()
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, service := range services {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return &Monitor{
+		server:   server,
+		services: services,
+		interval: interval,
+		results:  make(map[string]error),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// AddCheck registers a dependency check. services lists the gRPC services that
+// depend on it; with none, every service depends on it.
+func (m *Monitor) AddCheck(name string, fn CheckFunc, services ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, check{name: name, fn: fn, services: services})
+}
+
+// Server returns the grpc.health.v1.Health server to register on the gRPC server
+func (m *Monitor) Server() healthpb.HealthServer {
+	return m.server
+}
+
+// Start runs the checks once, then every interval until Shutdown
+func (m *Monitor) Start() {
+	m.RunChecks(context.Background())
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.RunChecks(context.Background())
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// RunChecks runs every check and updates the serving statuses
+func (m *Monitor) RunChecks(ctx context.Context) {
+	m.mu.RLock()
+	checks := append([]check(nil), m.checks...)
+	m.mu.RUnlock()
+
+	results := make(map[string]error, len(checks))
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, m.interval)
+		err := c.fn(checkCtx)
+		cancel()
+		results[c.name] = err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shuttingDown {
+		return
+	}
+	// Log transitions only, not every failing run
+	for _, c := range checks {
+		err := results[c.name]
+		prevErr, seen := m.results[c.name]
+		if err != nil && (!seen || prevErr == nil) {
+			slog.Warn("Health check failed", "check", c.name, "error", err)
+		} else if err == nil && seen && prevErr != nil {
+			slog.Info("Health check recovered", "check", c.name)
+		}
+	}
+	m.results = results
+
+	m.server.SetServingStatus("", servingStatus(checks, results, ""))
+	for _, service := range m.services {
+		m.server.SetServingStatus(service, servingStatus(checks, results, service))
+	}
+}
+
+// Shutdown stops the checks and sets every service to NOT_SERVING, so load
+// balancers stop sending traffic before the server drains its connections
+func (m *Monitor) Shutdown() {
+	m.mu.Lock()
+	m.shuttingDown = true
+	m.mu.Unlock()
+	m.server.Shutdown()
+
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	m.wg.Wait()
+}
+
+// LivenessHandler serves /healthz: 200 while the process is running
+func (m *Monitor) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, "ok", nil)
+	})
+}
+
+// ReadinessHandler serves /readyz: 200 when every check passed on the last run,
+// 503 otherwise (and during shutdown), with the failing checks in the body
+func (m *Monitor) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		checks := make(map[string]string, len(m.checks))
+		ready := !m.shuttingDown
+		for _, c := range m.checks {
+			err, ok := m.results[c.name]
+			switch {
+			case !ok:
+				checks[c.name] = "pending"
+				ready = false
+			case err != nil:
+				checks[c.name] = err.Error()
+				ready = false
+			default:
+				checks[c.name] = "ok"
+			}
+		}
+
+		switch {
+		case m.shuttingDown:
+			writeStatus(w, http.StatusServiceUnavailable, "shutting down", checks)
+		case !ready:
+			writeStatus(w, http.StatusServiceUnavailable, "not ready", checks)
+		default:
+			writeStatus(w, http.StatusOK, "ok", checks)
+		}
+	})
+}
+
+// servingStatus returns SERVING if every check the service depends on passed.
+// The overall status (service "") depends on every check.
+func servingStatus(checks []check, results map[string]error, service string) healthpb.HealthCheckResponse_ServingStatus {
+	for _, c := range checks {
+		if service != "" && !dependsOn(c, service) {
+			continue
+		}
+		if results[c.name] != nil {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// dependsOn reports whether the service depends on the check
+func dependsOn(c check, service string) bool {
+	if len(c.services) == 0 {
+		return true
+	}
+	for _, s := range c.services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStatus writes a JSON health response
+func writeStatus(w http.ResponseWriter, code int, status string, checks map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks,omitempty"`
+	}{status, checks})
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	permissionService = "keruberosu.v1.Permission"
+	dataService       = "keruberosu.v1.Data"
+)
+
+func servingStatusOf(t *testing.T, m *Monitor, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := m.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("health check for %q failed: %v", service, err)
+	}
+	return resp.Status
+}
+
+func TestMonitor_ServingStatus(t *testing.T) {
+	var listenerErr error
+	m := NewMonitor([]string{permissionService, dataService}, time.Second)
+	m.AddCheck("database.primary", func(ctx context.Context) error { return nil })
+	m.AddCheck("snapshot_listener", func(ctx context.Context) error { return listenerErr }, permissionService)
+
+	if got := servingStatusOf(t, m, permissionService); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING before the first run, got %v", got)
+	}
+
+	m.RunChecks(context.Background())
+	for _, service := range []string{"", permissionService, dataService} {
+		if got := servingStatusOf(t, m, service); got != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q: expected SERVING, got %v", service, got)
+		}
+	}
+
+	// A failing check only affects the services that depend on it (and the overall status)
+	listenerErr = errors.New("not connected")
+	m.RunChecks(context.Background())
+	if got := servingStatusOf(t, m, permissionService); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected Permission NOT_SERVING, got %v", got)
+	}
+	if got := servingStatusOf(t, m, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected overall NOT_SERVING, got %v", got)
+	}
+	if got := servingStatusOf(t, m, dataService); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected Data SERVING, got %v", got)
+	}
+
+	listenerErr = nil
+	m.RunChecks(context.Background())
+	if got := servingStatusOf(t, m, permissionService); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected Permission SERVING after recovery, got %v", got)
+	}
+}
+
+func TestMonitor_Shutdown(t *testing.T) {
+	m := NewMonitor([]string{permissionService}, time.Hour)
+	m.AddCheck("database.primary", func(ctx context.Context) error { return nil })
+	m.Start()
+
+	if got := servingStatusOf(t, m, permissionService); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING after start, got %v", got)
+	}
+
+	m.Shutdown()
+	for _, service := range []string{"", permissionService} {
+		if got := servingStatusOf(t, m, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("%q: expected NOT_SERVING after shutdown, got %v", service, got)
+		}
+	}
+
+	// Checks after shutdown do not flip the status back
+	m.RunChecks(context.Background())
+	if got := servingStatusOf(t, m, permissionService); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING to persist, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz 503 during shutdown, got %d", rec.Code)
+	}
+}
+
+func TestMonitor_HTTPHandlers(t *testing.T) {
+	var primaryErr error
+	m := NewMonitor(nil, time.Second)
+	m.AddCheck("database.primary", func(ctx context.Context) error { return primaryErr })
+
+	tests := []struct {
+		name       string
+		run        bool
+		err        error
+		wantCode   int
+		wantInBody string
+	}{
+		{"pending before the first run", false, nil, http.StatusServiceUnavailable, "pending"},
+		{"ready", true, nil, http.StatusOK, `"database.primary":"ok"`},
+		{"dependency down", true, errors.New("connection refused"), http.StatusServiceUnavailable, "connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primaryErr = tt.err
+			if tt.run {
+				m.RunChecks(context.Background())
+			}
+			rec := httptest.NewRecorder()
+			m.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantInBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.wantInBody, rec.Body)
+			}
+		})
+	}
+
+	// Liveness does not depend on the checks
+	rec := httptest.NewRecorder()
+	m.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected /healthz 200, got %d", rec.Code)
+	}
+}
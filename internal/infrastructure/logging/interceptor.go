@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/infrastructure/tenant"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the gRPC metadata key (and HTTP header) carrying the request ID.
+// An incoming request ID is kept; otherwise one is generated. It is returned in
+// the response header either way.
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLength bounds client-supplied request IDs, which are logged verbatim
+const maxRequestIDLength = 128
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that adds the
+// request ID and method to the log records of each request, and logs its
+// completion (at debug level, or error level for server-side failures). It must
+// run before the tenant and auth interceptors, so requests they reject are logged
+// and get a request ID too; UnaryTenantInterceptor adds the tenant once resolved.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx = newRequestContext(ctx, info.FullMethod)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCompletion(ctx, start, err)
+		return resp, err
+	}
+}
+
+// UnaryTenantInterceptor returns a gRPC unary server interceptor that adds the
+// request's tenant to its log records. It must run after the tenant and auth
+// interceptors, so the resolved tenant is logged.
+func UnaryTenantInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if tenantID, ok := tenant.FromRequest(req); ok {
+			SetTenant(ctx, tenantID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor equivalent to
+// UnaryServerInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		stream := &loggingStream{ServerStream: ss, ctx: newRequestContext(ss.Context(), info.FullMethod)}
+
+		start := time.Now()
+		err := handler(srv, stream)
+		logCompletion(stream.ctx, start, err)
+		return err
+	}
+}
+
+// StreamTenantInterceptor returns a gRPC stream server interceptor equivalent to
+// UnaryTenantInterceptor. The tenant is taken from the first received message.
+func StreamTenantInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &tenantStream{ServerStream: ss})
+	}
+}
+
+// loggingStream carries the request context
+type loggingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+// tenantStream records the tenant of the first message
+type tenantStream struct {
+	grpc.ServerStream
+	received bool
+}
+
+func (s *tenantStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.received {
+		s.received = true
+		if tenantID, ok := tenant.FromRequest(m); ok {
+			SetTenant(s.Context(), tenantID)
+		}
+	}
+	return nil
+}
+
+// newRequestContext returns ctx with the request's log fields, and sends the
+// request ID back in the response header
+func newRequestContext(ctx context.Context, method string) context.Context {
+	requestID := requestIDFromMetadata(ctx)
+	if requestID == "" {
+		requestID = ulid.Make().String()
+	}
+	// Fails only if headers were already sent, which cannot happen before the handler runs
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+	return NewContext(ctx, requestID, method)
+}
+
+// requestIDFromMetadata returns the caller's request ID, or "" if it is missing
+// or not a printable string of at most maxRequestIDLength characters
+func requestIDFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(RequestIDKey)
+	if len(values) == 0 {
+		return ""
+	}
+	requestID := strings.TrimSpace(values[0])
+	if len(requestID) > maxRequestIDLength {
+		return ""
+	}
+	for _, r := range requestID {
+		if r < 0x20 || r > 0x7e {
+			return ""
+		}
+	}
+	return requestID
+}
+
+// logCompletion logs the outcome of a request. Server-side failures are logged
+// at error level; everything else at debug level, so enabling debug logging
+// shows every request.
+func logCompletion(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable:
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		slog.LogAttrs(ctx, slog.LevelError, "Request failed", attrs...)
+	default:
+		slog.LogAttrs(ctx, slog.LevelDebug, "Request completed", attrs...)
+	}
+}
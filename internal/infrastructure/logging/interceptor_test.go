@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// useTestLogger makes a debug-level test logger the slog default for the test
+func useTestLogger(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(newTestLogger(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// chainUnary runs outer, then inner, then the handler, like grpc.ChainUnaryInterceptor
+func chainUnary(outer, inner grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return outer(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return inner(ctx, req, info, handler)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buf := useTestLogger(t)
+	interceptor := chainUnary(UnaryServerInterceptor(), UnaryTenantInterceptor())
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_Check_FullMethodName}
+
+	var requestID string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		requestID = RequestID(ctx)
+		SetSchemaVersion(ctx, "v1")
+		slog.WarnContext(ctx, "in handler")
+		return "ok", nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "client-request-1"))
+	if _, err := interceptor(ctx, &pb.PermissionCheckRequest{TenantId: "t1"}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestID != "client-request-1" {
+		t.Errorf("Expected the client's request ID, got %q", requestID)
+	}
+
+	records := decodeLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected the handler's record and the completion record, got %v", records)
+	}
+	for _, record := range records {
+		if record["request_id"] != "client-request-1" || record["tenant_id"] != "t1" ||
+			record["method"] != pb.Permission_Check_FullMethodName || record["schema_version"] != "v1" {
+			t.Errorf("Expected request fields, got %v", record)
+		}
+	}
+	if records[1]["level"] != "DEBUG" || records[1]["code"] != "OK" {
+		t.Errorf("Expected a debug completion record, got %v", records[1])
+	}
+
+	t.Run("正常系: リクエスト ID がなければ生成", func(t *testing.T) {
+		buf.Reset()
+		interceptor(context.Background(), &pb.PermissionCheckRequest{}, info, handler)
+		if len(requestID) != 26 {
+			t.Errorf("Expected a generated ULID, got %q", requestID)
+		}
+		if records := decodeLines(t, buf); records[0]["tenant_id"] != "default" {
+			t.Errorf("Expected the default tenant, got %v", records[0])
+		}
+	})
+
+	t.Run("正常系: 不正なリクエスト ID は置き換える", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "bad\nid"))
+		interceptor(ctx, &pb.PermissionCheckRequest{}, info, handler)
+		if requestID == "bad\nid" || requestID == "" {
+			t.Errorf("Expected a generated request ID, got %q", requestID)
+		}
+	})
+
+	t.Run("異常系: テナント解決前に拒否したリクエストも記録", func(t *testing.T) {
+		buf.Reset()
+		rejecting := chainUnary(UnaryServerInterceptor(), func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		})
+		rejecting(context.Background(), &pb.PermissionCheckRequest{}, info, handler)
+		records := decodeLines(t, buf)
+		if len(records) != 1 || records[0]["code"] != "Unauthenticated" || records[0]["request_id"] == nil {
+			t.Errorf("Expected a completion record with a request ID, got %v", records)
+		}
+	})
+
+	t.Run("異常系: サーバーエラーは error レベル", func(t *testing.T) {
+		buf.Reset()
+		failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "database unavailable")
+		}
+		interceptor(context.Background(), &pb.PermissionCheckRequest{}, info, failing)
+		records := decodeLines(t, buf)
+		if len(records) != 1 || records[0]["level"] != "ERROR" || records[0]["error"] != "database unavailable" {
+			t.Errorf("Expected an error record, got %v", records)
+		}
+	})
+}
+
+// fakeServerStream replays one request
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *pb.PermissionLookupEntityRequest
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	m.(*pb.PermissionLookupEntityRequest).TenantId = s.req.TenantId
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	buf := useTestLogger(t)
+	interceptor := StreamServerInterceptor()
+	tenantInterceptor := StreamTenantInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: pb.Permission_LookupEntityStream_FullMethodName}
+	stream := &fakeServerStream{ctx: context.Background(), req: &pb.PermissionLookupEntityRequest{TenantId: "t2"}}
+
+	err := interceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return tenantInterceptor(srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			req := &pb.PermissionLookupEntityRequest{}
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+			slog.InfoContext(ss.Context(), "streaming")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := decodeLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %v", records)
+	}
+	for _, record := range records {
+		if record["tenant_id"] != "t2" || record["method"] != pb.Permission_LookupEntityStream_FullMethodName || record["request_id"] == nil {
+			t.Errorf("Expected request fields, got %v", record)
+		}
+	}
+}
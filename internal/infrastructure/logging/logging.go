@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs a JSON logger writing to w as the slog default, also receiving
+// output of the standard log package (from dependencies), and returns the level
+// variable that changes the log level at runtime
+func Setup(w io.Writer, level slog.Level) *slog.LevelVar {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+	logger := slog.New(NewHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: levelVar})))
+	slog.SetDefault(logger)
+	log.SetOutput(slog.NewLogLogger(logger.Handler(), slog.LevelInfo).Writer())
+	log.SetFlags(0)
+	return levelVar
+}
+
+// ParseLevel parses a log level name (debug, info, warn or error, case-insensitive)
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// Handler adds the request fields of the context (request ID, method, tenant,
+// schema version) and the trace ID of the current span to each record
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps next so records logged with a request context carry its fields
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
+}
+
+// Handle adds the context's request fields to r before passing it on
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		r.AddAttrs(fields.attrs()...)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		r.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler whose records also carry attrs
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a Handler qualifying later attributes with name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
+
+// fieldsKey is the context key of *requestFields
+type fieldsKey struct{}
+
+// requestFields are the per-request log fields. The tenant and schema version are
+// filled in while the request is processed, so they are guarded by a mutex
+// (BulkCheck evaluates items concurrently).
+type requestFields struct {
+	requestID string
+	method    string
+
+	mu            sync.Mutex
+	tenantID      string
+	schemaVersion string
+}
+
+func (f *requestFields) attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("request_id", f.requestID),
+		slog.String("method", f.method),
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tenantID != "" {
+		attrs = append(attrs, slog.String("tenant_id", f.tenantID))
+	}
+	if f.schemaVersion != "" {
+		attrs = append(attrs, slog.String("schema_version", f.schemaVersion))
+	}
+	return attrs
+}
+
+// NewContext returns a context whose log records carry requestID and method
+func NewContext(ctx context.Context, requestID, method string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &requestFields{requestID: requestID, method: method})
+}
+
+// RequestID returns the request ID of ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		return fields.requestID
+	}
+	return ""
+}
+
+// SetTenant sets the tenant logged with the request of ctx (no-op outside a request)
+func SetTenant(ctx context.Context, tenantID string) {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		fields.mu.Lock()
+		fields.tenantID = tenantID
+		fields.mu.Unlock()
+	}
+}
+
+// SetSchemaVersion sets the schema version logged with the request of ctx
+// (no-op outside a request)
+func SetSchemaVersion(ctx context.Context, version string) {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		fields.mu.Lock()
+		fields.schemaVersion = version
+		fields.mu.Unlock()
+	}
+}
+
+// LevelHandler serves the log level: GET returns {"level": "INFO"}, and PUT with
+// a body of {"level": "debug"} changes it without restarting the server. The
+// handler is unauthenticated, so PUT is rejected with 403 unless changeable.
+func LevelHandler(levelVar *slog.LevelVar, changeable bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if !changeable {
+				http.Error(w, "changing the log level is disabled", http.StatusForbidden)
+				return
+			}
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&body); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			level, err := ParseLevel(body.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if previous := levelVar.Level(); previous != level {
+				levelVar.Set(level)
+				slog.Warn("Log level changed", "previous", previous.String(), "level", level.String())
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"level": levelVar.Level().String()})
+	})
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestLogger returns a JSON logger writing to buf through Handler
+func newTestLogger(buf *bytes.Buffer, level slog.Leveler) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level})))
+}
+
+// decodeLines decodes each JSON log line in buf
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestHandler_AddsRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, slog.LevelInfo)
+
+	ctx := NewContext(context.Background(), "req-1", "/keruberosu.v1.Permission/Check")
+	SetTenant(ctx, "tenant1")
+	SetSchemaVersion(ctx, "01HSCHEMA")
+	logger.With("component", "evaluator").WarnContext(ctx, "query failed", "error", "timeout")
+	logger.Info("no request")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	want := map[string]interface{}{
+		"level":          "WARN",
+		"msg":            "query failed",
+		"component":      "evaluator",
+		"error":          "timeout",
+		"request_id":     "req-1",
+		"method":         "/keruberosu.v1.Permission/Check",
+		"tenant_id":      "tenant1",
+		"schema_version": "01HSCHEMA",
+	}
+	for key, value := range want {
+		if records[0][key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, records[0][key])
+		}
+	}
+	if _, ok := records[1]["request_id"]; ok {
+		t.Errorf("Expected no request fields outside a request, got %v", records[1])
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	levelVar := new(slog.LevelVar)
+	handler := LevelHandler(levelVar, true)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"level":"INFO"`) {
+		t.Errorf("Expected INFO, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK || levelVar.Level() != slog.LevelDebug {
+		t.Errorf("Expected the level to change to DEBUG, got %d %s (level %v)", rec.Code, rec.Body.String(), levelVar.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"verbose"}`)))
+	if rec.Code != http.StatusBadRequest || levelVar.Level() != slog.LevelDebug {
+		t.Errorf("Expected 400 and an unchanged level, got %d (level %v)", rec.Code, levelVar.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/loglevel", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+
+	t.Run("異常系: 変更が無効なら PUT は 403", func(t *testing.T) {
+		readOnly := LevelHandler(levelVar, false)
+		rec := httptest.NewRecorder()
+		readOnly.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"error"}`)))
+		if rec.Code != http.StatusForbidden || levelVar.Level() != slog.LevelDebug {
+			t.Errorf("Expected 403 and an unchanged level, got %d (level %v)", rec.Code, levelVar.Level())
+		}
+
+		rec = httptest.NewRecorder()
+		readOnly.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"level":"DEBUG"`) {
+			t.Errorf("Expected GET to still return the level, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
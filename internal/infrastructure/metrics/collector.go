@@ -5,7 +5,7 @@ import (
 	"sync/atomic"
 
 	"github.com/asakaida/keruberosu/pkg/cache"
-	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
+	"github.com/asakaida/keruberosu/pkg/cache/tieredcache"
 )
 
 // Collector collects and aggregates metrics for the application.
@@ -19,6 +19,12 @@ type Collector struct {
 	cache cache.Cache
 }
 
+// sizedCache is implemented by caches that report their current size (memorycache)
+type sizedCache interface {
+	Len() int
+	Size() int64
+}
+
 // durationValue holds duration with mutex for thread-safe updates.
 type durationValue struct {
 	mu           sync.Mutex
@@ -92,10 +98,14 @@ func (c *Collector) GetCacheMetrics() *CacheMetrics {
 		Evictions: metrics.KeysEvicted,
 	}
 
-	// Get current keys and memory if available
-	if memCache, ok := c.cache.(*memorycache.Cache); ok {
-		result.KeysCurrent = int64(memCache.Len())
-		result.MemoryBytes = memCache.Size()
+	// Get current keys and memory if available (of the in-process tier for a tiered cache)
+	current := c.cache
+	if tiered, ok := current.(*tieredcache.Cache); ok {
+		current = tiered.Local()
+	}
+	if sized, ok := current.(sizedCache); ok {
+		result.KeysCurrent = int64(sized.Len())
+		result.MemoryBytes = sized.Size()
 	}
 
 	return result
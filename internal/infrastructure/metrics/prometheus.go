@@ -19,6 +19,12 @@ type PrometheusExporter struct {
 	grpcRequests     *prometheus.CounterVec
 	grpcDuration     *prometheus.HistogramVec
 	grpcErrors       *prometheus.CounterVec
+	grpcThrottled    *prometheus.CounterVec
+
+	// Per-check evaluation statistics
+	checkSubChecks      prometheus.Histogram
+	checkQueries        prometheus.Histogram
+	checkCELEvaluations prometheus.Histogram
 
 	// Last known cumulative values for delta calculation
 	lastHits      uint64
@@ -81,9 +87,34 @@ func NewPrometheusExporter(collector *Collector, registry prometheus.Registerer)
 			},
 			[]string{"method"},
 		),
+		grpcThrottled: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "keruberosu_grpc_throttled_total",
+				Help: "Total number of gRPC requests rejected by per-tenant rate limits",
+			},
+			[]string{"method", "tenant", "class", "reason"},
+		),
+		checkSubChecks: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "keruberosu_check_evaluated_nodes",
+			Help:    "Number of rule nodes (sub-checks) evaluated per permission check",
+			Buckets: evaluationCountBuckets,
+		}),
+		checkQueries: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "keruberosu_check_repository_queries",
+			Help:    "Number of relation and attribute repository queries per permission check",
+			Buckets: evaluationCountBuckets,
+		}),
+		checkCELEvaluations: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "keruberosu_check_cel_evaluations",
+			Help:    "Number of CEL expression evaluations per permission check",
+			Buckets: evaluationCountBuckets,
+		}),
 	}
 }
 
+// evaluationCountBuckets covers single-node checks up to badly fanning-out schemas
+var evaluationCountBuckets = []float64{0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}
+
 // Update updates Gauge metrics from the collector.
 // Counters are updated via interceptor, so only update gauges here.
 // This should be called periodically (e.g., every 10 seconds).
@@ -126,6 +157,12 @@ func (e *PrometheusExporter) RecordError(method string) {
 	e.grpcErrors.WithLabelValues(method).Inc()
 }
 
+// RecordThrottle records a request rejected by the rate limiter.
+// reason is "rate" (token bucket) or "concurrency" (in-flight limit).
+func (e *PrometheusExporter) RecordThrottle(method, tenantID, class, reason string) {
+	e.grpcThrottled.WithLabelValues(method, tenantID, class, reason).Inc()
+}
+
 // RecordCacheHit records a cache hit.
 func (e *PrometheusExporter) RecordCacheHit() {
 	e.cacheHits.Inc()
@@ -140,3 +177,10 @@ func (e *PrometheusExporter) RecordCacheMiss() {
 func (e *PrometheusExporter) RecordCacheEviction() {
 	e.cacheEvictions.Inc()
 }
+
+// RecordCheckStats records the evaluation statistics of a single permission check.
+func (e *PrometheusExporter) RecordCheckStats(checkCount, queryCount, celEvaluations int) {
+	e.checkSubChecks.Observe(float64(checkCount))
+	e.checkQueries.Observe(float64(queryCount))
+	e.checkCELEvaluations.Observe(float64(celEvaluations))
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheusExporter_RecordCheckStats(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	exporter := NewPrometheusExporter(NewCollector(), registry)
+
+	exporter.RecordCheckStats(7, 4, 1)
+	exporter.RecordCheckStats(3, 2, 0)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	want := map[string]float64{
+		"keruberosu_check_evaluated_nodes":    10,
+		"keruberosu_check_repository_queries": 6,
+		"keruberosu_check_cel_evaluations":    1,
+	}
+	for _, family := range families {
+		wantSum, ok := want[family.GetName()]
+		if !ok {
+			continue
+		}
+		delete(want, family.GetName())
+
+		histogram := family.GetMetric()[0].GetHistogram()
+		if histogram.GetSampleCount() != 2 {
+			t.Errorf("%s: expected 2 observations, got %d", family.GetName(), histogram.GetSampleCount())
+		}
+		if histogram.GetSampleSum() != wantSum {
+			t.Errorf("%s: expected sum %v, got %v", family.GetName(), wantSum, histogram.GetSampleSum())
+		}
+	}
+	for name := range want {
+		t.Errorf("histogram %s was not exported", name)
+	}
+}
+
+func TestPrometheusExporter_RecordThrottle(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	exporter := NewPrometheusExporter(NewCollector(), registry)
+
+	exporter.RecordThrottle("/keruberosu.v1.Permission/LookupEntity", "t1", "lookup", "rate")
+	exporter.RecordThrottle("/keruberosu.v1.Permission/LookupEntity", "t1", "lookup", "rate")
+	exporter.RecordThrottle("/keruberosu.v1.Data/Write", "t2", "write", "concurrency")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "keruberosu_grpc_throttled_total" {
+			continue
+		}
+		counts := make(map[string]float64)
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counts[labels["tenant"]+"/"+labels["class"]+"/"+labels["reason"]] = metric.GetCounter().GetValue()
+		}
+		if counts["t1/lookup/rate"] != 2 || counts["t2/write/concurrency"] != 1 {
+			t.Errorf("unexpected throttle counts: %v", counts)
+		}
+		return
+	}
+	t.Error("counter keruberosu_grpc_throttled_total was not exported")
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/asakaida/keruberosu/internal/infrastructure/tenant"
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Recorder records throttled requests (implemented by metrics.PrometheusExporter)
+type Recorder interface {
+	RecordThrottle(method, tenantID, class, reason string)
+}
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that admits
+// requests through the limiter. It must run after the tenant and auth
+// interceptors, so requests are limited by their resolved tenant.
+// recorder may be nil.
+func UnaryServerInterceptor(limiter *Limiter, recorder Recorder) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		class, ok := ClassOf(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		release, err := acquire(limiter, recorder, info.FullMethod, class, req)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor equivalent to
+// UnaryServerInterceptor. The request is admitted when its first message is
+// received, and holds its in-flight slot until the stream ends.
+func StreamServerInterceptor(limiter *Limiter, recorder Recorder) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		class, ok := ClassOf(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+		stream := &limitedStream{
+			ServerStream: ss,
+			limiter:      limiter,
+			recorder:     recorder,
+			method:       info.FullMethod,
+			class:        class,
+		}
+		defer stream.release()
+		return handler(srv, stream)
+	}
+}
+
+// limitedStream admits the stream on its first received message
+type limitedStream struct {
+	grpc.ServerStream
+	limiter  *Limiter
+	recorder Recorder
+	method   string
+	class    Class
+	done     func() // Releases the in-flight slot; nil until admitted
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.done != nil {
+		return nil
+	}
+	release, err := acquire(s.limiter, s.recorder, s.method, s.class, m)
+	if err != nil {
+		return err
+	}
+	s.done = release
+	return nil
+}
+
+func (s *limitedStream) release() {
+	if s.done != nil {
+		s.done()
+	}
+}
+
+// acquire admits req and converts a throttle into a ResourceExhausted status with RetryInfo
+func acquire(limiter *Limiter, recorder Recorder, method string, class Class, req interface{}) (func(), error) {
+	tenantID, _ := tenant.FromRequest(req)
+	release, err := limiter.AcquireN(tenantID, class, costOf(req))
+	if err == nil {
+		return release, nil
+	}
+
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) {
+		return nil, status.Errorf(codes.Internal, "rate limiter failed: %v", err)
+	}
+	if recorder != nil {
+		recorder.RecordThrottle(method, throttled.Bucket, string(class), throttled.Reason)
+	}
+	st, detailErr := status.New(codes.ResourceExhausted, throttled.Error()).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(throttled.RetryAfter),
+	})
+	if detailErr != nil {
+		return nil, status.Error(codes.ResourceExhausted, throttled.Error())
+	}
+	return nil, st.Err()
+}
+
+// costOf returns the tokens a request costs: the item count of a BulkCheck, 1 otherwise
+func costOf(req interface{}) int {
+	if bulk, ok := req.(*pb.PermissionBulkCheckRequest); ok && len(bulk.Items) > 1 {
+		return len(bulk.Items)
+	}
+	return 1
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// throttleRecord is one call to fakeRecorder.RecordThrottle
+type throttleRecord struct {
+	method, tenantID, class, reason string
+}
+
+type fakeRecorder struct {
+	records []throttleRecord
+}
+
+func (r *fakeRecorder) RecordThrottle(method, tenantID, class, reason string) {
+	r.records = append(r.records, throttleRecord{method, tenantID, class, reason})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter(Limits{ClassCheck: {RPS: 1, Burst: 1}}, nil)
+	recorder := &fakeRecorder{}
+	interceptor := UnaryServerInterceptor(limiter, recorder)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_Check_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	if _, err := interceptor(context.Background(), &pb.PermissionCheckRequest{}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := interceptor(context.Background(), &pb.PermissionCheckRequest{}, info, handler)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted, got %v", err)
+	}
+	var retryInfo *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = ri
+		}
+	}
+	if retryInfo == nil || retryInfo.RetryDelay.AsDuration() != time.Second {
+		t.Errorf("Expected RetryInfo with a 1s delay, got %v", st.Details())
+	}
+
+	want := throttleRecord{pb.Permission_Check_FullMethodName, "default", "check", ReasonRate}
+	if len(recorder.records) != 1 || recorder.records[0] != want {
+		t.Errorf("Expected throttle record %+v, got %+v", want, recorder.records)
+	}
+
+	t.Run("正常系: テナントごとに制限", func(t *testing.T) {
+		if _, err := interceptor(context.Background(), &pb.PermissionCheckRequest{TenantId: "t1"}, info, handler); err != nil {
+			t.Errorf("Expected tenant t1 to be admitted, got %v", err)
+		}
+	})
+
+	t.Run("正常系: 対象外のメソッドは制限しない", func(t *testing.T) {
+		readInfo := &grpc.UnaryServerInfo{FullMethod: pb.Data_Read_FullMethodName}
+		if _, err := interceptor(context.Background(), &pb.DataReadRequest{}, readInfo, handler); err != nil {
+			t.Errorf("Expected Data.Read to be admitted, got %v", err)
+		}
+	})
+}
+
+func TestUnaryServerInterceptor_BulkCheckCost(t *testing.T) {
+	limiter, _ := newTestLimiter(Limits{ClassCheck: {RPS: 1, Burst: 3}}, nil)
+	interceptor := UnaryServerInterceptor(limiter, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_BulkCheck_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	req := &pb.PermissionBulkCheckRequest{Items: make([]*pb.PermissionBulkCheckRequestItem, 2)}
+
+	if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := interceptor(context.Background(), req, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the second 2-item BulkCheck to be throttled, got %v", err)
+	}
+}
+
+func TestUnaryServerInterceptor_ReleasesInFlight(t *testing.T) {
+	limiter, _ := newTestLimiter(Limits{ClassWrite: {MaxInFlight: 1}}, nil)
+	interceptor := UnaryServerInterceptor(limiter, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Data_Write_FullMethodName}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		// The request holds the only slot while it is being handled
+		_, err := interceptor(ctx, &pb.DataWriteRequest{}, info, func(context.Context, interface{}) (interface{}, error) {
+			t.Error("nested handler must not be called")
+			return nil, nil
+		})
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected ResourceExhausted, got %v", err)
+		}
+		return "ok", nil
+	}
+	if _, err := interceptor(context.Background(), &pb.DataWriteRequest{}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := interceptor(context.Background(), &pb.DataWriteRequest{}, info, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}); err != nil {
+		t.Errorf("Expected the slot to be released, got %v", err)
+	}
+}
+
+// fakeServerStream delivers one empty request
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter(Limits{ClassLookup: {MaxInFlight: 1}}, nil)
+	interceptor := StreamServerInterceptor(limiter, nil)
+	info := &grpc.StreamServerInfo{FullMethod: pb.Permission_LookupEntityStream_FullMethodName}
+
+	err := interceptor(nil, &fakeServerStream{}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		if err := ss.RecvMsg(&pb.PermissionLookupEntityRequest{}); err != nil {
+			return err
+		}
+		if limiter.buckets[bucketKey{tenant: "default", class: ClassLookup}].inFlight != 1 {
+			t.Error("Expected the stream to hold an in-flight slot")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inFlight := limiter.buckets[bucketKey{tenant: "default", class: ClassLookup}].inFlight; inFlight != 0 {
+		t.Errorf("Expected the slot to be released, got %d in flight", inFlight)
+	}
+}
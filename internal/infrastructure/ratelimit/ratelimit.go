@@ -0,0 +1,258 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+)
+
+// Class groups RPCs that share a limit
+type Class string
+
+const (
+	ClassCheck  Class = "check"  // Permission checks and expansions
+	ClassLookup Class = "lookup" // Entity and subject lookups
+	ClassWrite  Class = "write"  // Schema and data mutations
+)
+
+// Classes lists every class, in the order they are documented
+var Classes = []Class{ClassCheck, ClassLookup, ClassWrite}
+
+// methodClasses is the class of each limited RPC. RPCs not listed here
+// (reads, Watch, audit logs, tenancy, health) are not limited.
+var methodClasses = map[string]Class{
+	pb.Permission_Check_FullMethodName:              ClassCheck,
+	pb.Permission_BulkCheck_FullMethodName:          ClassCheck,
+	pb.Permission_Expand_FullMethodName:             ClassCheck,
+	pb.Permission_SubjectPermission_FullMethodName:  ClassCheck,
+	pb.Permission_LookupEntity_FullMethodName:       ClassLookup,
+	pb.Permission_LookupEntityStream_FullMethodName: ClassLookup,
+	pb.Permission_LookupSubject_FullMethodName:      ClassLookup,
+	pb.Data_Write_FullMethodName:                    ClassWrite,
+	pb.Data_Delete_FullMethodName:                   ClassWrite,
+	pb.Schema_Write_FullMethodName:                  ClassWrite,
+}
+
+// ClassOf returns the class of an RPC, or false if the RPC is not limited
+func ClassOf(fullMethod string) (Class, bool) {
+	class, ok := methodClasses[fullMethod]
+	return class, ok
+}
+
+// ParseClass parses a class name
+func ParseClass(name string) (Class, error) {
+	for _, class := range Classes {
+		if string(class) == name {
+			return class, nil
+		}
+	}
+	return "", fmt.Errorf("unknown rate limit class %q (expected check, lookup or write)", name)
+}
+
+// Limit is the admission limit of one tenant for one class
+type Limit struct {
+	RPS         float64 // Sustained requests per second (0 = unlimited)
+	Burst       int     // Token bucket size; 0 means max(1, RPS)
+	MaxInFlight int     // Requests processed concurrently (0 = unlimited)
+}
+
+// Limits holds a limit per class
+type Limits map[Class]Limit
+
+// Throttle reasons, used as the "reason" metric label
+const (
+	ReasonRate        = "rate"
+	ReasonConcurrency = "concurrency"
+)
+
+// OtherTenant is the metric label of requests for tenants not known to the tenant
+// filter, which are limited together in one shared bucket per class
+const OtherTenant = "other"
+
+// sweepInterval is how often idle buckets are evicted
+const sweepInterval = time.Minute
+
+// concurrencyRetryDelay is the retry delay suggested when every in-flight slot is taken.
+// Unlike the token bucket, the time until a slot frees up is unknown.
+const concurrencyRetryDelay = 100 * time.Millisecond
+
+// ThrottledError is returned by Acquire when a request is not admitted
+type ThrottledError struct {
+	Tenant     string
+	Bucket     string // Tenant whose bucket was exhausted: Tenant, or OtherTenant for unknown tenants
+	Class      Class
+	Reason     string        // ReasonRate or ReasonConcurrency
+	RetryAfter time.Duration // Suggested delay before retrying
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Reason == ReasonConcurrency {
+		return fmt.Sprintf("too many concurrent %s requests for tenant %s", e.Class, e.Tenant)
+	}
+	return fmt.Sprintf("%s rate limit exceeded for tenant %s", e.Class, e.Tenant)
+}
+
+// Limiter admits requests per (tenant, class) with a token bucket and an
+// in-flight counter. Tenants without overrides use the default limits.
+//
+// With a tenant filter, tenants it does not know (and that have no overrides)
+// share one bucket per class, so client-supplied tenant IDs cannot create
+// unbounded buckets or start with a fresh burst. Buckets that are full and idle
+// are evicted, as they hold no state a new bucket would not.
+type Limiter struct {
+	defaults  Limits
+	overrides map[string]Limits // tenant ID -> class -> limit, replacing the default of that class
+	known     func(tenantID string) bool
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+// bucketKey identifies a bucket. The shared bucket of unknown tenants has an
+// empty tenant, which never names a real tenant.
+type bucketKey struct {
+	tenant string
+	class  Class
+}
+
+// bucket is the admission state of one (tenant, class)
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	inFlight int
+}
+
+// NewLimiter creates a limiter with default limits and per-tenant overrides (which may be nil)
+func NewLimiter(defaults Limits, overrides map[string]Limits) *Limiter {
+	return &Limiter{
+		defaults:  defaults,
+		overrides: overrides,
+		now:       time.Now,
+		buckets:   make(map[bucketKey]*bucket),
+	}
+}
+
+// SetTenantFilter limits tenants for which known returns false together, in a
+// shared bucket labeled OtherTenant. Tenants with overrides always get their own
+// bucket. known is called on every request, so it must be cheap.
+func (l *Limiter) SetTenantFilter(known func(tenantID string) bool) {
+	l.known = known
+}
+
+// LimitFor returns the limit applied to a tenant for a class
+func (l *Limiter) LimitFor(tenantID string, class Class) Limit {
+	if limits, ok := l.overrides[tenantID]; ok {
+		if limit, ok := limits[class]; ok {
+			return limit
+		}
+	}
+	return l.defaults[class]
+}
+
+// Acquire admits one request of class for tenantID. On success, the returned
+// release function must be called once the request completes. Otherwise the
+// error is a *ThrottledError and no token or slot is consumed.
+func (l *Limiter) Acquire(tenantID string, class Class) (func(), error) {
+	return l.AcquireN(tenantID, class, 1)
+}
+
+// AcquireN is like Acquire for a request costing n tokens (such as a BulkCheck of
+// n items). A request costing more than the burst needs a full bucket.
+func (l *Limiter) AcquireN(tenantID string, class Class, n int) (func(), error) {
+	limit := l.LimitFor(tenantID, class)
+	if limit.RPS <= 0 && limit.MaxInFlight <= 0 {
+		return func() {}, nil
+	}
+	bucketTenant := l.bucketTenant(tenantID)
+	key := bucketKey{tenant: bucketTenant, class: class}
+	burst := limit.burst()
+	cost := math.Min(math.Max(1, float64(n)), burst)
+	if bucketTenant == "" {
+		bucketTenant = OtherTenant
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	if limit.MaxInFlight > 0 && b.inFlight >= limit.MaxInFlight {
+		return nil, &ThrottledError{Tenant: tenantID, Bucket: bucketTenant, Class: class, Reason: ReasonConcurrency, RetryAfter: concurrencyRetryDelay}
+	}
+
+	if limit.RPS > 0 {
+		b.refill(now, limit.RPS, burst)
+		if b.tokens < cost {
+			wait := time.Duration((cost - b.tokens) / limit.RPS * float64(time.Second))
+			return nil, &ThrottledError{Tenant: tenantID, Bucket: bucketTenant, Class: class, Reason: ReasonRate, RetryAfter: wait}
+		}
+		b.tokens -= cost
+	}
+
+	b.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			b.inFlight--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// bucketTenant returns the tenant whose bucket limits tenantID: tenantID itself,
+// or "" (the shared bucket) for a tenant unknown to the tenant filter
+func (l *Limiter) bucketTenant(tenantID string) string {
+	if l.known == nil || l.known(tenantID) {
+		return tenantID
+	}
+	if _, ok := l.overrides[tenantID]; ok {
+		return tenantID
+	}
+	return ""
+}
+
+// sweep evicts the buckets with no request in flight whose tokens have refilled
+// since their last use. Called with l.mu held.
+func (l *Limiter) sweep(now time.Time) {
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.inFlight > 0 {
+			continue
+		}
+		limit := l.LimitFor(key.tenant, key.class)
+		if limit.RPS > 0 && b.tokens+now.Sub(b.updated).Seconds()*limit.RPS < limit.burst() {
+			continue
+		}
+		delete(l.buckets, key)
+	}
+}
+
+// refill adds the tokens accrued since the bucket was last updated
+func (b *bucket) refill(now time.Time, rps, burst float64) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rps)
+	}
+	b.updated = now
+}
+
+// burst returns the bucket size of the limit
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, l.RPS)
+}
@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+)
+
+// newTestLimiter returns a limiter whose clock is advanced by the returned function
+func newTestLimiter(defaults Limits, overrides map[string]Limits) (*Limiter, func(time.Duration)) {
+	limiter := NewLimiter(defaults, overrides)
+	now := time.Unix(0, 0)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		method string
+		want   Class
+		ok     bool
+	}{
+		{pb.Permission_Check_FullMethodName, ClassCheck, true},
+		{pb.Permission_LookupEntity_FullMethodName, ClassLookup, true},
+		{pb.Permission_LookupEntityStream_FullMethodName, ClassLookup, true},
+		{pb.Data_Write_FullMethodName, ClassWrite, true},
+		{pb.Data_Read_FullMethodName, "", false},
+		{pb.Tenancy_List_FullMethodName, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ClassOf(tt.method)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ClassOf(%s) = %q, %v, want %q, %v", tt.method, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	limiter, advance := newTestLimiter(Limits{ClassLookup: {RPS: 2, Burst: 2}}, nil)
+
+	for i := 0; i < 2; i++ {
+		release, err := limiter.Acquire("t1", ClassLookup)
+		if err != nil {
+			t.Fatalf("request %d: expected to be admitted, got %v", i, err)
+		}
+		release()
+	}
+
+	t.Run("異常系: バーストを使い切ると拒否", func(t *testing.T) {
+		_, err := limiter.Acquire("t1", ClassLookup)
+		var throttled *ThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected ThrottledError, got %v", err)
+		}
+		if throttled.Reason != ReasonRate {
+			t.Errorf("Expected reason %s, got %s", ReasonRate, throttled.Reason)
+		}
+		if throttled.RetryAfter != 500*time.Millisecond {
+			t.Errorf("Expected retry after 500ms, got %v", throttled.RetryAfter)
+		}
+	})
+
+	t.Run("正常系: 他のテナントとクラスは独立", func(t *testing.T) {
+		if _, err := limiter.Acquire("t2", ClassLookup); err != nil {
+			t.Errorf("Expected t2 to be admitted, got %v", err)
+		}
+		if _, err := limiter.Acquire("t1", ClassCheck); err != nil {
+			t.Errorf("Expected unlimited check class to be admitted, got %v", err)
+		}
+	})
+
+	t.Run("正常系: トークンが補充される", func(t *testing.T) {
+		advance(500 * time.Millisecond)
+		if _, err := limiter.Acquire("t1", ClassLookup); err != nil {
+			t.Errorf("Expected to be admitted after refill, got %v", err)
+		}
+	})
+}
+
+func TestLimiter_MaxInFlight(t *testing.T) {
+	limiter, _ := newTestLimiter(Limits{ClassWrite: {MaxInFlight: 1}}, nil)
+
+	release, err := limiter.Acquire("t1", ClassWrite)
+	if err != nil {
+		t.Fatalf("Expected to be admitted, got %v", err)
+	}
+
+	_, err = limiter.Acquire("t1", ClassWrite)
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) || throttled.Reason != ReasonConcurrency {
+		t.Fatalf("Expected concurrency throttle, got %v", err)
+	}
+
+	release()
+	release() // Releasing twice must not free a second slot
+	if _, err := limiter.Acquire("t1", ClassWrite); err != nil {
+		t.Errorf("Expected to be admitted after release, got %v", err)
+	}
+	if _, err := limiter.Acquire("t1", ClassWrite); err == nil {
+		t.Error("Expected the second concurrent request to be throttled")
+	}
+}
+
+func TestLimiter_TenantOverrides(t *testing.T) {
+	limiter, _ := newTestLimiter(
+		Limits{ClassLookup: {RPS: 100, Burst: 100}, ClassCheck: {RPS: 1, Burst: 1}},
+		map[string]Limits{"noisy": {ClassLookup: {RPS: 1, Burst: 1}}},
+	)
+
+	if got := limiter.LimitFor("noisy", ClassLookup); got.RPS != 1 {
+		t.Errorf("Expected override RPS 1, got %v", got.RPS)
+	}
+	if got := limiter.LimitFor("noisy", ClassCheck); got.RPS != 1 || got.Burst != 1 {
+		t.Errorf("Expected default check limit, got %+v", got)
+	}
+	if got := limiter.LimitFor("other", ClassLookup); got.RPS != 100 {
+		t.Errorf("Expected default RPS 100, got %v", got.RPS)
+	}
+
+	if _, err := limiter.Acquire("noisy", ClassLookup); err != nil {
+		t.Fatalf("Expected to be admitted, got %v", err)
+	}
+	if _, err := limiter.Acquire("noisy", ClassLookup); err == nil {
+		t.Error("Expected noisy tenant to be throttled")
+	}
+	if _, err := limiter.Acquire("other", ClassLookup); err != nil {
+		t.Errorf("Expected other tenant to be admitted, got %v", err)
+	}
+}
+
+func TestLimiter_TenantFilter(t *testing.T) {
+	limiter, _ := newTestLimiter(
+		Limits{ClassCheck: {RPS: 1, Burst: 1}},
+		map[string]Limits{"vip": {ClassCheck: {RPS: 1, Burst: 1}}},
+	)
+	limiter.SetTenantFilter(func(tenantID string) bool { return tenantID == "t1" })
+
+	if _, err := limiter.Acquire("random-1", ClassCheck); err != nil {
+		t.Fatalf("Expected to be admitted, got %v", err)
+	}
+
+	t.Run("異常系: 未登録テナントはバケットを共有", func(t *testing.T) {
+		_, err := limiter.Acquire("random-2", ClassCheck)
+		var throttled *ThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected ThrottledError, got %v", err)
+		}
+		if throttled.Tenant != "random-2" || throttled.Bucket != OtherTenant {
+			t.Errorf("Expected tenant random-2 in bucket %s, got %s in %s", OtherTenant, throttled.Tenant, throttled.Bucket)
+		}
+	})
+
+	t.Run("正常系: 登録済みとオーバーライドのテナントは個別のバケット", func(t *testing.T) {
+		for _, tenantID := range []string{"t1", "vip"} {
+			if _, err := limiter.Acquire(tenantID, ClassCheck); err != nil {
+				t.Errorf("Expected %s to be admitted, got %v", tenantID, err)
+			}
+		}
+	})
+}
+
+func TestLimiter_AcquireN(t *testing.T) {
+	limiter, advance := newTestLimiter(Limits{ClassCheck: {RPS: 10, Burst: 10}}, nil)
+
+	if _, err := limiter.AcquireN("t1", ClassCheck, 8); err != nil {
+		t.Fatalf("Expected to be admitted, got %v", err)
+	}
+
+	t.Run("異常系: 残りトークンを超えるコストは拒否", func(t *testing.T) {
+		_, err := limiter.AcquireN("t1", ClassCheck, 5)
+		var throttled *ThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected ThrottledError, got %v", err)
+		}
+		if throttled.RetryAfter != 300*time.Millisecond {
+			t.Errorf("Expected retry after 300ms, got %v", throttled.RetryAfter)
+		}
+	})
+
+	t.Run("正常系: バーストを超えるコストは満杯のバケットで許可", func(t *testing.T) {
+		advance(time.Second)
+		if _, err := limiter.AcquireN("t1", ClassCheck, 1000); err != nil {
+			t.Errorf("Expected to be admitted with a full bucket, got %v", err)
+		}
+		if _, err := limiter.Acquire("t1", ClassCheck); err == nil {
+			t.Error("Expected the bucket to be drained")
+		}
+	})
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	limiter, advance := newTestLimiter(Limits{ClassCheck: {RPS: 1, Burst: 5}, ClassWrite: {MaxInFlight: 1}}, nil)
+
+	release, err := limiter.Acquire("idle", ClassCheck)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+	if _, err := limiter.Acquire("busy", ClassWrite); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	advance(sweepInterval)
+	release, err = limiter.AcquireN("active", ClassCheck, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+
+	if _, ok := limiter.buckets[bucketKey{tenant: "idle", class: ClassCheck}]; ok {
+		t.Error("Expected the refilled idle bucket to be evicted")
+	}
+	if _, ok := limiter.buckets[bucketKey{tenant: "busy", class: ClassWrite}]; !ok {
+		t.Error("Expected the bucket with a request in flight to be kept")
+	}
+
+	advance(sweepInterval)
+	if _, err := limiter.Acquire("idle", ClassCheck); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := limiter.buckets[bucketKey{tenant: "active", class: ClassCheck}]; ok {
+		t.Error("Expected the bucket refilled since its last use to be evicted")
+	}
+}
@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// directoryPageSize is the number of tenants listed per page on refresh
+const directoryPageSize = 1000
+
+// Lister lists registered tenants (implemented by repositories.TenantRepository)
+type Lister interface {
+	List(ctx context.Context, limit int, cursor string) ([]*entities.Tenant, error)
+}
+
+// Directory is a periodically refreshed set of registered tenant IDs. It lets
+// per-request code tell registered tenants from arbitrary client-supplied IDs
+// without a database query.
+type Directory struct {
+	lister   Lister
+	interval time.Duration
+
+	mu  sync.RWMutex
+	ids map[string]struct{}
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewDirectory creates a directory refreshed from lister every interval.
+// It is empty until Refresh or Start is called.
+func NewDirectory(lister Lister, interval time.Duration) *Directory {
+	return &Directory{
+		lister:   lister,
+		interval: interval,
+		ids:      make(map[string]struct{}),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Contains reports whether tenantID was registered at the last refresh
+func (d *Directory) Contains(tenantID string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.ids[tenantID]
+	return ok
+}
+
+// Refresh reloads the tenant IDs. On error the previous set is kept.
+func (d *Directory) Refresh(ctx context.Context) error {
+	ids := make(map[string]struct{})
+	cursor := ""
+	for {
+		tenants, err := d.lister.List(ctx, directoryPageSize, cursor)
+		if err != nil {
+			return err
+		}
+		for _, t := range tenants {
+			ids[t.ID] = struct{}{}
+		}
+		if len(tenants) < directoryPageSize {
+			break
+		}
+		cursor = tenants[len(tenants)-1].ID
+	}
+
+	d.mu.Lock()
+	d.ids = ids
+	d.mu.Unlock()
+	return nil
+}
+
+// Start loads the tenant IDs and refreshes them in the background until Stop
+func (d *Directory) Start() {
+	if err := d.Refresh(context.Background()); err != nil {
+		slog.Warn("Failed to load tenants", "error", err)
+	}
+	if d.interval <= 0 {
+		return
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.Refresh(context.Background()); err != nil {
+					slog.Warn("Failed to refresh tenants, keeping the previous list", "error", err)
+				}
+			case <-d.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing the tenant IDs
+func (d *Directory) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.wg.Wait()
+}
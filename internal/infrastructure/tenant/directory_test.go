@@ -0,0 +1,65 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// fakeLister pages through ids like TenantRepository.List
+type fakeLister struct {
+	ids []string
+	err error
+}
+
+func (l *fakeLister) List(ctx context.Context, limit int, cursor string) ([]*entities.Tenant, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	var tenants []*entities.Tenant
+	for _, id := range l.ids {
+		if id > cursor && len(tenants) < limit {
+			tenants = append(tenants, &entities.Tenant{ID: id, Name: id})
+		}
+	}
+	return tenants, nil
+}
+
+func TestDirectory_Refresh(t *testing.T) {
+	t.Run("正常系: 複数ページのテナントを読み込む", func(t *testing.T) {
+		ids := make([]string, directoryPageSize+1)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("t%05d", i)
+		}
+		directory := NewDirectory(&fakeLister{ids: ids}, 0)
+		if err := directory.Refresh(context.Background()); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		for _, id := range []string{ids[0], ids[len(ids)-1]} {
+			if !directory.Contains(id) {
+				t.Errorf("Expected %s to be contained", id)
+			}
+		}
+		if directory.Contains("unknown") {
+			t.Error("Expected unregistered tenant not to be contained")
+		}
+	})
+
+	t.Run("異常系: 失敗時は前回の一覧を保持", func(t *testing.T) {
+		lister := &fakeLister{ids: []string{"t1"}}
+		directory := NewDirectory(lister, 0)
+		if err := directory.Refresh(context.Background()); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		lister.err = errors.New("database unavailable")
+		if err := directory.Refresh(context.Background()); err == nil {
+			t.Fatal("Expected error")
+		}
+		if !directory.Contains("t1") {
+			t.Error("Expected previous tenants to be kept")
+		}
+	})
+}
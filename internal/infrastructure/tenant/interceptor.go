@@ -0,0 +1,92 @@
+package tenant
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that resolves the
+// tenant of each request. It must run before the auth interceptor, so tenant
+// restrictions are checked against the resolved tenant.
+func UnaryServerInterceptor(resolver *Resolver) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resolver.Resolve(ctx, req)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor equivalent to
+// UnaryServerInterceptor. The tenant is resolved on every received message.
+func StreamServerInterceptor(resolver *Resolver) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &resolvingStream{ServerStream: ss, resolver: resolver})
+	}
+}
+
+// resolvingStream resolves the tenant of each received request
+type resolvingStream struct {
+	grpc.ServerStream
+	resolver *Resolver
+}
+
+func (s *resolvingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.resolver.Resolve(s.Context(), m)
+	return nil
+}
+
+// RequireUnaryServerInterceptor returns a gRPC unary server interceptor that
+// rejects requests without a tenant in strict mode. It must run after the auth
+// interceptor, which binds the tenant of the caller's credential.
+func RequireUnaryServerInterceptor(resolver *Resolver) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := resolver.Require(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequireStreamServerInterceptor returns a gRPC stream server interceptor
+// equivalent to RequireUnaryServerInterceptor, checking every received message.
+func RequireStreamServerInterceptor(resolver *Resolver) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &requiringStream{ServerStream: ss, resolver: resolver})
+	}
+}
+
+// requiringStream checks the tenant of each received request
+type requiringStream struct {
+	grpc.ServerStream
+	resolver *Resolver
+}
+
+func (s *requiringStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.resolver.Require(m)
+}
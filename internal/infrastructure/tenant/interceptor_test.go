@@ -0,0 +1,105 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(NewResolver("", true))
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_Check_FullMethodName}
+
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = req.(*pb.PermissionCheckRequest).TenantId
+		return "ok", nil
+	}
+	if _, err := interceptor(withMetadata("x-tenant-id", "t1"), &pb.PermissionCheckRequest{}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "t1" {
+		t.Errorf("Expected handler to see tenant t1, got %q", got)
+	}
+
+	// Strict mode is enforced later, after auth can bind the credential's tenant
+	got = "unset"
+	if _, err := interceptor(context.Background(), &pb.PermissionCheckRequest{}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "" {
+		t.Errorf("Expected handler to see no tenant, got %q", got)
+	}
+}
+
+func TestRequireUnaryServerInterceptor(t *testing.T) {
+	resolver := NewResolver("", true)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Permission_Check_FullMethodName}
+
+	// Resolve, then bind a tenant as the auth interceptor does for a tenant-bound credential
+	bindTenant := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		req.(*pb.PermissionCheckRequest).TenantId = "from-token"
+		return handler(ctx, req)
+	}
+	chain := func(first grpc.UnaryServerInterceptor, req interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+		return UnaryServerInterceptor(resolver)(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return first(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return RequireUnaryServerInterceptor(resolver)(ctx, req, info, handler)
+			})
+		})
+	}
+
+	called := false
+	_, err := chain(bindTenant, &pb.PermissionCheckRequest{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	})
+	if err != nil || !called {
+		t.Fatalf("expected the credential's tenant to satisfy strict mode, got %v", err)
+	}
+
+	noAuth := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+	_, err = chain(noAuth, &pb.PermissionCheckRequest{}, func(context.Context, interface{}) (interface{}, error) {
+		t.Error("handler must not be called")
+		return nil, nil
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+// fakeServerStream delivers one empty request
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(NewResolver("", false))
+	info := &grpc.StreamServerInfo{FullMethod: pb.Data_Watch_FullMethodName}
+
+	req := &pb.DataWatchRequest{}
+	err := interceptor(nil, &fakeServerStream{ctx: withMetadata("x-tenant-id", "t1")}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return ss.RecvMsg(req)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.TenantId != "t1" {
+		t.Errorf("Expected tenant t1, got %q", req.TenantId)
+	}
+}
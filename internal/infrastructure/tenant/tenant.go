@@ -0,0 +1,146 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MetadataKey is the gRPC metadata key (and HTTP header) carrying the tenant ID
+const MetadataKey = "x-tenant-id"
+
+// DefaultID is the tenant of requests that omit tenant_id
+const DefaultID = "default"
+
+// fieldName is the request field holding the tenant ID
+const fieldName = "tenant_id"
+
+// FromRequest returns the tenant a request is served for: its tenant_id, or
+// DefaultID if it is empty. ok is false for requests without a tenant_id field
+// (such as the Tenancy service), which also get DefaultID.
+func FromRequest(req interface{}) (tenantID string, ok bool) {
+	m, field, ok := tenantField(req)
+	if !ok {
+		return DefaultID, false
+	}
+	if tenantID := m.Get(field).String(); tenantID != "" {
+		return tenantID, true
+	}
+	return DefaultID, true
+}
+
+// Bind sets the tenant_id field of req to tenantID if it is empty, and reports
+// whether it did. Requests with a tenant_id, or without the field, are unchanged.
+func Bind(req interface{}, tenantID string) bool {
+	m, field, ok := tenantField(req)
+	if !ok || m.Get(field).String() != "" {
+		return false
+	}
+	m.Set(field, protoreflect.ValueOfString(tenantID))
+	return true
+}
+
+// Resolver determines the tenant of a request. In precedence order, the tenant is:
+//  1. the request's tenant_id field (set from the path by the HTTP gateway)
+//  2. the x-tenant-id metadata
+//  3. the configured header, forwarded as metadata
+//
+// A request with none of them is left without a tenant, so handlers use the
+// default tenant (or the tenant of the caller's credential, bound by the auth
+// interceptor), unless the resolver is strict.
+type Resolver struct {
+	header string // Additional metadata key; empty disables it
+	strict bool   // Reject requests without a tenant instead of using the default tenant
+}
+
+// NewResolver creates a resolver reading x-tenant-id and header (which may be empty)
+func NewResolver(header string, strict bool) *Resolver {
+	header = strings.ToLower(strings.TrimSpace(header))
+	if header == MetadataKey {
+		header = ""
+	}
+	return &Resolver{header: header, strict: strict}
+}
+
+// Header returns the additional metadata key, or "" if only x-tenant-id is read
+func (r *Resolver) Header() string {
+	return r.header
+}
+
+// Strict reports whether requests without a tenant are rejected
+func (r *Resolver) Strict() bool {
+	return r.strict
+}
+
+// Resolve sets the tenant_id field of req from the incoming metadata when the field
+// is empty. Requests without a tenant_id field (such as the Tenancy service) are
+// left unchanged.
+func (r *Resolver) Resolve(ctx context.Context, req interface{}) {
+	m, field, ok := tenantField(req)
+	if !ok || m.Get(field).String() != "" {
+		return
+	}
+	if tenantID := r.fromMetadata(ctx); tenantID != "" {
+		Bind(req, tenantID)
+	}
+}
+
+// Require rejects a request whose tenant_id field is still empty with
+// InvalidArgument in strict mode. It runs after authentication, so the tenant
+// bound to the caller's credential satisfies it.
+func (r *Resolver) Require(req interface{}) error {
+	if !r.strict {
+		return nil
+	}
+	m, field, ok := tenantField(req)
+	if !ok || m.Get(field).String() != "" {
+		return nil
+	}
+	return status.Errorf(codes.InvalidArgument, "tenant is required: set tenant_id or the %s metadata", r.keys())
+}
+
+// tenantField returns the tenant_id field of req, or ok=false for requests without one
+func tenantField(req interface{}) (protoreflect.Message, protoreflect.FieldDescriptor, bool) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, nil, false
+	}
+	m := msg.ProtoReflect()
+	field := m.Descriptor().Fields().ByName(fieldName)
+	if field == nil {
+		return nil, nil, false
+	}
+	return m, field, true
+}
+
+// fromMetadata returns the first non-empty tenant ID in the incoming metadata
+func (r *Resolver) fromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{MetadataKey, r.header} {
+		if key == "" {
+			continue
+		}
+		if values := md.Get(key); len(values) > 0 {
+			if tenantID := strings.TrimSpace(values[0]); tenantID != "" {
+				return tenantID
+			}
+		}
+	}
+	return ""
+}
+
+// keys describes the metadata keys read, for error messages
+func (r *Resolver) keys() string {
+	if r.header == "" {
+		return MetadataKey
+	}
+	return MetadataKey + " or " + r.header
+}
@@ -0,0 +1,108 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/asakaida/keruberosu/proto/keruberosu/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func withMetadata(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver := NewResolver("X-Org-Id", false)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		bodyTenant string
+		want       string
+	}{
+		{"ボディのtenant_idを優先", withMetadata("x-tenant-id", "t2", "x-org-id", "t3"), "t1", "t1"},
+		{"x-tenant-idメタデータ", withMetadata("x-tenant-id", "t2", "x-org-id", "t3"), "", "t2"},
+		{"設定したヘッダー", withMetadata("x-org-id", "t3"), "", "t3"},
+		{"空のメタデータは無視", withMetadata("x-tenant-id", " ", "x-org-id", "t3"), "", "t3"},
+		{"テナント指定なし", context.Background(), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run("正常系: "+tt.name, func(t *testing.T) {
+			req := &pb.PermissionCheckRequest{TenantId: tt.bodyTenant}
+			resolver.Resolve(tt.ctx, req)
+			if req.TenantId != tt.want {
+				t.Errorf("Expected tenant %q, got %q", tt.want, req.TenantId)
+			}
+		})
+	}
+}
+
+func TestResolver_Require(t *testing.T) {
+	resolver := NewResolver("", true)
+
+	t.Run("異常系: テナント指定なし", func(t *testing.T) {
+		req := &pb.DataWriteRequest{}
+		resolver.Resolve(context.Background(), req)
+		if err := resolver.Require(req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("正常系: メタデータで指定", func(t *testing.T) {
+		req := &pb.DataWriteRequest{}
+		resolver.Resolve(withMetadata("x-tenant-id", "t1"), req)
+		if err := resolver.Require(req); err != nil || req.TenantId != "t1" {
+			t.Errorf("Expected tenant t1, got %q (err: %v)", req.TenantId, err)
+		}
+	})
+
+	t.Run("正常系: tenant_idのないリクエスト", func(t *testing.T) {
+		if err := resolver.Require(&pb.TenantListRequest{}); err != nil {
+			t.Errorf("expected requests without tenant_id to pass, got %v", err)
+		}
+	})
+
+	t.Run("正常系: strictでなければ許可", func(t *testing.T) {
+		if err := NewResolver("", false).Require(&pb.DataWriteRequest{}); err != nil {
+			t.Errorf("expected non-strict resolver to allow requests without a tenant, got %v", err)
+		}
+	})
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    interface{}
+		want   string
+		wantOK bool
+	}{
+		{"tenant_idあり", &pb.PermissionCheckRequest{TenantId: "t1"}, "t1", true},
+		{"tenant_idが空", &pb.PermissionCheckRequest{}, DefaultID, true},
+		{"tenant_idのないリクエスト", &pb.TenantListRequest{}, DefaultID, false},
+		{"protoでないリクエスト", "not a proto", DefaultID, false},
+	}
+	for _, tt := range tests {
+		t.Run("正常系: "+tt.name, func(t *testing.T) {
+			got, ok := FromRequest(tt.req)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	req := &pb.PermissionCheckRequest{}
+	if !Bind(req, "t1") || req.TenantId != "t1" {
+		t.Errorf("Expected empty tenant_id to be bound to t1, got %q", req.TenantId)
+	}
+	if Bind(req, "t2") || req.TenantId != "t1" {
+		t.Errorf("Expected tenant_id t1 to be kept, got %q", req.TenantId)
+	}
+	if Bind(&pb.TenantListRequest{}, "t1") {
+		t.Error("Expected requests without tenant_id to be left unchanged")
+	}
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/stats"
+)
+
+// Exporters supported by Setup
+const (
+	ExporterOTLP   = "otlp"   // OTLP over gRPC, to a collector or tracing backend
+	ExporterStdout = "stdout" // JSON spans on stdout, for local testing
+)
+
+// Config configures the tracer provider
+type Config struct {
+	ServiceName  string  // service.name resource attribute
+	Exporter     string  // ExporterOTLP or ExporterStdout
+	OTLPEndpoint string  // host:port of the OTLP gRPC receiver; empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317
+	OTLPInsecure bool    // Connect to the OTLP receiver without TLS
+	SampleRatio  float64 // Fraction of new traces sampled (0.0 to 1.0); sampled parents are always followed
+
+	stdout io.Writer // Destination of the stdout exporter (os.Stdout if nil)
+}
+
+// Setup installs a global tracer provider exporting spans as configured, and the
+// W3C trace context and baggage propagators. The returned function flushes and
+// stops the provider; call it on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// newExporter creates the span exporter selected by cfg.Exporter
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		var options []otlptracegrpc.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		writer := cfg.stdout
+		if writer == nil {
+			writer = os.Stdout
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout trace exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (expected otlp or stdout)", cfg.Exporter)
+	}
+}
+
+// ServerHandler returns the gRPC stats handler creating a server span for every
+// RPC, continuing the W3C trace context of the incoming metadata. Health checks
+// are not traced, since probes would flood the backend with single-span traces.
+func ServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
+		return !strings.HasPrefix(info.FullMethodName, "/grpc.health.v1.Health/")
+	}))
+}
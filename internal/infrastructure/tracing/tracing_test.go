@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/stats"
+)
+
+func TestSetup_StdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName: "keruberosu-test",
+		Exporter:    ExporterStdout,
+		SampleRatio: 1.0,
+		stdout:      &out,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An incoming W3C trace context becomes the parent of new spans
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	_, span := otel.Tracer("test").Start(ctx, "test-span")
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the propagated trace ID, got %s", got)
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	for _, want := range []string{`"Name":"test-span"`, "keruberosu-test"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %s in the exported spans, got %s", want, out.String())
+		}
+	}
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected error for unknown exporter")
+	}
+}
+
+func TestServerHandler_SkipsHealthChecks(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	handler := ServerHandler()
+	for _, method := range []string{"/grpc.health.v1.Health/Check", "/keruberosu.v1.Permission/Check"} {
+		ctx := handler.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: method})
+		handler.HandleRPC(ctx, &stats.End{})
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "keruberosu.v1.Permission/Check" {
+		t.Errorf("Expected only the Permission/Check span, got %v", spans)
+	}
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"errors"
 
 	"buf.build/go/protovalidate"
 	"google.golang.org/grpc"
@@ -26,9 +27,23 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	) (interface{}, error) {
 		if msg, ok := req.(proto.Message); ok {
 			if err := validator.Validate(msg); err != nil {
-				return nil, status.Errorf(codes.InvalidArgument, "validation error: %v", err)
+				return nil, validationStatus(err)
 			}
 		}
 		return handler(ctx, req)
 	}
 }
+
+// validationStatus converts a protovalidate error to an InvalidArgument status.
+// Constraint violations are attached as buf.validate.Violations details, so clients
+// (and the HTTP gateway) can report which fields failed.
+func validationStatus(err error) error {
+	st := status.Newf(codes.InvalidArgument, "validation error: %v", err)
+	var valErr *protovalidate.ValidationError
+	if errors.As(err, &valErr) {
+		if withDetails, detailErr := st.WithDetails(valErr.ToProto()); detailErr == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
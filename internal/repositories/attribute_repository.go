@@ -7,6 +7,13 @@ import (
 	"github.com/asakaida/keruberosu/internal/entities"
 )
 
+// AttributeFilter represents filter conditions for deleting attributes
+type AttributeFilter struct {
+	EntityType string   // Filter by entity type (required)
+	EntityIDs  []string // Filter by entity IDs (optional)
+	Attributes []string // Filter by attribute names (optional)
+}
+
 // AttributeRepository defines the interface for attribute data access
 type AttributeRepository interface {
 	// Write creates or updates an attribute
@@ -19,6 +26,12 @@ type AttributeRepository interface {
 	// Delete removes a specific attribute from an entity
 	Delete(ctx context.Context, tenantID string, entityType string, entityID string, attrName string) error
 
+	// DeleteByFilter removes all attributes matching the filter (Permify互換)
+	DeleteByFilter(ctx context.Context, tenantID string, filter *AttributeFilter) error
+
+	// DeleteByFilterInTx removes all attributes matching the filter within an existing transaction
+	DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *AttributeFilter) error
+
 	// GetValue retrieves a specific attribute value for an entity
 	GetValue(ctx context.Context, tenantID string, entityType string, entityID string, attrName string) (interface{}, error)
 
@@ -26,5 +39,6 @@ type AttributeRepository interface {
 	WriteInTx(ctx context.Context, tx *sql.Tx, tenantID string, attr *entities.Attribute) error
 
 	// GetSortedEntityIDs returns sorted unique entity IDs from the attributes table with cursor-based pagination.
-	GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, cursor string, limit int) ([]string, error)
+	// If scope is non-empty, only entity IDs contained in scope are returned.
+	GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, scope []string, cursor string, limit int) ([]string, error)
 }
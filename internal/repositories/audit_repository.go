@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// AuditLogFilter represents filter conditions for reading audit logs
+type AuditLogFilter struct {
+	EventType string    // Filter by event type (optional)
+	ActorID   string    // Filter by actor ID (optional)
+	StartTime time.Time // Inclusive lower bound on the event time (optional)
+	EndTime   time.Time // Exclusive upper bound on the event time (optional)
+}
+
+// AuditRepository defines the interface for audit log data access
+type AuditRepository interface {
+	// Write records an audit log
+	Write(ctx context.Context, tenantID string, log *entities.AuditLog) error
+
+	// WriteInTx records an audit log within an existing transaction, so the log
+	// is committed or rolled back together with the mutation it describes
+	WriteInTx(ctx context.Context, tx *sql.Tx, tenantID string, log *entities.AuditLog) error
+
+	// List retrieves audit logs matching the filter, newest first, with cursor-based pagination.
+	// The cursor is the ID of the last log of the previous page.
+	List(ctx context.Context, tenantID string, filter *AuditLogFilter, limit int, cursor string) ([]*entities.AuditLog, error)
+
+	// Count returns the number of audit logs matching the filter
+	Count(ctx context.Context, tenantID string, filter *AuditLogFilter) (int, error)
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// ChangeRepository defines the interface for reading the relation and attribute change log
+type ChangeRepository interface {
+	// ReadChanges returns the change sets of a tenant committed by transactions after
+	// afterTxID, in transaction order, up to limit transactions.
+	// Only transactions older than every in-flight transaction are returned, so no
+	// change set can later appear before the last one returned.
+	// Returns ErrChangesPruned if changes after afterTxID have been pruned.
+	ReadChanges(ctx context.Context, tenantID string, afterTxID int64, limit int) ([]*entities.ChangeSet, error)
+
+	// CurrentTxID returns the oldest transaction ID that may still be in flight;
+	// every change set below it has been committed (or rolled back).
+	CurrentTxID(ctx context.Context) (int64, error)
+
+	// PruneChanges deletes the changes recorded before the given time and
+	// returns the number of deleted rows. Reads after a pruned transaction
+	// fail with ErrChangesPruned from then on.
+	PruneChanges(ctx context.Context, before time.Time) (int64, error)
+}
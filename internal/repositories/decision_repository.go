@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// DecisionRepository defines the interface for storing permission decision logs
+type DecisionRepository interface {
+	// Write records a decision
+	Write(ctx context.Context, tenantID string, decision *entities.Decision) error
+}
@@ -4,3 +4,10 @@ import "errors"
 
 // ErrNotFound is returned when a requested resource is not found
 var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned when creating a resource whose ID is already taken
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrChangesPruned is returned when changes after the requested position have
+// already been removed from the change log by retention
+var ErrChangesPruned = errors.New("changes pruned")
@@ -11,6 +11,7 @@ import (
 	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/infrastructure/database"
 	"github.com/asakaida/keruberosu/internal/repositories"
+	"github.com/lib/pq"
 )
 
 // PostgresAttributeRepository implements AttributeRepository using PostgreSQL
@@ -87,7 +88,7 @@ func (r *PostgresAttributeRepository) Read(ctx context.Context, tenantID string,
 		FROM attributes
 		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
 	`
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, tenantID, entityType, entityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read attributes: %w", err)
@@ -133,6 +134,59 @@ func (r *PostgresAttributeRepository) Delete(ctx context.Context, tenantID strin
 	return nil
 }
 
+// DeleteByFilter removes all attributes matching the filter
+func (r *PostgresAttributeRepository) DeleteByFilter(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error {
+	query, args, err := attributeDeleteQuery(tenantID, filter)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.cluster.Writer().ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete attributes by filter: %w", err)
+	}
+
+	r.cluster.RecordWrite(tenantID)
+	return nil
+}
+
+// DeleteByFilterInTx removes all attributes matching the filter within an existing transaction
+func (r *PostgresAttributeRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.AttributeFilter) error {
+	query, args, err := attributeDeleteQuery(tenantID, filter)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete attributes by filter: %w", err)
+	}
+
+	return nil
+}
+
+// attributeDeleteQuery builds the DELETE statement for an attribute filter.
+// The entity type is required to prevent deleting every attribute of a tenant.
+func attributeDeleteQuery(tenantID string, filter *repositories.AttributeFilter) (string, []interface{}, error) {
+	if filter == nil || filter.EntityType == "" {
+		return "", nil, fmt.Errorf("filter with entity type is required")
+	}
+
+	query := `DELETE FROM attributes WHERE tenant_id = $1 AND entity_type = $2`
+	args := []interface{}{tenantID, filter.EntityType}
+	argIdx := 3
+
+	if len(filter.EntityIDs) > 0 {
+		query += fmt.Sprintf(" AND entity_id = ANY($%d)", argIdx)
+		args = append(args, pq.Array(filter.EntityIDs))
+		argIdx++
+	}
+	if len(filter.Attributes) > 0 {
+		query += fmt.Sprintf(" AND attribute = ANY($%d)", argIdx)
+		args = append(args, pq.Array(filter.Attributes))
+	}
+
+	return query, args, nil
+}
+
 // GetValue retrieves a specific attribute value for an entity
 func (r *PostgresAttributeRepository) GetValue(ctx context.Context, tenantID string, entityType string, entityID string, attrName string) (interface{}, error) {
 	query := `
@@ -140,7 +194,7 @@ func (r *PostgresAttributeRepository) GetValue(ctx context.Context, tenantID str
 		FROM attributes
 		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND attribute = $4
 	`
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	var valueJSON string
 	err := db.QueryRowContext(ctx, query, tenantID, entityType, entityID, attrName).Scan(&valueJSON)
 	if err == sql.ErrNoRows {
@@ -192,11 +246,17 @@ func normalizeJSONValue(v interface{}) interface{} {
 
 // GetSortedEntityIDs returns sorted unique entity IDs from the attributes table with cursor-based pagination.
 func (r *PostgresAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenantID string,
-	entityType string, cursor string, limit int) ([]string, error) {
+	entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	query := `SELECT DISTINCT entity_id FROM attributes WHERE tenant_id = $1 AND entity_type = $2`
 	args := []interface{}{tenantID, entityType}
 	argIdx := 3
 
+	if len(scope) > 0 {
+		query += fmt.Sprintf(" AND entity_id = ANY($%d)", argIdx)
+		args = append(args, pq.Array(scope))
+		argIdx++
+	}
+
 	if cursor != "" {
 		query += fmt.Sprintf(" AND entity_id > $%d", argIdx)
 		args = append(args, cursor)
@@ -207,7 +267,7 @@ func (r *PostgresAttributeRepository) GetSortedEntityIDs(ctx context.Context, te
 	query += fmt.Sprintf(" LIMIT $%d", argIdx)
 	args = append(args, limit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sorted entity IDs from attributes: %w", err)
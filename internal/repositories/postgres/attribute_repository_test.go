@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
 )
 
 func TestAttributeRepository_Write(t *testing.T) {
@@ -309,6 +310,81 @@ func TestAttributeRepository_Delete(t *testing.T) {
 	})
 }
 
+func TestAttributeRepository_DeleteByFilter(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresAttributeRepository(cluster)
+	ctx := context.Background()
+	tenantID := "tenant6"
+
+	attrs := []*entities.Attribute{
+		{EntityType: "document", EntityID: "doc1", Name: "public", Value: true},
+		{EntityType: "document", EntityID: "doc1", Name: "title", Value: "One"},
+		{EntityType: "document", EntityID: "doc2", Name: "public", Value: true},
+		{EntityType: "document", EntityID: "doc3", Name: "public", Value: false},
+		{EntityType: "folder", EntityID: "doc1", Name: "public", Value: true},
+	}
+	for _, attr := range attrs {
+		if err := repo.Write(ctx, tenantID, attr); err != nil {
+			t.Fatalf("Failed to write attribute: %v", err)
+		}
+	}
+
+	t.Run("正常系: エンティティIDと属性名で削除", func(t *testing.T) {
+		err := repo.DeleteByFilter(ctx, tenantID, &repositories.AttributeFilter{
+			EntityType: "document",
+			EntityIDs:  []string{"doc1", "doc2"},
+			Attributes: []string{"public"},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		for _, id := range []string{"doc1", "doc2"} {
+			if _, err := repo.GetValue(ctx, tenantID, "document", id, "public"); err == nil {
+				t.Errorf("Expected document:%s public to be deleted", id)
+			}
+		}
+		// 条件に一致しない属性は残っている
+		if _, err := repo.GetValue(ctx, tenantID, "document", "doc1", "title"); err != nil {
+			t.Errorf("Expected document:doc1 title to remain, got: %v", err)
+		}
+		if _, err := repo.GetValue(ctx, tenantID, "document", "doc3", "public"); err != nil {
+			t.Errorf("Expected document:doc3 public to remain, got: %v", err)
+		}
+		if _, err := repo.GetValue(ctx, tenantID, "folder", "doc1", "public"); err != nil {
+			t.Errorf("Expected folder:doc1 public to remain, got: %v", err)
+		}
+	})
+
+	t.Run("正常系: トランザクション内で削除", func(t *testing.T) {
+		tx, err := cluster.PrimaryDB().BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		err = repo.DeleteByFilterInTx(ctx, tx, tenantID, &repositories.AttributeFilter{EntityType: "folder"})
+		if err != nil {
+			tx.Rollback()
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		// ロールバックすると削除されない
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("Failed to roll back: %v", err)
+		}
+		if _, err := repo.GetValue(ctx, tenantID, "folder", "doc1", "public"); err != nil {
+			t.Errorf("Expected folder:doc1 public to remain after rollback, got: %v", err)
+		}
+	})
+
+	t.Run("異常系: エンティティタイプなし", func(t *testing.T) {
+		err := repo.DeleteByFilter(ctx, tenantID, &repositories.AttributeFilter{Attributes: []string{"public"}})
+		if err == nil {
+			t.Error("Expected error for filter without entity type")
+		}
+	})
+}
+
 func TestAttributeRepository_ComplexTypes(t *testing.T) {
 	cluster := SetupTestDB(t)
 	defer CleanupTestDB(t, cluster)
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// sqlExecutor is satisfied by both database.DBTX and *sql.Tx, so a statement
+// can run either standalone or within a caller's transaction
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// PostgresAuditRepository implements AuditRepository using PostgreSQL
+type PostgresAuditRepository struct {
+	cluster *database.DBCluster
+}
+
+// NewPostgresAuditRepository creates a new PostgreSQL audit repository
+func NewPostgresAuditRepository(cluster *database.DBCluster) repositories.AuditRepository {
+	return &PostgresAuditRepository{cluster: cluster}
+}
+
+// Write records an audit log
+func (r *PostgresAuditRepository) Write(ctx context.Context, tenantID string, log *entities.AuditLog) error {
+	if err := insertAuditLog(ctx, r.cluster.Writer(), tenantID, log); err != nil {
+		return err
+	}
+
+	r.cluster.RecordWrite(tenantID)
+	return nil
+}
+
+// WriteInTx records an audit log within an existing transaction
+func (r *PostgresAuditRepository) WriteInTx(ctx context.Context, tx *sql.Tx, tenantID string, log *entities.AuditLog) error {
+	return insertAuditLog(ctx, tx, tenantID, log)
+}
+
+// insertAuditLog validates and inserts an audit log using the given executor
+func insertAuditLog(ctx context.Context, exec sqlExecutor, tenantID string, log *entities.AuditLog) error {
+	if err := log.Validate(); err != nil {
+		return fmt.Errorf("invalid audit log: %w", err)
+	}
+
+	details := log.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log details: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_logs (
+			tenant_id, event_type, actor_id, actor_type,
+			resource_type, resource_id, action, details, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = exec.ExecContext(ctx, query,
+		tenantID, log.EventType, log.ActorID, log.ActorType,
+		log.ResourceType, log.ResourceID, log.Action, string(detailsJSON), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit logs matching the filter, newest first, with cursor-based pagination
+func (r *PostgresAuditRepository) List(ctx context.Context, tenantID string, filter *repositories.AuditLogFilter, limit int, cursor string) ([]*entities.AuditLog, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, event_type, actor_id, actor_type, resource_type, resource_id, action, details, created_at
+		FROM audit_logs
+		WHERE tenant_id = $1
+	`
+	conditions, args := auditLogFilterConditions(tenantID, filter)
+	query += conditions
+	argIdx := len(args) + 1
+
+	if cursor != "" {
+		cursorID, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+		query += fmt.Sprintf(" AND id < $%d", argIdx)
+		args = append(args, cursorID)
+		argIdx++
+	}
+
+	query += " ORDER BY id DESC"
+	query += fmt.Sprintf(" LIMIT $%d", argIdx)
+	args = append(args, limit)
+
+	db := r.cluster.ReaderFor(ctx, tenantID)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*entities.AuditLog
+	for rows.Next() {
+		log := &entities.AuditLog{}
+		var detailsJSON []byte
+		if err := rows.Scan(&log.ID, &log.EventType, &log.ActorID, &log.ActorType,
+			&log.ResourceType, &log.ResourceID, &log.Action, &detailsJSON, &log.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := json.Unmarshal(detailsJSON, &log.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit log details: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// Count returns the number of audit logs matching the filter
+func (r *PostgresAuditRepository) Count(ctx context.Context, tenantID string, filter *repositories.AuditLogFilter) (int, error) {
+	conditions, args := auditLogFilterConditions(tenantID, filter)
+	query := `SELECT COUNT(*) FROM audit_logs WHERE tenant_id = $1` + conditions
+
+	db := r.cluster.ReaderFor(ctx, tenantID)
+	var count int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+	return count, nil
+}
+
+// auditLogFilterConditions builds the WHERE conditions (after tenant_id = $1) for a filter
+func auditLogFilterConditions(tenantID string, filter *repositories.AuditLogFilter) (string, []interface{}) {
+	args := []interface{}{tenantID}
+	if filter == nil {
+		return "", args
+	}
+
+	var conditions string
+	argIdx := 2
+	if filter.EventType != "" {
+		conditions += fmt.Sprintf(" AND event_type = $%d", argIdx)
+		args = append(args, filter.EventType)
+		argIdx++
+	}
+	if filter.ActorID != "" {
+		conditions += fmt.Sprintf(" AND actor_id = $%d", argIdx)
+		args = append(args, filter.ActorID)
+		argIdx++
+	}
+	if !filter.StartTime.IsZero() {
+		conditions += fmt.Sprintf(" AND created_at >= $%d", argIdx)
+		args = append(args, filter.StartTime)
+		argIdx++
+	}
+	if !filter.EndTime.IsZero() {
+		conditions += fmt.Sprintf(" AND created_at < $%d", argIdx)
+		args = append(args, filter.EndTime)
+	}
+
+	return conditions, args
+}
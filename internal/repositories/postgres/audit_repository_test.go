@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+func TestAuditRepository_WriteAndList(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresAuditRepository(cluster)
+	ctx := context.Background()
+	tenantID := "tenant1"
+
+	logs := []*entities.AuditLog{
+		{EventType: "admin", ActorID: "alice", Action: "create", Details: map[string]interface{}{"count": 1.0}},
+		{EventType: "login", ActorID: "bob", Action: "login"},
+		{EventType: "admin", ActorID: "alice", Action: "delete"},
+		{EventType: "admin", ActorID: "carol", Action: "update"},
+	}
+	for _, log := range logs {
+		if err := repo.Write(ctx, tenantID, log); err != nil {
+			t.Fatalf("Failed to write audit log: %v", err)
+		}
+	}
+	// Logs of other tenants are not returned
+	if err := repo.Write(ctx, "tenant2", &entities.AuditLog{EventType: "admin", Action: "create"}); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	t.Run("正常系: 新しい順に取得", func(t *testing.T) {
+		result, err := repo.List(ctx, tenantID, nil, 10, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(result) != 4 {
+			t.Fatalf("Expected 4 logs, got %d", len(result))
+		}
+		if result[0].Action != "update" || result[3].Action != "create" {
+			t.Errorf("Expected newest first, got %s ... %s", result[0].Action, result[3].Action)
+		}
+		if result[3].Details["count"] != 1.0 {
+			t.Errorf("Expected details count 1, got %v", result[3].Details)
+		}
+	})
+
+	t.Run("正常系: フィルターとカーソル", func(t *testing.T) {
+		filter := &repositories.AuditLogFilter{EventType: "admin", ActorID: "alice"}
+		first, err := repo.List(ctx, tenantID, filter, 1, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(first) != 1 || first[0].Action != "delete" {
+			t.Fatalf("Expected the delete log, got %v", first)
+		}
+
+		second, err := repo.List(ctx, tenantID, filter, 1, strconv.FormatInt(first[0].ID, 10))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(second) != 1 || second[0].Action != "create" {
+			t.Fatalf("Expected the create log, got %v", second)
+		}
+
+		count, err := repo.Count(ctx, tenantID, filter)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("Expected count 2, got %d", count)
+		}
+	})
+
+	t.Run("正常系: 時間範囲", func(t *testing.T) {
+		future := &repositories.AuditLogFilter{StartTime: time.Now().Add(time.Hour)}
+		result, err := repo.List(ctx, tenantID, future, 10, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(result) != 0 {
+			t.Errorf("Expected no logs in the future, got %d", len(result))
+		}
+
+		past := &repositories.AuditLogFilter{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour)}
+		count, err := repo.Count(ctx, tenantID, past)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if count != 4 {
+			t.Errorf("Expected count 4, got %d", count)
+		}
+	})
+
+	t.Run("異常系: 不正なカーソル", func(t *testing.T) {
+		if _, err := repo.List(ctx, tenantID, nil, 10, "abc"); err == nil {
+			t.Error("Expected error for invalid cursor")
+		}
+	})
+}
+
+func TestAuditRepository_WriteInTx(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresAuditRepository(cluster)
+	ctx := context.Background()
+	tenantID := "tenant1"
+	db := cluster.PrimaryDB()
+
+	t.Run("正常系: コミットで記録される", func(t *testing.T) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if err := repo.WriteInTx(ctx, tx, tenantID, &entities.AuditLog{EventType: "data.write", Action: "write"}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+
+		count, err := repo.Count(ctx, tenantID, &repositories.AuditLogFilter{EventType: "data.write"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("Expected count 1, got %d", count)
+		}
+	})
+
+	t.Run("正常系: ロールバックで記録されない", func(t *testing.T) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if err := repo.WriteInTx(ctx, tx, tenantID, &entities.AuditLog{EventType: "data.delete", Action: "delete"}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("Failed to roll back: %v", err)
+		}
+
+		count, err := repo.Count(ctx, tenantID, &repositories.AuditLogFilter{EventType: "data.delete"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected count 0, got %d", count)
+		}
+	})
+}
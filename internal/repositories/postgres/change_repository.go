@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// changePruneBatchSize is the maximum number of change rows deleted per statement,
+// so pruning a large backlog does not hold one long-running transaction
+const changePruneBatchSize = 10000
+
+// PostgresChangeRepository implements ChangeRepository using the changes table,
+// which is populated by triggers on the relations and attributes tables.
+type PostgresChangeRepository struct {
+	cluster *database.DBCluster
+}
+
+// NewPostgresChangeRepository creates a new PostgreSQL change repository
+func NewPostgresChangeRepository(cluster *database.DBCluster) repositories.ChangeRepository {
+	return &PostgresChangeRepository{cluster: cluster}
+}
+
+// ReadChanges returns the change sets of a tenant committed after afterTxID.
+// Transaction IDs are assigned when a transaction starts, not when it commits, so
+// only transactions below the current snapshot's xmin (all finished) are read.
+// Reads go to the primary: transaction IDs are only meaningful there.
+//
+// Because of the xmin bound, a transaction that stays open on the primary
+// (including one that writes nothing, e.g. an idle-in-transaction session or a
+// long report query) holds back xmin and stalls every Watch stream of every
+// tenant until it finishes.
+//
+// The pruned position is checked after the read, so a prune that commits while
+// reading is still detected and reported as repositories.ErrChangesPruned.
+func (r *PostgresChangeRepository) ReadChanges(ctx context.Context, tenantID string, afterTxID int64, limit int) ([]*entities.ChangeSet, error) {
+	query := `
+		WITH txs AS (
+			SELECT DISTINCT txid
+			FROM changes
+			WHERE tenant_id = $1
+				AND txid > $2
+				AND txid < txid_snapshot_xmin(txid_current_snapshot())
+			ORDER BY txid
+			LIMIT $3
+		)
+		SELECT txid, operation, entity_type, entity_id,
+			relation, subject_type, subject_id, subject_relation, attribute, value
+		FROM changes
+		WHERE tenant_id = $1 AND txid IN (SELECT txid FROM txs)
+		ORDER BY txid, id
+	`
+	rows, err := r.cluster.Writer().QueryContext(ctx, query, tenantID, afterTxID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changeSets []*entities.ChangeSet
+	for rows.Next() {
+		var txid int64
+		var operation, entityType, entityID string
+		var relation, subjectType, subjectID, subjectRelation, attribute, value sql.NullString
+		if err := rows.Scan(&txid, &operation, &entityType, &entityID,
+			&relation, &subjectType, &subjectID, &subjectRelation, &attribute, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+
+		change := &entities.Change{Operation: entities.ChangeOperation(operation)}
+		if attribute.Valid {
+			change.Attribute = &entities.Attribute{
+				EntityType: entityType,
+				EntityID:   entityID,
+				Name:       attribute.String,
+			}
+			if value.Valid {
+				var v interface{}
+				dec := json.NewDecoder(strings.NewReader(value.String))
+				dec.UseNumber()
+				if err := dec.Decode(&v); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal attribute value: %w", err)
+				}
+				change.Attribute.Value = normalizeJSONValue(v)
+			}
+		} else {
+			change.Tuple = &entities.RelationTuple{
+				EntityType:      entityType,
+				EntityID:        entityID,
+				Relation:        relation.String,
+				SubjectType:     subjectType.String,
+				SubjectID:       subjectID.String,
+				SubjectRelation: subjectRelation.String,
+			}
+		}
+
+		if n := len(changeSets); n == 0 || changeSets[n-1].TxID != txid {
+			changeSets = append(changeSets, &entities.ChangeSet{TxID: txid})
+		}
+		last := changeSets[len(changeSets)-1]
+		last.Changes = append(last.Changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating changes: %w", err)
+	}
+
+	var prunedTxID int64
+	err = r.cluster.Writer().QueryRowContext(ctx, "SELECT pruned_txid FROM change_log_state").Scan(&prunedTxID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get pruned transaction ID: %w", err)
+	}
+	if afterTxID < prunedTxID {
+		return nil, fmt.Errorf("%w: changes up to transaction %d are no longer retained", repositories.ErrChangesPruned, prunedTxID)
+	}
+
+	return changeSets, nil
+}
+
+// CurrentTxID returns the xmin of the primary's current snapshot
+func (r *PostgresChangeRepository) CurrentTxID(ctx context.Context) (int64, error) {
+	var txid int64
+	err := r.cluster.Writer().QueryRowContext(ctx, "SELECT txid_snapshot_xmin(txid_current_snapshot())").Scan(&txid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current transaction ID: %w", err)
+	}
+	return txid, nil
+}
+
+// PruneChanges deletes the changes recorded before the given time in batches
+// and advances the pruned transaction ID to the newest deleted transaction.
+// All rows of a transaction share its start time, so a transaction is pruned
+// as a whole unless it straddles a batch boundary.
+func (r *PostgresChangeRepository) PruneChanges(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM changes
+			WHERE id IN (SELECT id FROM changes WHERE created_at < $1 LIMIT $2)
+			RETURNING txid
+		), state AS (
+			UPDATE change_log_state
+			SET pruned_txid = GREATEST(pruned_txid, (SELECT MAX(txid) FROM deleted))
+			WHERE EXISTS (SELECT 1 FROM deleted)
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+	var total int64
+	for {
+		var n int64
+		if err := r.cluster.Writer().QueryRowContext(ctx, query, before, changePruneBatchSize).Scan(&n); err != nil {
+			return total, fmt.Errorf("failed to prune changes: %w", err)
+		}
+		total += n
+		if n < changePruneBatchSize {
+			return total, nil
+		}
+	}
+}
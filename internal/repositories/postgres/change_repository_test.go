@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+func TestChangeRepository_ReadChanges(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	relationRepo := NewPostgresRelationRepository(cluster, nil)
+	attributeRepo := NewPostgresAttributeRepository(cluster)
+	changeRepo := NewPostgresChangeRepository(cluster)
+	ctx := context.Background()
+	tenantID := "tenant1"
+
+	start, err := changeRepo.CurrentTxID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get current transaction ID: %v", err)
+	}
+
+	tuple := &entities.RelationTuple{
+		EntityType:  "document",
+		EntityID:    "doc1",
+		Relation:    "viewer",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	}
+	if err := relationRepo.Write(ctx, tenantID, tuple); err != nil {
+		t.Fatalf("Failed to write relation: %v", err)
+	}
+	if err := attributeRepo.Write(ctx, tenantID, &entities.Attribute{
+		EntityType: "document", EntityID: "doc1", Name: "version", Value: 3,
+	}); err != nil {
+		t.Fatalf("Failed to write attribute: %v", err)
+	}
+	if err := relationRepo.Delete(ctx, tenantID, tuple); err != nil {
+		t.Fatalf("Failed to delete relation: %v", err)
+	}
+	// Changes of other tenants are not returned
+	if err := relationRepo.Write(ctx, "tenant2", tuple); err != nil {
+		t.Fatalf("Failed to write relation: %v", err)
+	}
+
+	t.Run("正常系: トランザクション順に変更を取得", func(t *testing.T) {
+		changeSets, err := changeRepo.ReadChanges(ctx, tenantID, start-1, 100)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(changeSets) != 3 {
+			t.Fatalf("Expected 3 change sets, got %d", len(changeSets))
+		}
+
+		write := changeSets[0].Changes[0]
+		if write.Operation != entities.ChangeOperationWrite || write.Tuple == nil || write.Tuple.SubjectID != "alice" {
+			t.Errorf("Expected relation write, got %+v", write)
+		}
+		attr := changeSets[1].Changes[0]
+		if attr.Attribute == nil || attr.Attribute.Value != int64(3) {
+			t.Errorf("Expected attribute write with value 3, got %+v", attr.Attribute)
+		}
+		del := changeSets[2].Changes[0]
+		if del.Operation != entities.ChangeOperationDelete || del.Tuple == nil {
+			t.Errorf("Expected relation delete, got %+v", del)
+		}
+
+		for i := 1; i < len(changeSets); i++ {
+			if changeSets[i].TxID <= changeSets[i-1].TxID {
+				t.Errorf("Expected increasing transaction IDs, got %d after %d", changeSets[i].TxID, changeSets[i-1].TxID)
+			}
+		}
+	})
+
+	t.Run("正常系: 指定トランザクション以降のみ取得", func(t *testing.T) {
+		all, err := changeRepo.ReadChanges(ctx, tenantID, start-1, 100)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		after, err := changeRepo.ReadChanges(ctx, tenantID, all[0].TxID, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(after) != 1 || after[0].TxID != all[1].TxID {
+			t.Errorf("Expected only change set %d, got %v", all[1].TxID, after)
+		}
+	})
+}
+
+func TestChangeRepository_PruneChanges(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	relationRepo := NewPostgresRelationRepository(cluster, nil)
+	changeRepo := NewPostgresChangeRepository(cluster)
+	ctx := context.Background()
+	tenantID := "tenant1"
+
+	start, err := changeRepo.CurrentTxID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get current transaction ID: %v", err)
+	}
+	for _, id := range []string{"doc1", "doc2"} {
+		if err := relationRepo.Write(ctx, tenantID, &entities.RelationTuple{
+			EntityType: "document", EntityID: id, Relation: "viewer", SubjectType: "user", SubjectID: "alice",
+		}); err != nil {
+			t.Fatalf("Failed to write relation: %v", err)
+		}
+	}
+	changeSets, err := changeRepo.ReadChanges(ctx, tenantID, start-1, 100)
+	if err != nil || len(changeSets) != 2 {
+		t.Fatalf("Expected 2 change sets, got %v (err: %v)", changeSets, err)
+	}
+
+	t.Run("正常系: 保持期間内の変更は削除しない", func(t *testing.T) {
+		deleted, err := changeRepo.PruneChanges(ctx, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if deleted != 0 {
+			t.Errorf("Expected 0 deleted changes, got %d", deleted)
+		}
+	})
+
+	t.Run("異常系: 削除済みの位置からの読み取りはエラー", func(t *testing.T) {
+		deleted, err := changeRepo.PruneChanges(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if deleted != 2 {
+			t.Errorf("Expected 2 deleted changes, got %d", deleted)
+		}
+
+		_, err = changeRepo.ReadChanges(ctx, tenantID, changeSets[0].TxID, 100)
+		if !errors.Is(err, repositories.ErrChangesPruned) {
+			t.Errorf("Expected ErrChangesPruned, got: %v", err)
+		}
+	})
+
+	t.Run("正常系: 削除済みの最新トランザクション以降は読み取れる", func(t *testing.T) {
+		changes, err := changeRepo.ReadChanges(ctx, tenantID, changeSets[1].TxID, 100)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("Expected no change sets, got %v", changes)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// PostgresDecisionRepository implements DecisionRepository using PostgreSQL
+type PostgresDecisionRepository struct {
+	cluster *database.DBCluster
+}
+
+// NewPostgresDecisionRepository creates a new PostgreSQL decision repository
+func NewPostgresDecisionRepository(cluster *database.DBCluster) repositories.DecisionRepository {
+	return &PostgresDecisionRepository{cluster: cluster}
+}
+
+// Write records a decision.
+// Unlike other writes it does not call RecordWrite: decision logs are never read
+// back by the service, so they must not pin the tenant's reads to the primary.
+func (r *PostgresDecisionRepository) Write(ctx context.Context, tenantID string, decision *entities.Decision) error {
+	resultIDs := decision.ResultIDs
+	if resultIDs == nil {
+		resultIDs = []string{}
+	}
+	resultIDsJSON, err := json.Marshal(resultIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision result IDs: %w", err)
+	}
+
+	createdAt := decision.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO decision_logs (
+			tenant_id, method, entity_type, entity_id, permission,
+			subject_type, subject_id, subject_relation, result, result_ids, result_count,
+			schema_version, snap_token, cache_hit, latency_us, error, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	_, err = r.cluster.Writer().ExecContext(ctx, query,
+		tenantID, decision.Method, decision.EntityType, decision.EntityID, decision.Permission,
+		decision.SubjectType, decision.SubjectID, decision.SubjectRelation, string(decision.Result), string(resultIDsJSON), decision.ResultCount,
+		decision.SchemaVersion, decision.SnapToken, decision.CacheHit, decision.Latency.Microseconds(), decision.Error, createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write decision log: %w", err)
+	}
+
+	return nil
+}
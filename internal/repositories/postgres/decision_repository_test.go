@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+func TestDecisionRepository_Write(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresDecisionRepository(cluster)
+	ctx := context.Background()
+
+	decision := &entities.Decision{
+		Method:      "LookupEntity",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+		Result:      entities.DecisionAllowed,
+		ResultIDs:   []string{"doc1", "doc2"},
+		ResultCount: 5,
+		CacheHit:    false,
+		Latency:     2 * time.Millisecond,
+	}
+	if err := repo.Write(ctx, "tenant1", decision); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var method, result, resultIDs string
+	var resultCount int
+	var latencyMicros int64
+	err := cluster.PrimaryDB().QueryRowContext(ctx,
+		`SELECT method, result, result_ids::text, result_count, latency_us FROM decision_logs WHERE tenant_id = $1`, "tenant1",
+	).Scan(&method, &result, &resultIDs, &resultCount, &latencyMicros)
+	if err != nil {
+		t.Fatalf("Failed to read decision log: %v", err)
+	}
+	if method != "LookupEntity" || result != "allowed" || resultIDs != `["doc1", "doc2"]` || resultCount != 5 || latencyMicros != 2000 {
+		t.Errorf("Unexpected decision log: %s %s %s %d %d", method, result, resultIDs, resultCount, latencyMicros)
+	}
+}
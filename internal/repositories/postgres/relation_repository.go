@@ -166,7 +166,7 @@ func (r *PostgresRelationRepository) Read(ctx context.Context, tenantID string,
 		}
 	}
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read relations: %w", err)
@@ -199,7 +199,7 @@ func (r *PostgresRelationRepository) Exists(ctx context.Context, tenantID string
 				AND COALESCE(subject_relation, '') = $7
 		)
 	`
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	var exists bool
 	err := db.QueryRowContext(ctx, query,
 		tenantID, tuple.EntityType, tuple.EntityID, tuple.Relation,
@@ -227,7 +227,7 @@ func (r *PostgresRelationRepository) ExistsWithSubjectRelation(ctx context.Conte
 				AND subject_relation = $7
 		)
 	`
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	var exists bool
 	err := db.QueryRowContext(ctx, query,
 		tenantID, entityType, entityID, relation, subjectType, subjectID, subjectRelation,
@@ -251,7 +251,7 @@ func (r *PostgresRelationRepository) FindByEntityWithRelation(ctx context.Contex
 		query += " LIMIT $5"
 		args = append(args, limit)
 	}
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find relations by entity with relation: %w", err)
@@ -278,7 +278,7 @@ func (r *PostgresRelationRepository) LookupAncestorsViaRelation(ctx context.Cont
 
 	query += " ORDER BY c.depth"
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup ancestors via relation: %w", err)
@@ -329,7 +329,7 @@ func (r *PostgresRelationRepository) FindHierarchicalWithSubject(ctx context.Con
 			WHERE subject_type = $6 AND subject_id = $7
 		)
 	`
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	var exists bool
 	err := db.QueryRowContext(ctx, query,
 		tenantID, entityType, entityID, relation, maxDepth,
@@ -584,6 +584,25 @@ func (r *PostgresRelationRepository) DeleteByFilter(ctx context.Context, tenantI
 	}
 	defer tx.Rollback()
 
+	if err := r.DeleteByFilterInTx(ctx, tx, tenantID, filter); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.cluster.RecordWrite(tenantID)
+	return nil
+}
+
+// DeleteByFilterInTx removes relation tuples matching the filter within an existing
+// transaction and updates the closure table
+func (r *PostgresRelationRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.RelationFilter) error {
+	if filter == nil {
+		return fmt.Errorf("filter is required")
+	}
+
 	// First, SELECT tuples that will be deleted (for closure table cleanup)
 	selectQuery := `SELECT entity_type, entity_id, relation, subject_type, subject_id, COALESCE(subject_relation, '') FROM relations WHERE tenant_id = $1`
 	deleteQuery := `DELETE FROM relations WHERE tenant_id = $1`
@@ -670,11 +689,6 @@ func (r *PostgresRelationRepository) DeleteByFilter(ctx context.Context, tenantI
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	r.cluster.RecordWrite(tenantID)
 	return nil
 }
 
@@ -743,7 +757,7 @@ func (r *PostgresRelationRepository) ReadByFilter(ctx context.Context, tenantID
 	query += fmt.Sprintf(" LIMIT $%d", argIdx)
 	args = append(args, pageSize+1)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read relations by filter: %w", err)
@@ -914,7 +928,7 @@ func (r *PostgresRelationRepository) LookupAncestors(
 
 	query += " ORDER BY depth"
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup ancestors: %w", err)
@@ -1112,11 +1126,17 @@ func (r *PostgresRelationRepository) updateClosureOnDelete(
 
 // GetSortedEntityIDs returns sorted unique entity IDs with cursor-based pagination.
 func (r *PostgresRelationRepository) GetSortedEntityIDs(ctx context.Context, tenantID string,
-	entityType string, cursor string, limit int) ([]string, error) {
+	entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	query := `SELECT DISTINCT entity_id FROM relations WHERE tenant_id = $1 AND entity_type = $2`
 	args := []interface{}{tenantID, entityType}
 	argIdx := 3
 
+	if len(scope) > 0 {
+		query += fmt.Sprintf(" AND entity_id = ANY($%d)", argIdx)
+		args = append(args, pq.Array(scope))
+		argIdx++
+	}
+
 	if cursor != "" {
 		query += fmt.Sprintf(" AND entity_id > $%d", argIdx)
 		args = append(args, cursor)
@@ -1127,7 +1147,7 @@ func (r *PostgresRelationRepository) GetSortedEntityIDs(ctx context.Context, ten
 	query += fmt.Sprintf(" LIMIT $%d", argIdx)
 	args = append(args, limit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sorted entity IDs: %w", err)
@@ -1162,7 +1182,7 @@ func (r *PostgresRelationRepository) GetSortedSubjectIDs(ctx context.Context, te
 	query += fmt.Sprintf(" LIMIT $%d", argIdx)
 	args = append(args, limit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sorted subject IDs: %w", err)
@@ -1182,8 +1202,10 @@ func (r *PostgresRelationRepository) GetSortedSubjectIDs(ctx context.Context, te
 
 // LookupAccessibleEntitiesComplex finds entity IDs that a subject can access
 // via direct relations, computed usersets, or hierarchical relations using closure table.
+// A non-empty scope is applied inside every sub-query so that only the scoped
+// entities are joined and walked, rather than filtering the combined result.
 func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string,
-	entityType string, relations []string, parentRelations []string,
+	entityType string, scope []string, relations []string, parentRelations []string,
 	subjectType string, subjectID string,
 	maxDepth int, cursor string, limit int) ([]string, error) {
 
@@ -1204,6 +1226,15 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 	pSubjectType := addArg(subjectType)
 	pSubjectID := addArg(subjectID)
 
+	// scopeFilter returns an "AND <column> = ANY(scope)" clause, or "" when unscoped
+	scopeFilter := func(string) string { return "" }
+	if len(scope) > 0 {
+		pScope := addArg(pq.Array(scope))
+		scopeFilter = func(column string) string {
+			return fmt.Sprintf("AND %s = ANY(%s)", column, pScope)
+		}
+	}
+
 	if len(relations) > 0 {
 		pRelations := addArg(pq.Array(relations))
 
@@ -1215,7 +1246,8 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 			  AND r.relation = ANY(%s)
 			  AND r.subject_type = %s AND r.subject_id = %s
 			  AND COALESCE(r.subject_relation, '') = ''
-		`, pTenantID, pEntityType, pRelations, pSubjectType, pSubjectID))
+			  %s
+		`, pTenantID, pEntityType, pRelations, pSubjectType, pSubjectID, scopeFilter("r.entity_id")))
 
 		// Sub-query 2: Computed usersets (recursive CTE for nested expansion)
 		// Handles chains like: entity#rel@team#member -> team#member@group#member -> group#member@user
@@ -1251,7 +1283,9 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 			WHERE outer_r.tenant_id = %s AND outer_r.entity_type = %s
 			  AND outer_r.relation = ANY(%s)
 			  AND outer_r.subject_relation IS NOT NULL AND outer_r.subject_relation != ''
-		`, pTenantID, pUsersetDepth, pTenantID, pSubjectType, pSubjectID, pTenantID, pEntityType, pRelations))
+			  %s
+		`, pTenantID, pUsersetDepth, pTenantID, pSubjectType, pSubjectID, pTenantID, pEntityType, pRelations,
+			scopeFilter("outer_r.entity_id")))
 	}
 
 	if len(parentRelations) > 0 {
@@ -1299,6 +1333,7 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 				    WHERE tenant_id = %s AND entity_type = %s
 				      AND relation = ANY(%s)
 				      AND COALESCE(subject_relation, '') = ''
+				      %s
 				    UNION ALL
 				    SELECT hw.descendant_id, r.subject_type, r.subject_id, hw.depth + 1
 				    FROM hier_walk hw
@@ -1319,7 +1354,7 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 				  AND r.relation = ANY(%s)
 				  AND r.subject_type = %s AND r.subject_id = %s
 				  AND COALESCE(r.subject_relation, '') = ''
-			`, pTenantID, pEntityType, pHierRelations,
+			`, pTenantID, pEntityType, pHierRelations, scopeFilter("entity_id"),
 				pTenantID, pHierRelations, pMaxDepth,
 				pTenantID, pTargetRelations, pSubjectType, pSubjectID))
 
@@ -1334,6 +1369,7 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 				    WHERE tenant_id = %s AND entity_type = %s
 				      AND relation = ANY(%s)
 				      AND COALESCE(subject_relation, '') = ''
+				      %s
 				    UNION ALL
 				    SELECT hw.descendant_id, r.subject_type, r.subject_id, hw.depth + 1
 				    FROM hier_walk hw
@@ -1378,7 +1414,7 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 				    AND COALESCE(leaf.subject_relation, '') = ''
 				  LIMIT 1
 				) userset_match ON true
-			`, pTenantID, pEntityType, pHierRelations,
+			`, pTenantID, pEntityType, pHierRelations, scopeFilter("entity_id"),
 				pTenantID, pHierRelations, pMaxDepth,
 				pTenantID, pTargetRelations,
 				pTenantID, pHierUsersetDepth,
@@ -1403,7 +1439,7 @@ func (r *PostgresRelationRepository) LookupAccessibleEntitiesComplex(ctx context
 	pLimit := addArg(limit)
 	query += fmt.Sprintf(` LIMIT %s`, pLimit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup accessible entities: %w", err)
@@ -1632,7 +1668,7 @@ func (r *PostgresRelationRepository) LookupAccessibleSubjectsComplex(ctx context
 	pLimit := addArg(limit)
 	query += fmt.Sprintf(` LIMIT %s`, pLimit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup accessible subjects: %w", err)
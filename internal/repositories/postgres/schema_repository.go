@@ -31,6 +31,21 @@ func NewPostgresSchemaRepository(cluster *database.DBCluster) repositories.Schem
 
 // Create creates a new schema version for a tenant and returns the version ID
 func (r *PostgresSchemaRepository) Create(ctx context.Context, tenantID string, schemaDSL string) (string, error) {
+	version, err := insertSchema(ctx, r.cluster.Writer(), tenantID, schemaDSL)
+	if err != nil {
+		return "", err
+	}
+	r.cluster.RecordWrite(tenantID)
+	return version, nil
+}
+
+// CreateInTx creates a new schema version within an existing transaction and returns the version ID
+func (r *PostgresSchemaRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	return insertSchema(ctx, tx, tenantID, schemaDSL)
+}
+
+// insertSchema inserts a new schema version with a fresh ULID using the given executor
+func insertSchema(ctx context.Context, exec sqlExecutor, tenantID string, schemaDSL string) (string, error) {
 	ulidEntropyMu.Lock()
 	id, err := ulid.New(ulid.Timestamp(time.Now()), ulidEntropy)
 	ulidEntropyMu.Unlock()
@@ -44,11 +59,10 @@ func (r *PostgresSchemaRepository) Create(ctx context.Context, tenantID string,
 		VALUES ($1, $2, $3, $4, $5)
 	`
 	now := time.Now()
-	_, err = r.cluster.Writer().ExecContext(ctx, query, tenantID, version, schemaDSL, now, now)
+	_, err = exec.ExecContext(ctx, query, tenantID, version, schemaDSL, now, now)
 	if err != nil {
 		return "", fmt.Errorf("failed to create schema: %w", err)
 	}
-	r.cluster.RecordWrite(tenantID)
 	return version, nil
 }
 
@@ -64,7 +78,7 @@ func (r *PostgresSchemaRepository) GetLatestVersion(ctx context.Context, tenantI
 	var version, schemaDSL string
 	var createdAt, updatedAt time.Time
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	err := db.QueryRowContext(ctx, query, tenantID).Scan(&version, &schemaDSL, &createdAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("schema not found for tenant %s: %w", tenantID, repositories.ErrNotFound)
@@ -92,7 +106,7 @@ func (r *PostgresSchemaRepository) GetByVersion(ctx context.Context, tenantID st
 	var versionOut, schemaDSL string
 	var createdAt, updatedAt time.Time
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	err := db.QueryRowContext(ctx, query, tenantID, version).Scan(&versionOut, &schemaDSL, &createdAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("schema version %s not found for tenant %s: %w", version, tenantID, repositories.ErrNotFound)
@@ -130,7 +144,7 @@ func (r *PostgresSchemaRepository) ListVersions(ctx context.Context, tenantID st
 	query += fmt.Sprintf(" LIMIT $%d", argIdx)
 	args = append(args, limit)
 
-	db := r.cluster.ReaderFor(tenantID)
+	db := r.cluster.ReaderFor(ctx, tenantID)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list schema versions: %w", err)
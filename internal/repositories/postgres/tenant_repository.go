@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/database"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// tenantDataTables are the tables holding tenant data, in deletion order.
+// Deleting relations and attributes fires triggers that insert into changes and
+// transactions, so those two are deleted last.
+var tenantDataTables = []string{
+	"entity_closure",
+	"relations",
+	"attributes",
+	"schemas",
+	"changes",
+	"transactions",
+}
+
+// PostgresTenantRepository implements TenantRepository using PostgreSQL
+type PostgresTenantRepository struct {
+	cluster *database.DBCluster
+}
+
+// NewPostgresTenantRepository creates a new PostgreSQL tenant repository
+func NewPostgresTenantRepository(cluster *database.DBCluster) repositories.TenantRepository {
+	return &PostgresTenantRepository{cluster: cluster}
+}
+
+// queryRower is satisfied by both database.DBTX and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Create registers a tenant
+func (r *PostgresTenantRepository) Create(ctx context.Context, tenant *entities.Tenant) error {
+	if err := insertTenant(ctx, r.cluster.Writer(), tenant); err != nil {
+		return err
+	}
+	r.cluster.RecordWrite(tenant.ID)
+	return nil
+}
+
+// CreateInTx registers a tenant within an existing transaction
+func (r *PostgresTenantRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenant *entities.Tenant) error {
+	return insertTenant(ctx, tx, tenant)
+}
+
+// insertTenant validates and inserts a tenant, setting its CreatedAt
+func insertTenant(ctx context.Context, exec queryRower, tenant *entities.Tenant) error {
+	if err := tenant.Validate(); err != nil {
+		return fmt.Errorf("invalid tenant: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenants (id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO NOTHING
+		RETURNING created_at
+	`
+	err := exec.QueryRowContext(ctx, query, tenant.ID, tenant.Name).Scan(&tenant.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("tenant %s: %w", tenant.ID, repositories.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether a tenant is registered. It reads from the primary, so a
+// tenant is visible to writes right after it is created.
+func (r *PostgresTenantRepository) Exists(ctx context.Context, tenantID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM tenants WHERE id = $1)`
+	if err := r.cluster.Writer().QueryRowContext(ctx, query, tenantID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check tenant: %w", err)
+	}
+	return exists, nil
+}
+
+// LockInTx takes a share lock on the tenant row, which conflicts with the row lock
+// of deleteTenant. A write in tx therefore either commits before a concurrent
+// delete removes the tenant's data, or sees the tenant as deleted.
+func (r *PostgresTenantRepository) LockInTx(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	var one int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM tenants WHERE id = $1 FOR SHARE`, tenantID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("tenant %s: %w", tenantID, repositories.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock tenant: %w", err)
+	}
+	return nil
+}
+
+// List retrieves tenants ordered by ID, with cursor-based pagination.
+// Tenants are few and change rarely, so they are always read from the primary.
+func (r *PostgresTenantRepository) List(ctx context.Context, limit int, cursor string) ([]*entities.Tenant, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, name, created_at
+		FROM tenants
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	rows, err := r.cluster.Writer().QueryContext(ctx, query, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []*entities.Tenant
+	for rows.Next() {
+		tenant := &entities.Tenant{}
+		if err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// Delete removes a tenant and all of its data in one transaction
+func (r *PostgresTenantRepository) Delete(ctx context.Context, tenantID string) error {
+	tx, err := r.cluster.PrimaryDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteTenant(ctx, tx, tenantID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.cluster.RecordWrite(tenantID)
+	return nil
+}
+
+// DeleteInTx removes a tenant and all of its data within an existing transaction
+func (r *PostgresTenantRepository) DeleteInTx(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	return deleteTenant(ctx, tx, tenantID)
+}
+
+// deleteTenant deletes the tenant row first, which locks it against concurrent
+// deletes, then the tenant's data from every table
+func deleteTenant(ctx context.Context, tx *sql.Tx, tenantID string) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("tenant %s: %w", tenantID, repositories.ErrNotFound)
+	}
+
+	for _, table := range tenantDataTables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE tenant_id = $1", table), tenantID); err != nil {
+			return fmt.Errorf("failed to delete tenant data from %s: %w", table, err)
+		}
+	}
+	return nil
+}
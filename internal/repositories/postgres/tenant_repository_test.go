@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+func TestTenantRepository_CreateAndList(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresTenantRepository(cluster)
+	ctx := context.Background()
+
+	for _, id := range []string{"tenant-b", "tenant-a", "tenant-c"} {
+		tenant := &entities.Tenant{ID: id, Name: "Tenant " + id}
+		if err := repo.Create(ctx, tenant); err != nil {
+			t.Fatalf("Failed to create tenant: %v", err)
+		}
+		if tenant.CreatedAt.IsZero() {
+			t.Error("expected CreatedAt to be set")
+		}
+	}
+
+	t.Run("異常系: 重複したID", func(t *testing.T) {
+		err := repo.Create(ctx, &entities.Tenant{ID: "tenant-a", Name: "duplicate"})
+		if !errors.Is(err, repositories.ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("正常系: 存在確認", func(t *testing.T) {
+		exists, err := repo.Exists(ctx, "tenant-a")
+		if err != nil || !exists {
+			t.Errorf("Expected tenant-a to exist, got %v (err: %v)", exists, err)
+		}
+		exists, err = repo.Exists(ctx, "unknown")
+		if err != nil || exists {
+			t.Errorf("Expected unknown not to exist, got %v (err: %v)", exists, err)
+		}
+	})
+
+	t.Run("正常系: ID順にカーソルで取得", func(t *testing.T) {
+		first, err := repo.List(ctx, 2, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		// The default tenant is registered by the migration
+		if len(first) != 2 || first[0].ID != "default" || first[1].ID != "tenant-a" {
+			t.Fatalf("Expected default and tenant-a, got %v", first)
+		}
+		rest, err := repo.List(ctx, 10, first[1].ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(rest) != 2 || rest[0].ID != "tenant-b" || rest[1].ID != "tenant-c" {
+			t.Errorf("Expected tenant-b and tenant-c, got %v", rest)
+		}
+	})
+}
+
+func TestTenantRepository_DeleteCascades(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresTenantRepository(cluster)
+	schemaRepo := NewPostgresSchemaRepository(cluster)
+	relationRepo := NewPostgresRelationRepository(cluster, nil)
+	attributeRepo := NewPostgresAttributeRepository(cluster)
+	ctx := context.Background()
+
+	for _, id := range []string{"tenant1", "tenant2"} {
+		if err := repo.Create(ctx, &entities.Tenant{ID: id, Name: id}); err != nil {
+			t.Fatalf("Failed to create tenant: %v", err)
+		}
+		if _, err := schemaRepo.Create(ctx, id, "entity user {}"); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+		tuple := &entities.RelationTuple{EntityType: "folder", EntityID: "f1", Relation: "parent", SubjectType: "folder", SubjectID: "root"}
+		if err := relationRepo.Write(ctx, id, tuple); err != nil {
+			t.Fatalf("Failed to write relation: %v", err)
+		}
+		attr := &entities.Attribute{EntityType: "folder", EntityID: "f1", Name: "public", Value: true}
+		if err := attributeRepo.Write(ctx, id, attr); err != nil {
+			t.Fatalf("Failed to write attribute: %v", err)
+		}
+	}
+
+	if err := repo.Delete(ctx, "tenant1"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	db := cluster.PrimaryDB()
+	for _, table := range append([]string{"tenants"}, tenantDataTables...) {
+		column := "tenant_id"
+		if table == "tenants" {
+			column = "id"
+		}
+		var deleted, kept int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE " + column + " = 'tenant1'").Scan(&deleted); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE " + column + " = 'tenant2'").Scan(&kept); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if deleted != 0 {
+			t.Errorf("Expected no %s rows for tenant1, got %d", table, deleted)
+		}
+		if kept == 0 {
+			t.Errorf("Expected %s rows for tenant2 to be kept", table)
+		}
+	}
+
+	t.Run("異常系: 存在しないテナント", func(t *testing.T) {
+		err := repo.Delete(ctx, "tenant1")
+		if !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTenantRepository_LockInTx(t *testing.T) {
+	cluster := SetupTestDB(t)
+	defer CleanupTestDB(t, cluster)
+
+	repo := NewPostgresTenantRepository(cluster)
+	ctx := context.Background()
+	if err := repo.Create(ctx, &entities.Tenant{ID: "tenant1", Name: "Tenant 1"}); err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+
+	tx, err := cluster.PrimaryDB().BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	t.Run("異常系: 未登録のテナント", func(t *testing.T) {
+		if err := repo.LockInTx(ctx, tx, "unknown"); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("正常系: ロック中のテナントは削除できない", func(t *testing.T) {
+		if err := repo.LockInTx(ctx, tx, "tenant1"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		deleteTx, err := cluster.PrimaryDB().BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		defer deleteTx.Rollback()
+		if _, err := deleteTx.ExecContext(ctx, "SET LOCAL lock_timeout = '100ms'"); err != nil {
+			t.Fatalf("Failed to set lock timeout: %v", err)
+		}
+		if err := repo.DeleteInTx(ctx, deleteTx, "tenant1"); err == nil {
+			t.Error("Expected the delete to wait for the lock and time out")
+		}
+	})
+}
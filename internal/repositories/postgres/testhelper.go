@@ -46,13 +46,20 @@ func CleanupTestDB(t *testing.T, cluster *database.DBCluster) {
 	db := cluster.PrimaryDB()
 
 	// Clean up all tables
-	tables := []string{"entity_closure", "attributes", "relations", "schemas"}
+	tables := []string{"entity_closure", "attributes", "relations", "schemas", "changes", "audit_logs", "decision_logs"}
 	for _, table := range tables {
 		_, err := db.Exec(fmt.Sprintf("DELETE FROM %s", table))
 		if err != nil {
 			t.Logf("Warning: Failed to clean up table %s: %v", table, err)
 		}
 	}
+	// Keep the default tenant registered by the migration
+	if _, err := db.Exec("DELETE FROM tenants WHERE id <> 'default'"); err != nil {
+		t.Logf("Warning: Failed to clean up table tenants: %v", err)
+	}
+	if _, err := db.Exec("UPDATE change_log_state SET pruned_txid = 0"); err != nil {
+		t.Logf("Warning: Failed to reset change_log_state: %v", err)
+	}
 
 	if err := db.Close(); err != nil {
 		t.Logf("Warning: Failed to close database: %v", err)
@@ -65,13 +72,20 @@ func CleanupTestDBWithRawDB(t *testing.T, db *sql.DB) {
 	t.Helper()
 
 	// Clean up all tables
-	tables := []string{"entity_closure", "attributes", "relations", "schemas"}
+	tables := []string{"entity_closure", "attributes", "relations", "schemas", "changes", "audit_logs", "decision_logs"}
 	for _, table := range tables {
 		_, err := db.Exec(fmt.Sprintf("DELETE FROM %s", table))
 		if err != nil {
 			t.Logf("Warning: Failed to clean up table %s: %v", table, err)
 		}
 	}
+	// Keep the default tenant registered by the migration
+	if _, err := db.Exec("DELETE FROM tenants WHERE id <> 'default'"); err != nil {
+		t.Logf("Warning: Failed to clean up table tenants: %v", err)
+	}
+	if _, err := db.Exec("UPDATE change_log_state SET pruned_txid = 0"); err != nil {
+		t.Logf("Warning: Failed to reset change_log_state: %v", err)
+	}
 
 	if err := db.Close(); err != nil {
 		t.Logf("Warning: Failed to close database: %v", err)
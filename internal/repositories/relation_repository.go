@@ -45,6 +45,9 @@ type RelationRepository interface {
 	// DeleteByFilter removes relation tuples matching the filter (Permify互換)
 	DeleteByFilter(ctx context.Context, tenantID string, filter *RelationFilter) error
 
+	// DeleteByFilterInTx removes relation tuples matching the filter within an existing transaction
+	DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *RelationFilter) error
+
 	// ReadByFilter retrieves relation tuples matching filter with pagination (Permify互換)
 	ReadByFilter(ctx context.Context, tenantID string, filter *RelationFilter, pageSize int, pageToken string) ([]*entities.RelationTuple, string, error)
 
@@ -72,9 +75,10 @@ type RelationRepository interface {
 	// RebuildClosure rebuilds the closure table for a tenant from scratch
 	RebuildClosure(ctx context.Context, tenantID string) error
 
-	// GetSortedEntityIDs returns sorted unique entity IDs with cursor-based pagination
+	// GetSortedEntityIDs returns sorted unique entity IDs with cursor-based pagination.
+	// If scope is non-empty, only entity IDs contained in scope are returned.
 	GetSortedEntityIDs(ctx context.Context, tenantID string,
-		entityType string, cursor string, limit int) ([]string, error)
+		entityType string, scope []string, cursor string, limit int) ([]string, error)
 
 	// GetSortedSubjectIDs returns sorted unique subject IDs with cursor-based pagination
 	GetSortedSubjectIDs(ctx context.Context, tenantID string,
@@ -83,8 +87,9 @@ type RelationRepository interface {
 	// LookupAccessibleEntitiesComplex finds entity IDs that a subject can access
 	// via direct relations, computed usersets, or hierarchical relations using closure table.
 	// parentRelations format: "relation.targetRelation" (e.g., "parent.owner")
+	// If scope is non-empty, only entity IDs contained in scope are considered.
 	LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string,
-		entityType string, relations []string, parentRelations []string,
+		entityType string, scope []string, relations []string, parentRelations []string,
 		subjectType string, subjectID string,
 		maxDepth int, cursor string, limit int) ([]string, error)
 
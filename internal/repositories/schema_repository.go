@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/asakaida/keruberosu/internal/entities"
 )
@@ -11,6 +12,9 @@ type SchemaRepository interface {
 	// Create creates a new schema version for a tenant and returns the version ID
 	Create(ctx context.Context, tenantID string, schemaDSL string) (string, error)
 
+	// CreateInTx creates a new schema version within an existing transaction and returns the version ID
+	CreateInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error)
+
 	// GetLatestVersion retrieves the latest schema version for a tenant
 	GetLatestVersion(ctx context.Context, tenantID string) (*entities.Schema, error)
 
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// TenantRepository defines the interface for tenant data access
+type TenantRepository interface {
+	// Create registers a tenant. Returns ErrAlreadyExists if the ID is taken.
+	Create(ctx context.Context, tenant *entities.Tenant) error
+
+	// CreateInTx registers a tenant within an existing transaction
+	CreateInTx(ctx context.Context, tx *sql.Tx, tenant *entities.Tenant) error
+
+	// Exists reports whether a tenant is registered
+	Exists(ctx context.Context, tenantID string) (bool, error)
+
+	// LockInTx checks that a tenant is registered and keeps it from being deleted
+	// until tx ends. Returns ErrNotFound if the tenant is not registered.
+	LockInTx(ctx context.Context, tx *sql.Tx, tenantID string) error
+
+	// List retrieves tenants ordered by ID, with cursor-based pagination.
+	// The cursor is the ID of the last tenant of the previous page.
+	List(ctx context.Context, limit int, cursor string) ([]*entities.Tenant, error)
+
+	// Delete removes a tenant together with its schemas, relations, attributes,
+	// closure entries, change log and transactions. Returns ErrNotFound if the
+	// tenant is not registered.
+	Delete(ctx context.Context, tenantID string) error
+
+	// DeleteInTx removes a tenant and its data within an existing transaction
+	DeleteInTx(ctx context.Context, tx *sql.Tx, tenantID string) error
+}
@@ -0,0 +1,170 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// ErrInvalidRuleArgument is returned when a request-supplied rule argument
+// cannot be bound to a rule parameter (wrong count, wrong type, or unknown attribute).
+var ErrInvalidRuleArgument = errors.New("invalid rule argument")
+
+// coerceRuleArgument checks a request-supplied argument against a declared
+// attribute type ("string", "integer", "boolean", "double" or their "[]" array
+// forms) and converts it to the representation used for CEL evaluation.
+// Numbers arrive as float64 from google.protobuf.Value, so integers must be
+// whole numbers and are converted to int64.
+func coerceRuleArgument(value interface{}, attrType string) (interface{}, error) {
+	if elemType, isArray := strings.CutSuffix(attrType, "[]"); isArray {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %T", attrType, value)
+		}
+		result := make([]interface{}, len(list))
+		for i, item := range list {
+			v, err := coerceRuleArgument(item, elemType)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			result[i] = v
+		}
+		return result, nil
+	}
+
+	switch attrType {
+	case "string":
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case "boolean":
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		case float64:
+			if v == math.Trunc(v) && !math.IsInf(v, 0) {
+				return int64(v), nil
+			}
+		}
+	case "double":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported attribute type %s", attrType)
+	}
+	return nil, fmt.Errorf("expected %s, got %T", attrType, value)
+}
+
+// bindRuleParameters binds the arguments of a rule call to the parameters of
+// ruleDef. Each parameter either gets the context named by its argument
+// (argContexts, e.g. "resource" or "subject"), or a value: the request argument
+// at the same position when supplied, otherwise the entity's own attribute.
+//
+// Example: rule check(doc, user) called as check(resource, subject)
+//
+//	→ parameter "doc" gets resource context, parameter "user" gets subject context
+//
+// Example: rule check_ip(ip_range) called as check_ip(ip_range)
+//
+//	→ parameter "ip_range" gets arguments[0], or the stored ip_range attribute
+func bindRuleParameters(
+	schema *entities.Schema,
+	entityType string,
+	rule *entities.RuleCallRule,
+	ruleDef *entities.RuleDefinition,
+	argContexts map[string]map[string]interface{},
+	resourceAttrs map[string]interface{},
+	arguments []interface{},
+) (map[string]map[string]interface{}, map[string]interface{}, error) {
+	// Request arguments are bound positionally to the rule's parameters
+	if len(arguments) > len(ruleDef.Parameters) {
+		return nil, nil, fmt.Errorf("%w: rule %s takes %d parameters, got %d arguments",
+			ErrInvalidRuleArgument, rule.RuleName, len(ruleDef.Parameters), len(arguments))
+	}
+
+	paramContexts := make(map[string]map[string]interface{}, len(ruleDef.Parameters))
+	paramValues := make(map[string]interface{})
+	for i, paramName := range ruleDef.Parameters {
+		argName := rule.Arguments[i]
+		var argValue interface{}
+		if i < len(arguments) {
+			argValue = arguments[i]
+		}
+
+		if argCtx, ok := argContexts[argName]; ok {
+			if argValue != nil {
+				return nil, nil, fmt.Errorf("%w: parameter %s of rule %s is bound to %s and cannot take an argument",
+					ErrInvalidRuleArgument, paramName, rule.RuleName, argName)
+			}
+			paramContexts[paramName] = argCtx
+			continue
+		}
+
+		if argValue == nil {
+			// No request argument: fall back to the entity's own attribute
+			value, ok := resourceAttrs[argName]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: no value for parameter %s of rule %s",
+					ErrInvalidRuleArgument, paramName, rule.RuleName)
+			}
+			paramValues[paramName] = value
+			continue
+		}
+
+		var attrSchema *entities.AttributeSchema
+		if entity := schema.GetEntity(entityType); entity != nil {
+			attrSchema = entity.GetAttributeSchema(argName)
+		}
+		if attrSchema == nil {
+			return nil, nil, fmt.Errorf("%w: attribute %s is not declared on entity %s",
+				ErrInvalidRuleArgument, argName, entityType)
+		}
+		value, err := coerceRuleArgument(argValue, attrSchema.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: parameter %s of rule %s: %v",
+				ErrInvalidRuleArgument, paramName, rule.RuleName, err)
+		}
+		paramValues[paramName] = value
+	}
+
+	return paramContexts, paramValues, nil
+}
+
+// findParentRule returns the rule called through a hierarchical rule call on a
+// parent of parentType, trying the namespaced name ("folder.rule") first.
+func findParentRule(schema *entities.Schema, parentType, ruleName string) *entities.RuleDefinition {
+	if ruleDef := schema.GetRule(parentType + "." + ruleName); ruleDef != nil {
+		return ruleDef
+	}
+	return schema.GetRule(ruleName)
+}
+
+// hierarchicalRuleParams maps the parameters of a hierarchical rule call to the
+// attributes of the calling entity named by its arguments. Missing attributes
+// are left unbound.
+func hierarchicalRuleParams(ruleDef *entities.RuleDefinition, arguments []string, currentAttrs map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{})
+	for i, paramName := range ruleDef.Parameters {
+		if i < len(arguments) {
+			if val, ok := currentAttrs[arguments[i]]; ok {
+				params[paramName] = val
+			}
+		}
+	}
+	return params
+}
@@ -0,0 +1,41 @@
+package authorization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoerceRuleArgument(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		attrType string
+		want     interface{}
+		wantErr  bool
+	}{
+		{"string", "10.0.0.0/8", "string", "10.0.0.0/8", false},
+		{"boolean", true, "boolean", true, false},
+		{"integer from whole float", float64(42), "integer", int64(42), false},
+		{"integer from fractional float", 4.2, "integer", nil, true},
+		{"double from float", 4.2, "double", 4.2, false},
+		{"double from int64", int64(4), "double", float64(4), false},
+		{"string array", []interface{}{"a", "b"}, "string[]", []interface{}{"a", "b"}, false},
+		{"integer array", []interface{}{float64(1), float64(2)}, "integer[]", []interface{}{int64(1), int64(2)}, false},
+		{"array element mismatch", []interface{}{"a", float64(1)}, "string[]", nil, true},
+		{"scalar for array type", "a", "string[]", nil, true},
+		{"string for boolean", "true", "boolean", nil, true},
+		{"unsupported type", "x", "timestamp", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceRuleArgument(tt.value, tt.attrType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("coerceRuleArgument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceRuleArgument() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
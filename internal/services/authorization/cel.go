@@ -155,8 +155,15 @@ func (e *CELEngine) GetAvailableFunctions() []string {
 // paramContexts maps each parameter name to its context data (a map of attributes).
 // If all parameter names are standard, the cached standard environment is used for performance.
 func (e *CELEngine) EvaluateRule(expression string, paramContexts map[string]map[string]interface{}) (bool, error) {
+	return e.EvaluateRuleWithValues(expression, paramContexts, nil)
+}
+
+// EvaluateRuleWithValues is like EvaluateRule but additionally binds paramValues,
+// parameters that carry a plain value (e.g., a request argument or attribute value)
+// rather than an attribute map.
+func (e *CELEngine) EvaluateRuleWithValues(expression string, paramContexts map[string]map[string]interface{}, paramValues map[string]interface{}) (bool, error) {
 	// Check if all parameter names are standard CEL variables
-	allStandard := true
+	allStandard := len(paramValues) == 0
 	for paramName := range paramContexts {
 		if paramName != "resource" && paramName != "subject" && paramName != "request" {
 			allStandard = false
@@ -174,10 +181,13 @@ func (e *CELEngine) EvaluateRule(expression string, paramContexts map[string]map
 	}
 
 	// Non-standard parameter names: create a dynamic CEL environment
-	envOpts := make([]cel.EnvOption, 0, len(paramContexts))
+	envOpts := make([]cel.EnvOption, 0, len(paramContexts)+len(paramValues))
 	for paramName := range paramContexts {
 		envOpts = append(envOpts, cel.Variable(paramName, cel.MapType(cel.StringType, cel.DynType)))
 	}
+	for paramName := range paramValues {
+		envOpts = append(envOpts, cel.Variable(paramName, cel.DynType))
+	}
 
 	env, err := cel.NewEnv(envOpts...)
 	if err != nil {
@@ -194,7 +204,7 @@ func (e *CELEngine) EvaluateRule(expression string, paramContexts map[string]map
 		return false, fmt.Errorf("failed to create CEL program: %w", err)
 	}
 
-	vars := make(map[string]interface{}, len(paramContexts))
+	vars := make(map[string]interface{}, len(paramContexts)+len(paramValues))
 	for k, v := range paramContexts {
 		if v == nil {
 			vars[k] = map[string]interface{}{}
@@ -202,6 +212,9 @@ func (e *CELEngine) EvaluateRule(expression string, paramContexts map[string]map
 			vars[k] = v
 		}
 	}
+	for k, v := range paramValues {
+		vars[k] = v
+	}
 
 	result, _, err := program.Eval(vars)
 	if err != nil {
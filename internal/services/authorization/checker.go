@@ -5,26 +5,34 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/repositories/postgres"
 	"github.com/asakaida/keruberosu/pkg/cache"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CheckerInterface defines the interface for permission checking
 type CheckerInterface interface {
 	Check(ctx context.Context, req *CheckRequest) (*CheckResponse, error)
 	CheckMultiple(ctx context.Context, req *CheckRequest, permissions []string) (map[string]bool, error)
+	BulkCheck(ctx context.Context, req *BulkCheckRequest) (*BulkCheckResponse, error)
 }
 
+// DefaultBulkCheckConcurrency is the number of BulkCheck items evaluated in parallel
+// when no limit has been configured
+const DefaultBulkCheckConcurrency = 10
+
 // Checker provides permission checking functionality
 type Checker struct {
-	schemaService   SchemaServiceInterface
-	evaluator       *Evaluator
-	cache           cache.Cache               // Optional cache for check results
-	snapshotManager postgres.SnapshotProvider // Optional snapshot provider for cache consistency
-	cacheTTL        time.Duration             // TTL for cached results
+	schemaService        SchemaServiceInterface
+	evaluator            *Evaluator
+	cache                cache.Cache               // Optional cache for check results
+	snapshotManager      postgres.SnapshotProvider // Optional snapshot provider for cache consistency
+	cacheTTL             time.Duration             // TTL for cached results
+	bulkCheckConcurrency int                       // Max items evaluated in parallel by BulkCheck
 }
 
 // CheckRequest contains the parameters for a permission check
@@ -40,11 +48,47 @@ type CheckRequest struct {
 	ContextualTuples      []*entities.RelationTuple // Temporary relation tuples for this check
 	ContextualAttributes  []*entities.Attribute     // Temporary attributes for this check
 	SnapshotToken         string                    // Optional snapshot token for cache consistency
+	Arguments             []interface{}             // Positional arguments for rule calls (bound to rule parameters)
+	Debug                 bool                      // Record an evaluation trace (bypasses the cache)
+	DepthLimit            int                       // Recursion limit from metadata.depth (0 = DefaultDepth)
 }
 
 // CheckResponse contains the result of a permission check
 type CheckResponse struct {
-	Allowed bool // Whether the subject has the permission
+	Allowed       bool             // Whether the subject has the permission
+	Stats         *EvaluationStats // Work done to compute the result (all zero on a cache hit)
+	Trace         *Trace           // Evaluation trace (only set when the request has Debug)
+	SchemaVersion string           // Schema version the check was evaluated against
+	SnapshotToken string           // Snapshot token the result is valid at (empty if none was resolved)
+	CacheHit      bool             // Whether the result came from the cache
+}
+
+// BulkCheckItem identifies a single check within a BulkCheckRequest
+type BulkCheckItem struct {
+	EntityType      string // Resource entity type (e.g., "document")
+	EntityID        string // Resource entity ID (e.g., "doc1")
+	Permission      string // Permission to check (e.g., "view")
+	SubjectType     string // Subject type (e.g., "user")
+	SubjectID       string // Subject ID (e.g., "alice")
+	SubjectRelation string // Optional subject relation (e.g., "member" for subject set checks)
+}
+
+// BulkCheckRequest contains many checks that share tenant, schema version,
+// context, arguments and snapshot token
+type BulkCheckRequest struct {
+	TenantID             string                    // Tenant ID
+	SchemaVersion        string                    // Schema version (empty = latest)
+	Items                []*BulkCheckItem          // Checks to perform
+	ContextualTuples     []*entities.RelationTuple // Temporary relation tuples shared by all items
+	ContextualAttributes []*entities.Attribute     // Temporary attributes shared by all items
+	SnapshotToken        string                    // Optional snapshot token shared by all items
+	Arguments            []interface{}             // Positional arguments for rule calls shared by all items
+	DepthLimit           int                       // Recursion limit shared by all items (0 = DefaultDepth)
+}
+
+// BulkCheckResponse contains one result per request item, in request order
+type BulkCheckResponse struct {
+	Results []*CheckResponse
 }
 
 // NewChecker creates a new Checker without caching
@@ -55,6 +99,12 @@ func NewChecker(schemaService SchemaServiceInterface, evaluator *Evaluator) *Che
 	}
 }
 
+// SetBulkCheckConcurrency sets the maximum number of BulkCheck items evaluated
+// in parallel. Values <= 0 restore DefaultBulkCheckConcurrency.
+func (c *Checker) SetBulkCheckConcurrency(n int) {
+	c.bulkCheckConcurrency = n
+}
+
 // NewCheckerWithCache creates a new Checker with caching enabled
 func NewCheckerWithCache(
 	schemaService SchemaServiceInterface,
@@ -78,7 +128,9 @@ func (c *Checker) generateCacheKey(req *CheckRequest, snapshotToken string, sche
 	// Create a key from the request parameters, snapshot token, and resolved schema version.
 	// Using the resolved schemaVersion ensures that schema changes invalidate cached results
 	// even when no data writes have occurred.
-	keyData := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s",
+	// The resolved depth limit is part of the key because a shallower limit
+	// can change the outcome of hierarchical lookups.
+	keyData := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s:%d",
 		req.TenantID,
 		schemaVersion,
 		req.EntityType,
@@ -88,6 +140,7 @@ func (c *Checker) generateCacheKey(req *CheckRequest, snapshotToken string, sche
 		req.SubjectID,
 		req.SubjectRelation,
 		snapshotToken,
+		resolveDepthLimit(req.DepthLimit),
 	)
 	// Hash the key to keep it short
 	hash := sha256.Sum256([]byte(keyData))
@@ -102,47 +155,77 @@ func (c *Checker) Check(ctx context.Context, req *CheckRequest) (*CheckResponse,
 		return nil, fmt.Errorf("invalid check request: %w", err)
 	}
 
-	// Skip cache if contextual tuples or attributes are present (they make the result unique)
-	useCache := c.cache != nil && c.snapshotManager != nil && len(req.ContextualTuples) == 0 && len(req.ContextualAttributes) == 0
-
 	// Get parsed schema (needed for both cache key and evaluation)
 	schema, err := c.schemaService.GetSchemaEntity(ctx, req.TenantID, req.SchemaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schema: %w", err)
 	}
 
-	var snapshotToken string
-	var cacheKey string
+	return c.checkWithSchema(ctx, req, schema)
+}
 
-	if useCache {
-		// Get current snapshot token for cache key
-		if req.SnapshotToken != "" {
-			snapshotToken = req.SnapshotToken
-		} else {
-			snapshot, err := c.snapshotManager.GetCurrentSnapshotForRead(ctx)
-			if err != nil {
-				// Log error but continue without cache
-				useCache = false
-			} else {
-				snapshotToken = snapshot.String()
+// checkWithSchema performs a validated permission check against an already
+// resolved schema
+func (c *Checker) checkWithSchema(ctx context.Context, req *CheckRequest, schema *entities.Schema) (*CheckResponse, error) {
+	cacheKey, snapshotToken := c.cacheKeyFor(ctx, req, schema)
+	if cacheKey != "" {
+		if cached, found := c.lookupCache(ctx, cacheKey); found {
+			if resp := cachedResponse(cached, schema, snapshotToken); resp != nil {
+				return resp, nil
 			}
 		}
+	}
 
-		if useCache {
-			// Include the resolved schema version in the cache key so that
-			// schema changes invalidate cached results even when no data
-			// writes (which update the snapshot token) have occurred.
-			cacheKey = c.generateCacheKey(req, snapshotToken, schema.Version)
-
-			// Try to get from cache
-			if cached, found := c.cache.Get(ctx, cacheKey); found {
-				if result, ok := cached.(bool); ok {
-					return &CheckResponse{Allowed: result}, nil
-				}
-			}
+	return c.evaluate(ctx, req, schema, cacheKey, snapshotToken)
+}
+
+// cacheKeyFor returns the cache key of a check and the snapshot token it is
+// keyed by. The key is empty when the check must not use the cache.
+func (c *Checker) cacheKeyFor(ctx context.Context, req *CheckRequest, schema *entities.Schema) (string, string) {
+	// Skip cache if contextual tuples, attributes or arguments are present (they make the result unique),
+	// and for debug checks, which must actually evaluate the rules to trace them
+	useCache := c.cache != nil && c.snapshotManager != nil && !req.Debug &&
+		len(req.ContextualTuples) == 0 && len(req.ContextualAttributes) == 0 && len(req.Arguments) == 0
+	if !useCache {
+		return "", ""
+	}
+
+	// Get current snapshot token for cache key
+	snapshotToken := req.SnapshotToken
+	if snapshotToken == "" {
+		snapshot, err := c.snapshotManager.GetCurrentSnapshotForRead(ctx)
+		if err != nil {
+			// Continue without cache
+			return "", ""
 		}
+		snapshotToken = snapshot.String()
 	}
 
+	// Include the resolved schema version in the cache key so that
+	// schema changes invalidate cached results even when no data
+	// writes (which update the snapshot token) have occurred.
+	return c.generateCacheKey(req, snapshotToken, schema.Version), snapshotToken
+}
+
+// cachedResponse converts a cached check result into a response, or returns nil
+// if the cached value is not a check result
+func cachedResponse(cached interface{}, schema *entities.Schema, snapshotToken string) *CheckResponse {
+	result, ok := cached.(bool)
+	if !ok {
+		return nil
+	}
+	return &CheckResponse{
+		Allowed:       result,
+		Stats:         &EvaluationStats{},
+		SchemaVersion: schema.Version,
+		SnapshotToken: snapshotToken,
+		CacheHit:      true,
+	}
+}
+
+// evaluate evaluates the permission rule of a check that was not answered from
+// the cache, and caches the result under cacheKey unless it is empty
+func (c *Checker) evaluate(ctx context.Context, req *CheckRequest, schema *entities.Schema, cacheKey, snapshotToken string) (*CheckResponse, error) {
 	// Get entity definition
 	entity := schema.GetEntity(req.EntityType)
 	if entity == nil {
@@ -178,7 +261,13 @@ func (c *Checker) Check(ctx context.Context, req *CheckRequest) (*CheckResponse,
 		SubjectRelation:      req.SubjectRelation,
 		ContextualTuples:     req.ContextualTuples,
 		ContextualAttributes: req.ContextualAttributes,
+		Arguments:            req.Arguments,
 		Depth:                0, // Start at depth 0
+		DepthLimit:           req.DepthLimit,
+		Stats:                &EvaluationStats{},
+	}
+	if req.Debug {
+		evalReq.Trace = &Trace{}
 	}
 
 	// Evaluate the permission rule
@@ -188,15 +277,49 @@ func (c *Checker) Check(ctx context.Context, req *CheckRequest) (*CheckResponse,
 	}
 
 	// Store result in cache
-	if useCache && cacheKey != "" {
+	if cacheKey != "" {
 		_ = c.cache.Set(ctx, cacheKey, allowed, c.cacheTTL)
 	}
 
+	if snapshotToken == "" {
+		snapshotToken = req.SnapshotToken
+	}
+
 	return &CheckResponse{
-		Allowed: allowed,
+		Allowed:       allowed,
+		Stats:         evalReq.Stats,
+		Trace:         evalReq.Trace,
+		SchemaVersion: schema.Version,
+		SnapshotToken: snapshotToken,
 	}, nil
 }
 
+// lookupCache reads a cached check result in a "cache.get" span
+func (c *Checker) lookupCache(ctx context.Context, cacheKey string) (interface{}, bool) {
+	ctx, span := tracer.Start(ctx, "cache.get")
+	defer span.End()
+	cached, found := c.cache.Get(ctx, cacheKey)
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+	return cached, found
+}
+
+// lookupCacheBatch reads the cached results of keys with one batch lookup in a
+// "cache.get_multi" span. Returns nil if the cache does not support batches.
+func (c *Checker) lookupCacheBatch(ctx context.Context, keys []string) map[string]interface{} {
+	batch, ok := c.cache.(cache.BatchGetter)
+	if !ok || len(keys) == 0 {
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "cache.get_multi")
+	defer span.End()
+	cached := batch.GetMulti(ctx, keys)
+	span.SetAttributes(
+		attribute.Int("cache.keys", len(keys)),
+		attribute.Int("cache.hits", len(cached)),
+	)
+	return cached
+}
+
 // validateRequest validates the check request
 func (c *Checker) validateRequest(req *CheckRequest) error {
 	if req.TenantID == "" {
@@ -238,6 +361,8 @@ func (c *Checker) CheckMultiple(ctx context.Context, req *CheckRequest, permissi
 			ContextualTuples:     req.ContextualTuples,
 			ContextualAttributes: req.ContextualAttributes,
 			SnapshotToken:        req.SnapshotToken,
+			Arguments:            req.Arguments,
+			DepthLimit:           req.DepthLimit,
 		}
 
 		resp, err := c.Check(ctx, checkReq)
@@ -250,3 +375,132 @@ func (c *Checker) CheckMultiple(ctx context.Context, req *CheckRequest, permissi
 
 	return results, nil
 }
+
+// BulkCheck performs many permission checks that share tenant, context and
+// consistency settings. The schema is resolved once, and every item is
+// evaluated against the same snapshot token so that results are mutually
+// consistent and can share cache entries. Items run concurrently, up to the
+// configured limit; the first failing item aborts the whole request.
+func (c *Checker) BulkCheck(ctx context.Context, req *BulkCheckRequest) (*BulkCheckResponse, error) {
+	if req.TenantID == "" {
+		return nil, fmt.Errorf("invalid bulk check request: tenant ID is required")
+	}
+
+	schema, err := c.schemaService.GetSchemaEntity(ctx, req.TenantID, req.SchemaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	// Pin a single snapshot token for all items. Without a cache the token is
+	// unused by evaluation, so there is no need to query for one.
+	snapshotToken := req.SnapshotToken
+	if snapshotToken == "" && c.cache != nil && c.snapshotManager != nil {
+		if snapshot, err := c.snapshotManager.GetCurrentSnapshotForRead(ctx); err == nil {
+			snapshotToken = snapshot.String()
+		}
+	}
+
+	checkReqs := make([]*CheckRequest, len(req.Items))
+	for i, item := range req.Items {
+		checkReqs[i] = &CheckRequest{
+			TenantID:             req.TenantID,
+			SchemaVersion:        schema.Version,
+			EntityType:           item.EntityType,
+			EntityID:             item.EntityID,
+			Permission:           item.Permission,
+			SubjectType:          item.SubjectType,
+			SubjectID:            item.SubjectID,
+			SubjectRelation:      item.SubjectRelation,
+			ContextualTuples:     req.ContextualTuples,
+			ContextualAttributes: req.ContextualAttributes,
+			SnapshotToken:        snapshotToken,
+			Arguments:            req.Arguments,
+			DepthLimit:           req.DepthLimit,
+		}
+		if err := c.validateRequest(checkReqs[i]); err != nil {
+			return nil, fmt.Errorf("invalid check request at item %d: %w", i, err)
+		}
+	}
+
+	// With a cache supporting batches (such as Redis), look up every item in one
+	// round trip and evaluate only the misses
+	results := make([]*CheckResponse, len(checkReqs))
+	var cacheKeys, cacheTokens []string
+	if _, ok := c.cache.(cache.BatchGetter); ok {
+		cacheKeys = make([]string, len(checkReqs))
+		cacheTokens = make([]string, len(checkReqs))
+		keys := make([]string, 0, len(checkReqs))
+		for i, checkReq := range checkReqs {
+			cacheKeys[i], cacheTokens[i] = c.cacheKeyFor(ctx, checkReq, schema)
+			if cacheKeys[i] != "" {
+				keys = append(keys, cacheKeys[i])
+			}
+		}
+		cached := c.lookupCacheBatch(ctx, keys)
+		for i, key := range cacheKeys {
+			if value, found := cached[key]; found && key != "" {
+				results[i] = cachedResponse(value, schema, cacheTokens[i])
+			}
+		}
+	}
+
+	concurrency := c.bulkCheckConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkCheckConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for i, checkReq := range checkReqs {
+		if results[i] != nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, checkReq *CheckRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var resp *CheckResponse
+			var err error
+			if cacheKeys != nil {
+				resp, err = c.evaluate(ctx, checkReq, schema, cacheKeys[i], cacheTokens[i])
+			} else {
+				resp, err = c.checkWithSchema(ctx, checkReq, schema)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("failed to check item %d: %w", i, err)
+					cancel()
+				})
+				return
+			}
+			results[i] = resp
+		}(i, checkReq)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &BulkCheckResponse{Results: results}, nil
+}
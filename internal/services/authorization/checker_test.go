@@ -2,10 +2,16 @@ package authorization
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories/postgres"
+	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
 )
 
 func TestChecker_Check_BasicPermission(t *testing.T) {
@@ -843,3 +849,430 @@ func TestChecker_SchemaVersionResolutionFromMetadata(t *testing.T) {
 		t.Errorf("checker's first call used version %q, expected %q", capturedVersions[0], "v42")
 	}
 }
+
+// countingSnapshotProvider returns a fixed snapshot and counts how often it is asked.
+type countingSnapshotProvider struct {
+	calls atomic.Int32
+}
+
+func (p *countingSnapshotProvider) GetCurrentSnapshotForRead(_ context.Context) (*postgres.SnapshotToken, error) {
+	p.calls.Add(1)
+	return &postgres.SnapshotToken{Xmin: 100, Xmax: 105}, nil
+}
+
+func TestChecker_BulkCheck(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc3", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+
+	req := &BulkCheckRequest{
+		TenantID: "test-tenant",
+		Items: []*BulkCheckItem{
+			{EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc2", Permission: "view", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc3", Permission: "view", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "bob"},
+		},
+	}
+
+	resp, err := checker.BulkCheck(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BulkCheck() error = %v", err)
+	}
+
+	want := []bool{true, false, true, false}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("BulkCheck() returned %d results, want %d", len(resp.Results), len(want))
+	}
+	for i, w := range want {
+		if resp.Results[i].Allowed != w {
+			t.Errorf("result[%d] allowed = %v, want %v", i, resp.Results[i].Allowed, w)
+		}
+	}
+}
+
+func TestChecker_BulkCheck_ResolvesSchemaAndSnapshotOnce(t *testing.T) {
+	schema := createTestSchema()
+	schema.Version = "01HWRESOLVED"
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+
+	var mu sync.Mutex
+	var capturedVersions []string
+	schemaService := &mockSchemaServiceCapture{
+		schema: schema,
+		onGetSchemaEntity: func(tenantID, version string) {
+			mu.Lock()
+			defer mu.Unlock()
+			capturedVersions = append(capturedVersions, version)
+		},
+	}
+
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checkCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer checkCache.Close()
+	snapshots := &countingSnapshotProvider{}
+	checker := NewCheckerWithCache(schemaService, evaluator, checkCache, snapshots, time.Minute)
+
+	items := make([]*BulkCheckItem, 20)
+	for i := range items {
+		items[i] = &BulkCheckItem{EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "alice"}
+	}
+
+	resp, err := checker.BulkCheck(context.Background(), &BulkCheckRequest{TenantID: "test-tenant", Items: items})
+	if err != nil {
+		t.Fatalf("BulkCheck() error = %v", err)
+	}
+	for i, r := range resp.Results {
+		if !r.Allowed {
+			t.Errorf("result[%d] allowed = false, want true", i)
+		}
+	}
+
+	if got := snapshots.calls.Load(); got != 1 {
+		t.Errorf("snapshot provider called %d times, want 1", got)
+	}
+
+	// The checker resolves the requested (latest) version once; every
+	// evaluation afterwards must use the pinned, resolved version.
+	if len(capturedVersions) == 0 || capturedVersions[0] != "" {
+		t.Fatalf("expected first GetSchemaEntity call to request latest, got %v", capturedVersions)
+	}
+	for _, v := range capturedVersions[1:] {
+		if v != schema.Version {
+			t.Errorf("evaluation used schema version %q, want %q", v, schema.Version)
+		}
+	}
+}
+
+// batchCache wraps memorycache with a counting GetMulti
+type batchCache struct {
+	*memorycache.Cache
+	batches atomic.Int32
+	gets    atomic.Int32
+}
+
+func (c *batchCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	c.gets.Add(1)
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *batchCache) GetMulti(ctx context.Context, keys []string) map[string]interface{} {
+	c.batches.Add(1)
+	result := make(map[string]interface{})
+	for _, key := range keys {
+		if value, found := c.Cache.Get(ctx, key); found {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func TestChecker_BulkCheck_BatchCacheLookup(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	schemaService := &mockSchemaRepository{schema}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	memCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	checkCache := &batchCache{Cache: memCache}
+	defer checkCache.Close()
+	checker := NewCheckerWithCache(schemaService, evaluator, checkCache, &countingSnapshotProvider{}, time.Minute)
+
+	req := &BulkCheckRequest{
+		TenantID: "test-tenant",
+		Items: []*BulkCheckItem{
+			{EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc2", Permission: "view", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	first, err := checker.BulkCheck(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BulkCheck() error = %v", err)
+	}
+	second, err := checker.BulkCheck(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BulkCheck() error = %v", err)
+	}
+
+	if got := checkCache.batches.Load(); got != 2 {
+		t.Errorf("GetMulti called %d times, want 1 per BulkCheck", got)
+	}
+	if got := checkCache.gets.Load(); got != 0 {
+		t.Errorf("Get called %d times, want items looked up only by GetMulti", got)
+	}
+	for i, want := range []bool{true, false} {
+		if first.Results[i].CacheHit || !second.Results[i].CacheHit {
+			t.Errorf("item %d: cache hit = (%v, %v), want (false, true)", i, first.Results[i].CacheHit, second.Results[i].CacheHit)
+		}
+		if first.Results[i].Allowed != want || second.Results[i].Allowed != want {
+			t.Errorf("item %d: allowed = (%v, %v), want %v", i, first.Results[i].Allowed, second.Results[i].Allowed, want)
+		}
+		if second.Results[i].SnapshotToken != "100:105:" {
+			t.Errorf("item %d: snapshot token = %q, want %q", i, second.Results[i].SnapshotToken, "100:105:")
+		}
+	}
+}
+
+func TestChecker_Check_ReportsCacheHitAndResolvedVersion(t *testing.T) {
+	schema := createTestSchema()
+	schema.Version = "01HWRESOLVED"
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	schemaService := &mockSchemaServiceCapture{schema: schema}
+
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checkCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer checkCache.Close()
+	checker := NewCheckerWithCache(schemaService, evaluator, checkCache, &countingSnapshotProvider{}, time.Minute)
+
+	req := &CheckRequest{TenantID: "test-tenant", EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "alice"}
+	first, err := checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	second, err := checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if first.CacheHit || !second.CacheHit {
+		t.Errorf("cache hit = (%v, %v), want (false, true)", first.CacheHit, second.CacheHit)
+	}
+	for _, resp := range []*CheckResponse{first, second} {
+		if resp.SchemaVersion != schema.Version || resp.SnapshotToken != "100:105:" {
+			t.Errorf("got version %q token %q, want %q and %q", resp.SchemaVersion, resp.SnapshotToken, schema.Version, "100:105:")
+		}
+	}
+}
+
+func TestChecker_BulkCheck_ConcurrencyLimit(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{}
+
+	var inFlight, maxInFlight atomic.Int32
+	schemaService := &mockSchemaServiceCapture{
+		schema: schema,
+		onGetSchemaEntity: func(tenantID, version string) {
+			if version == "" {
+				return // initial resolution by BulkCheck itself
+			}
+			n := inFlight.Add(1)
+			for {
+				cur := maxInFlight.Load()
+				if n <= cur || maxInFlight.CompareAndSwap(cur, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		},
+	}
+
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+	checker.SetBulkCheckConcurrency(3)
+
+	items := make([]*BulkCheckItem, 30)
+	for i := range items {
+		items[i] = &BulkCheckItem{EntityType: "document", EntityID: fmt.Sprintf("doc%d", i), Permission: "view", SubjectType: "user", SubjectID: "alice"}
+	}
+
+	resp, err := checker.BulkCheck(context.Background(), &BulkCheckRequest{TenantID: "test-tenant", Items: items})
+	if err != nil {
+		t.Fatalf("BulkCheck() error = %v", err)
+	}
+	if len(resp.Results) != len(items) {
+		t.Fatalf("BulkCheck() returned %d results, want %d", len(resp.Results), len(items))
+	}
+	if got := maxInFlight.Load(); got > 3 {
+		t.Errorf("observed %d concurrent checks, want at most 3", got)
+	}
+}
+
+func TestChecker_BulkCheck_ErrorCases(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+
+	valid := &BulkCheckItem{EntityType: "document", EntityID: "doc1", Permission: "view", SubjectType: "user", SubjectID: "alice"}
+
+	tests := []struct {
+		name    string
+		req     *BulkCheckRequest
+		wantErr string
+	}{
+		{
+			name:    "missing tenant",
+			req:     &BulkCheckRequest{Items: []*BulkCheckItem{valid}},
+			wantErr: "tenant ID is required",
+		},
+		{
+			name: "invalid item",
+			req: &BulkCheckRequest{
+				TenantID: "test-tenant",
+				Items:    []*BulkCheckItem{valid, {EntityType: "document", Permission: "view", SubjectType: "user", SubjectID: "alice"}},
+			},
+			wantErr: "item 1",
+		},
+		{
+			name: "nonexistent permission",
+			req: &BulkCheckRequest{
+				TenantID: "test-tenant",
+				Items:    []*BulkCheckItem{valid, {EntityType: "document", EntityID: "doc1", Permission: "nope", SubjectType: "user", SubjectID: "alice"}},
+			},
+			wantErr: "failed to check item 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := checker.BulkCheck(context.Background(), tt.req)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecker_Check_DebugTrace(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checkCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer checkCache.Close()
+	checker := NewCheckerWithCache(schemaService, evaluator, checkCache, &countingSnapshotProvider{}, time.Minute)
+
+	req := &CheckRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	}
+
+	// Warm the cache with a regular check; it must not carry a trace
+	resp, err := checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if resp.Trace != nil {
+		t.Error("expected no trace without Debug")
+	}
+
+	// A debug check must evaluate (not hit the cache) and return the trace
+	req.Debug = true
+	resp, err = checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !resp.Allowed {
+		t.Error("expected allowed")
+	}
+	if resp.Trace == nil || len(resp.Trace.Nodes) != 1 {
+		t.Fatalf("expected a single-node trace, got %+v", resp.Trace)
+	}
+	node := resp.Trace.Nodes[0]
+	if node.Type != TraceTypeRelation || node.Rule != "owner" || !node.Result {
+		t.Errorf("unexpected trace node %+v", node)
+	}
+	if len(node.Matched) != 1 || node.Matched[0] != "document:doc1#owner@user:alice" {
+		t.Errorf("expected matched owner tuple, got %v", node.Matched)
+	}
+}
+
+func TestChecker_Check_Stats(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "editor", SubjectType: "user", SubjectID: "bob"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+
+	// edit = owner or editor: the OR node plus two relation nodes. "owner" misses
+	// and costs Exists + FindByEntityWithRelation; "editor" hits on Exists.
+	resp, err := checker.Check(context.Background(), &CheckRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		Permission:  "edit",
+		SubjectType: "user",
+		SubjectID:   "bob",
+	})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !resp.Allowed {
+		t.Error("expected allowed")
+	}
+	if resp.Stats == nil {
+		t.Fatal("expected stats")
+	}
+	if resp.Stats.CheckCount != 3 {
+		t.Errorf("CheckCount = %d, want 3", resp.Stats.CheckCount)
+	}
+	if resp.Stats.QueryCount != 3 {
+		t.Errorf("QueryCount = %d, want 3", resp.Stats.QueryCount)
+	}
+	if resp.Stats.CELEvaluations != 0 {
+		t.Errorf("CELEvaluations = %d, want 0", resp.Stats.CELEvaluations)
+	}
+}
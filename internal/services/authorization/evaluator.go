@@ -3,9 +3,11 @@ package authorization
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/asakaida/keruberosu/internal/entities"
 	"github.com/asakaida/keruberosu/internal/repositories"
@@ -46,14 +48,32 @@ func extractAllBaseTypes(targetType string) []string {
 }
 
 const (
-	// MaxDepth is the maximum recursion depth for hierarchical permission evaluation
+	// MaxDepth is the upper bound for the per-request recursion limit
 	MaxDepth = 100
+	// DefaultDepth is the recursion limit used when a request does not set metadata.depth
+	DefaultDepth = 50
 	// MaxTuplesPerQuery is the maximum number of tuples returned per query
 	MaxTuplesPerQuery = 10000
 	// MaxUsersetDepth is the maximum recursion depth for computed userset expansion in SQL
 	MaxUsersetDepth = 10
 )
 
+// ErrMaxDepthExceeded is returned when evaluating a request needs more recursion
+// than its depth limit allows.
+var ErrMaxDepthExceeded = errors.New("maximum recursion depth exceeded")
+
+// resolveDepthLimit returns the recursion limit for a requested depth:
+// DefaultDepth when unset, capped at MaxDepth.
+func resolveDepthLimit(requested int) int {
+	if requested <= 0 {
+		return DefaultDepth
+	}
+	if requested > MaxDepth {
+		return MaxDepth
+	}
+	return requested
+}
+
 // SchemaServiceInterface defines the interface for schema operations
 // This interface is defined here to avoid circular dependency
 type SchemaServiceInterface interface {
@@ -79,7 +99,36 @@ type EvaluationRequest struct {
 	SubjectRelation      string                    // Optional subject relation for subject set checks
 	ContextualTuples     []*entities.RelationTuple // Temporary tuples for this request
 	ContextualAttributes []*entities.Attribute     // Temporary attributes for this request
+	Arguments            []interface{}             // Positional rule-call arguments supplied with the request
 	Depth                int                       // Current recursion depth
+	DepthLimit           int                       // Recursion limit for this request (0 = DefaultDepth)
+	Trace                *Trace                    // Optional trace collecting visited rule nodes (debug checks)
+	Stats                *EvaluationStats          // Optional counters for the work done by this evaluation
+
+	traceNode *TraceNode // Trace node of the rule being evaluated (nil when not tracing)
+}
+
+// child returns the request for evaluating a rule of entityType:entityID on behalf
+// of r, one level deeper. The subject, contextual data, limits, trace and stats are
+// carried over. Arguments are not: they are bound only to the rule calls of the
+// requested permission, so another permission or entity reached from it is
+// evaluated with its own stored attributes.
+func (r *EvaluationRequest) child(entityType, entityID string) *EvaluationRequest {
+	return &EvaluationRequest{
+		TenantID:             r.TenantID,
+		SchemaVersion:        r.SchemaVersion,
+		EntityType:           entityType,
+		EntityID:             entityID,
+		SubjectType:          r.SubjectType,
+		SubjectID:            r.SubjectID,
+		SubjectRelation:      r.SubjectRelation,
+		ContextualTuples:     r.ContextualTuples,
+		ContextualAttributes: r.ContextualAttributes,
+		Depth:                r.Depth + 1,
+		DepthLimit:           r.DepthLimit,
+		Trace:                r.Trace,
+		Stats:                r.Stats,
+	}
 }
 
 // NewEvaluator creates a new Evaluator
@@ -104,10 +153,34 @@ func (e *Evaluator) EvaluateRule(
 	rule entities.PermissionRule,
 ) (bool, error) {
 	// Check depth limit
-	if req.Depth >= MaxDepth {
-		return false, fmt.Errorf("maximum recursion depth exceeded (depth: %d)", req.Depth)
-	}
+	if limit := resolveDepthLimit(req.DepthLimit); req.Depth >= limit {
+		return false, fmt.Errorf("%w (depth limit: %d)", ErrMaxDepthExceeded, limit)
+	}
+
+	req.Stats.addCheck()
+
+	ctx, span := startRuleSpan(ctx, req, rule)
+	var result bool
+	var err error
+	if req.Trace != nil {
+		start := time.Now()
+		traced := *req
+		traced.traceNode = req.Trace.enter(req, rule)
+		result, err = e.evaluateRule(ctx, &traced, rule)
+		req.Trace.exit(traced.traceNode, start, result, err)
+	} else {
+		result, err = e.evaluateRule(ctx, req, rule)
+	}
+	endSpan(span, result, err)
+	return result, err
+}
 
+// evaluateRule dispatches rule to the evaluator for its type
+func (e *Evaluator) evaluateRule(
+	ctx context.Context,
+	req *EvaluationRequest,
+	rule entities.PermissionRule,
+) (bool, error) {
 	switch r := rule.(type) {
 	case *entities.RelationRule:
 		return e.evaluateRelation(ctx, req, r)
@@ -136,8 +209,8 @@ func (e *Evaluator) evaluateRelation(
 	rule *entities.RelationRule,
 ) (bool, error) {
 	// Check depth limit to prevent infinite recursion from cyclic computed usersets
-	if req.Depth >= MaxDepth {
-		return false, fmt.Errorf("maximum recursion depth exceeded (depth: %d)", req.Depth)
+	if limit := resolveDepthLimit(req.DepthLimit); req.Depth >= limit {
+		return false, fmt.Errorf("%w (depth limit: %d)", ErrMaxDepthExceeded, limit)
 	}
 
 	// Check if the relation name actually refers to a permission in the same entity.
@@ -155,18 +228,7 @@ func (e *Evaluator) evaluateRelation(
 		isRelation := entity.GetRelation(rule.Relation) != nil
 		if !isRelation {
 			if perm := entity.GetPermission(rule.Relation); perm != nil {
-				return e.EvaluateRule(ctx, &EvaluationRequest{
-					TenantID:             req.TenantID,
-					SchemaVersion:        req.SchemaVersion,
-					EntityType:           req.EntityType,
-					EntityID:             req.EntityID,
-					SubjectType:          req.SubjectType,
-					SubjectID:            req.SubjectID,
-					SubjectRelation:      req.SubjectRelation,
-					ContextualTuples:     req.ContextualTuples,
-					ContextualAttributes: req.ContextualAttributes,
-					Depth:                req.Depth + 1,
-				}, perm.Rule)
+				return e.EvaluateRule(ctx, req.child(req.EntityType, req.EntityID), perm.Rule)
 			}
 		}
 	}
@@ -184,17 +246,29 @@ func (e *Evaluator) evaluateRelation(
 				tuple.SubjectType == req.SubjectType &&
 				tuple.SubjectID == req.SubjectID &&
 				tuple.SubjectRelation == req.SubjectRelation {
+				req.traceNode.match(tuple)
 				return true, nil
 			}
 		}
 
 		// Check database for subject set match
+		req.Stats.addQuery()
 		exists, err := e.relationRepo.ExistsWithSubjectRelation(ctx, req.TenantID,
 			req.EntityType, req.EntityID, rule.Relation,
 			req.SubjectType, req.SubjectID, req.SubjectRelation)
 		if err != nil {
 			return false, fmt.Errorf("failed to check relation existence with subject relation: %w", err)
 		}
+		if exists {
+			req.traceNode.match(&entities.RelationTuple{
+				EntityType:      req.EntityType,
+				EntityID:        req.EntityID,
+				Relation:        rule.Relation,
+				SubjectType:     req.SubjectType,
+				SubjectID:       req.SubjectID,
+				SubjectRelation: req.SubjectRelation,
+			})
+		}
 		return exists, nil
 	}
 
@@ -206,27 +280,32 @@ func (e *Evaluator) evaluateRelation(
 			tuple.SubjectType == req.SubjectType &&
 			tuple.SubjectID == req.SubjectID &&
 			tuple.SubjectRelation == "" {
+			req.traceNode.match(tuple)
 			return true, nil
 		}
 	}
 
 	// Check in database for direct match using Exists (more efficient than Read)
-	exists, err := e.relationRepo.Exists(ctx, req.TenantID, &entities.RelationTuple{
+	directTuple := &entities.RelationTuple{
 		EntityType:  req.EntityType,
 		EntityID:    req.EntityID,
 		Relation:    rule.Relation,
 		SubjectType: req.SubjectType,
 		SubjectID:   req.SubjectID,
-	})
+	}
+	req.Stats.addQuery()
+	exists, err := e.relationRepo.Exists(ctx, req.TenantID, directTuple)
 	if err != nil {
 		return false, fmt.Errorf("failed to check relation existence: %w", err)
 	}
 	if exists {
+		req.traceNode.match(directTuple)
 		return true, nil
 	}
 
 	// Check for subject relations (e.g., team:backend-team#member)
 	// Get all tuples for this entity and relation using specialized query
+	req.Stats.addQuery()
 	allTuples, err := e.relationRepo.FindByEntityWithRelation(ctx, req.TenantID, req.EntityType, req.EntityID, rule.Relation, MaxTuplesPerQuery)
 	if err != nil {
 		return false, fmt.Errorf("failed to find relations by entity with relation: %w", err)
@@ -245,25 +324,18 @@ func (e *Evaluator) evaluateRelation(
 		// If this tuple has a subject relation, expand it recursively.
 		// Example: tuple is "repository:backend-api#contributor@team:backend-team#member"
 		// We need to check if "user:frank" has relation "member" with "team:backend-team".
-		// Using recursive evaluateRelation handles nested computed usersets:
+		// Recursing through EvaluateRule with a RelationRule handles nested computed usersets:
 		// e.g., team#member → group#member → user
 		if tuple.SubjectRelation != "" {
-			subjectReq := &EvaluationRequest{
-				TenantID:             req.TenantID,
-				SchemaVersion:        req.SchemaVersion,
-				EntityType:           tuple.SubjectType,
-				EntityID:             tuple.SubjectID,
-				SubjectType:          req.SubjectType,
-				SubjectID:            req.SubjectID,
-				ContextualTuples:     req.ContextualTuples,
-				ContextualAttributes: req.ContextualAttributes,
-				Depth:                req.Depth + 1,
-			}
-			result, err := e.evaluateRelation(ctx, subjectReq, &entities.RelationRule{Relation: tuple.SubjectRelation})
+			// The subject itself is checked, not a subject set
+			subjectReq := req.child(tuple.SubjectType, tuple.SubjectID)
+			subjectReq.SubjectRelation = ""
+			result, err := e.EvaluateRule(ctx, subjectReq, &entities.RelationRule{Relation: tuple.SubjectRelation})
 			if err != nil {
 				return false, fmt.Errorf("failed to evaluate subject relation: %w", err)
 			}
 			if result {
+				req.traceNode.match(tuple)
 				return true, nil
 			}
 		}
@@ -354,16 +426,19 @@ func (e *Evaluator) evaluateHierarchical(
 	// on the same entity type, AND there are no contextual tuples to consider.
 	targetType := extractBaseType(relation.TargetType)
 	if targetType == req.EntityType && rule.Permission == rule.Relation && len(req.ContextualTuples) == 0 && req.SubjectRelation == "" {
+		req.Stats.addQuery()
 		found, err := e.relationRepo.FindHierarchicalWithSubject(
 			ctx, req.TenantID, req.EntityType, req.EntityID,
-			rule.Relation, req.SubjectType, req.SubjectID, MaxDepth)
+			rule.Relation, req.SubjectType, req.SubjectID, resolveDepthLimit(req.DepthLimit)-req.Depth)
 		if err == nil {
+			req.traceNode.setValue("strategy", "hierarchical_query")
 			return found, nil
 		}
-		log.Printf("WARNING: hierarchical CTE query failed, falling back to recursive evaluation: %v", err)
+		slog.WarnContext(ctx, "Hierarchical CTE query failed, falling back to recursive evaluation", "relation", rule.Relation, "error", err)
 	}
 
 	// Get the parent entity(s) via the relation using specialized query
+	req.Stats.addQuery()
 	tuples, err := e.relationRepo.FindByEntityWithRelation(ctx, req.TenantID, req.EntityType, req.EntityID, rule.Relation, MaxTuplesPerQuery)
 	if err != nil {
 		return false, fmt.Errorf("failed to read relations: %w", err)
@@ -390,25 +465,13 @@ func (e *Evaluator) evaluateHierarchical(
 		parentPermission := schema.GetPermission(tuple.SubjectType, rule.Permission)
 		if parentPermission != nil {
 			// Create a new request for the parent entity
-			parentReq := &EvaluationRequest{
-				TenantID:             req.TenantID,
-				SchemaVersion:        req.SchemaVersion,
-				EntityType:           tuple.SubjectType,
-				EntityID:             tuple.SubjectID,
-				SubjectType:          req.SubjectType,
-				SubjectID:            req.SubjectID,
-				SubjectRelation:      req.SubjectRelation,
-				ContextualTuples:     req.ContextualTuples,
-				ContextualAttributes: req.ContextualAttributes,
-				Depth:                req.Depth + 1, // Increment depth
-			}
-
 			// Recursively evaluate the parent permission
-			result, err := e.EvaluateRule(ctx, parentReq, parentPermission.Rule)
+			result, err := e.EvaluateRule(ctx, req.child(tuple.SubjectType, tuple.SubjectID), parentPermission.Rule)
 			if err != nil {
 				return false, fmt.Errorf("failed to evaluate hierarchical permission: %w", err)
 			}
 			if result {
+				req.traceNode.match(tuple)
 				return true, nil // Found at least one parent that grants permission
 			}
 		} else {
@@ -417,23 +480,12 @@ func (e *Evaluator) evaluateHierarchical(
 			// evaluation including computed userset expansion and SubjectRelation propagation.
 			parentEntityDef := schema.GetEntity(tuple.SubjectType)
 			if parentEntityDef != nil && parentEntityDef.GetRelation(rule.Permission) != nil {
-				parentReq := &EvaluationRequest{
-					TenantID:             req.TenantID,
-					SchemaVersion:        req.SchemaVersion,
-					EntityType:           tuple.SubjectType,
-					EntityID:             tuple.SubjectID,
-					SubjectType:          req.SubjectType,
-					SubjectID:            req.SubjectID,
-					SubjectRelation:      req.SubjectRelation,
-					ContextualTuples:     req.ContextualTuples,
-					ContextualAttributes: req.ContextualAttributes,
-					Depth:                req.Depth + 1,
-				}
-				relResult, err := e.evaluateRelation(ctx, parentReq, &entities.RelationRule{Relation: rule.Permission})
+				relResult, err := e.EvaluateRule(ctx, req.child(tuple.SubjectType, tuple.SubjectID), &entities.RelationRule{Relation: rule.Permission})
 				if err != nil {
 					return false, fmt.Errorf("failed to evaluate parent relation: %w", err)
 				}
 				if relResult {
+					req.traceNode.match(tuple)
 					return true, nil
 				}
 			}
@@ -450,6 +502,7 @@ func (e *Evaluator) evaluateABAC(
 	rule *entities.ABACRule,
 ) (bool, error) {
 	// Get resource attributes
+	req.Stats.addQuery()
 	resourceAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, req.EntityType, req.EntityID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read resource attributes: %w", err)
@@ -460,6 +513,7 @@ func (e *Evaluator) evaluateABAC(
 	}
 
 	// Get subject attributes
+	req.Stats.addQuery()
 	subjectAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, req.SubjectType, req.SubjectID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read subject attributes: %w", err)
@@ -476,8 +530,14 @@ func (e *Evaluator) evaluateABAC(
 		Request:  map[string]interface{}{}, // Can be extended with request metadata
 	}
 
+	req.traceNode.setValue("resource", resourceAttrs)
+	req.traceNode.setValue("subject", subjectAttrs)
+
 	// Evaluate the CEL expression
-	result, err := e.celEngine.Evaluate(rule.Expression, evalContext)
+	req.Stats.addCELEvaluation()
+	result, err := traceCEL(ctx, rule.Expression, func() (bool, error) {
+		return e.celEngine.Evaluate(rule.Expression, evalContext)
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to evaluate ABAC rule: %w", err)
 	}
@@ -510,6 +570,7 @@ func (e *Evaluator) evaluateRuleCall(
 	}
 
 	// Get resource attributes
+	req.Stats.addQuery()
 	resourceAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, req.EntityType, req.EntityID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read resource attributes: %w", err)
@@ -520,6 +581,7 @@ func (e *Evaluator) evaluateRuleCall(
 	}
 
 	// Get subject attributes
+	req.Stats.addQuery()
 	subjectAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, req.SubjectType, req.SubjectID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read subject attributes: %w", err)
@@ -536,18 +598,25 @@ func (e *Evaluator) evaluateRuleCall(
 		"request":  {},
 	}
 
-	// Build parameter-to-context mapping: each parameter name gets the context
-	// specified by its corresponding argument.
-	// Example: rule check(doc, user) called as check(resource, subject)
-	//   → parameter "doc" gets resource context, parameter "user" gets subject context
-	paramContexts := make(map[string]map[string]interface{}, len(ruleDef.Parameters))
-	for i, paramName := range ruleDef.Parameters {
-		argName := rule.Arguments[i]
-		paramContexts[paramName] = argContexts[argName]
+	paramContexts, paramValues, err := bindRuleParameters(schema, req.EntityType, rule, ruleDef, argContexts, resourceAttrs, req.Arguments)
+	if err != nil {
+		return false, err
+	}
+
+	if req.traceNode != nil {
+		for name, v := range paramContexts {
+			req.traceNode.setValue(name, v)
+		}
+		for name, v := range paramValues {
+			req.traceNode.setValue(name, v)
+		}
 	}
 
 	// Evaluate the CEL expression from the rule body
-	result, err := e.celEngine.EvaluateRule(ruleDef.Body, paramContexts)
+	req.Stats.addCELEvaluation()
+	result, err := traceCEL(ctx, ruleDef.Body, func() (bool, error) {
+		return e.celEngine.EvaluateRuleWithValues(ruleDef.Body, paramContexts, paramValues)
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to evaluate rule %s: %w", rule.RuleName, err)
 	}
@@ -569,6 +638,7 @@ func (e *Evaluator) evaluateHierarchicalRuleCall(
 	}
 
 	// Get parent entities via the relation
+	req.Stats.addQuery()
 	tuples, err := e.relationRepo.FindByEntityWithRelation(ctx, req.TenantID, req.EntityType, req.EntityID, rule.Relation, MaxTuplesPerQuery)
 	if err != nil {
 		return false, fmt.Errorf("failed to read parent relations: %w", err)
@@ -584,6 +654,7 @@ func (e *Evaluator) evaluateHierarchicalRuleCall(
 	}
 
 	// Get current entity's attributes (for argument values)
+	req.Stats.addQuery()
 	currentAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, req.EntityType, req.EntityID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read current entity attributes: %w", err)
@@ -599,12 +670,7 @@ func (e *Evaluator) evaluateHierarchicalRuleCall(
 		if tuple.SubjectRelation != "" {
 			continue
 		}
-		// Find rule definition (try namespaced first, then plain)
-		namespacedName := tuple.SubjectType + "." + rule.RuleName
-		ruleDef := schema.GetRule(namespacedName)
-		if ruleDef == nil {
-			ruleDef = schema.GetRule(rule.RuleName)
-		}
+		ruleDef := findParentRule(schema, tuple.SubjectType, rule.RuleName)
 		if ruleDef == nil {
 			return false, fmt.Errorf("rule %s not found", rule.RuleName)
 		}
@@ -616,6 +682,7 @@ func (e *Evaluator) evaluateHierarchicalRuleCall(
 		}
 
 		// Get parent entity's attributes → these become "this" context
+		req.Stats.addQuery()
 		parentAttrs, err := e.attributeRepo.Read(ctx, req.TenantID, tuple.SubjectType, tuple.SubjectID)
 		if err != nil {
 			return false, fmt.Errorf("failed to read parent attributes: %w", err)
@@ -625,23 +692,23 @@ func (e *Evaluator) evaluateHierarchicalRuleCall(
 			parentAttrs = mergeContextualAttributes(parentAttrs, req.ContextualAttributes, tuple.SubjectType, tuple.SubjectID)
 		}
 
-		// Build parameter values: map rule parameter names → current entity attribute values
-		paramMap := make(map[string]interface{})
-		for i, paramName := range ruleDef.Parameters {
-			if i < len(rule.Arguments) {
-				argAttrName := rule.Arguments[i]
-				if val, ok := currentAttrs[argAttrName]; ok {
-					paramMap[paramName] = val
-				}
-			}
-		}
+		paramMap := hierarchicalRuleParams(ruleDef, rule.Arguments, currentAttrs)
+
+		req.traceNode.setValue(tuple.SubjectType+":"+tuple.SubjectID, map[string]interface{}{
+			"this":   parentAttrs,
+			"params": paramMap,
+		})
 
 		// Evaluate CEL with "this" = parent attributes, plus parameter values
-		result, err := e.celEngine.EvaluateWithParams(ruleDef.Body, parentAttrs, paramMap)
+		req.Stats.addCELEvaluation()
+		result, err := traceCEL(ctx, ruleDef.Body, func() (bool, error) {
+			return e.celEngine.EvaluateWithParams(ruleDef.Body, parentAttrs, paramMap)
+		})
 		if err != nil {
 			return false, fmt.Errorf("failed to evaluate hierarchical rule %s: %w", rule.RuleName, err)
 		}
 		if result {
+			req.traceNode.match(tuple)
 			return true, nil
 		}
 	}
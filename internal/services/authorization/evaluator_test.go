@@ -3,6 +3,8 @@ package authorization
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"sort"
 	"testing"
 
@@ -20,6 +22,10 @@ func (m *mockSchemaRepository) Create(ctx context.Context, tenantID string, sche
 	return "v1", nil
 }
 
+func (m *mockSchemaRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	return "v1", nil
+}
+
 func (m *mockSchemaRepository) GetLatestVersion(ctx context.Context, tenantID string) (*entities.Schema, error) {
 	return m.schema, nil
 }
@@ -44,7 +50,7 @@ func (m *mockSchemaRepository) GetSchemaEntity(ctx context.Context, tenantID str
 
 type mockRelationRepository struct {
 	tuples                                 []*entities.RelationTuple
-	lookupAccessibleEntitiesComplexFunc    func(ctx context.Context, tenantID string, entityType string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error)
+	lookupAccessibleEntitiesComplexFunc    func(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error)
 	lookupAccessibleSubjectsComplexFunc    func(ctx context.Context, tenantID string, entityType string, entityID string, relations []string, parentRelations []string, subjectType string, maxDepth int, cursor string, limit int) ([]string, error)
 }
 
@@ -104,6 +110,10 @@ func (m *mockRelationRepository) DeleteByFilter(ctx context.Context, tenantID st
 	return nil
 }
 
+func (m *mockRelationRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.RelationFilter) error {
+	return nil
+}
+
 func (m *mockRelationRepository) ReadByFilter(ctx context.Context, tenantID string, filter *repositories.RelationFilter, pageSize int, pageToken string) ([]*entities.RelationTuple, string, error) {
 	return nil, "", nil
 }
@@ -146,11 +156,11 @@ func (m *mockRelationRepository) RebuildClosure(ctx context.Context, tenantID st
 	return nil
 }
 
-func (m *mockRelationRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, cursor string, limit int) ([]string, error) {
+func (m *mockRelationRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	seen := make(map[string]bool)
 	var ids []string
 	for _, t := range m.tuples {
-		if t.EntityType == entityType && !seen[t.EntityID] {
+		if t.EntityType == entityType && !seen[t.EntityID] && inScope(scope, t.EntityID) {
 			seen[t.EntityID] = true
 			ids = append(ids, t.EntityID)
 		}
@@ -204,9 +214,9 @@ func (m *mockRelationRepository) GetSortedSubjectIDs(ctx context.Context, tenant
 	return ids, nil
 }
 
-func (m *mockRelationRepository) LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string, entityType string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+func (m *mockRelationRepository) LookupAccessibleEntitiesComplex(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
 	if m.lookupAccessibleEntitiesComplexFunc != nil {
-		return m.lookupAccessibleEntitiesComplexFunc(ctx, tenantID, entityType, relations, parentRelations, subjectType, subjectID, maxDepth, cursor, limit)
+		return m.lookupAccessibleEntitiesComplexFunc(ctx, tenantID, entityType, scope, relations, parentRelations, subjectType, subjectID, maxDepth, cursor, limit)
 	}
 	return nil, nil
 }
@@ -266,18 +276,26 @@ func (m *mockAttributeRepository) GetValue(ctx context.Context, tenantID string,
 	return m.attributes[key][attrName], nil
 }
 
+func (m *mockAttributeRepository) DeleteByFilter(ctx context.Context, tenantID string, filter *repositories.AttributeFilter) error {
+	return nil
+}
+
+func (m *mockAttributeRepository) DeleteByFilterInTx(ctx context.Context, tx *sql.Tx, tenantID string, filter *repositories.AttributeFilter) error {
+	return nil
+}
+
 func (m *mockAttributeRepository) WriteInTx(ctx context.Context, tx *sql.Tx, tenantID string, attr *entities.Attribute) error {
 	return m.Write(ctx, tenantID, attr)
 }
 
-func (m *mockAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, cursor string, limit int) ([]string, error) {
+func (m *mockAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenantID string, entityType string, scope []string, cursor string, limit int) ([]string, error) {
 	seen := make(map[string]bool)
 	var ids []string
 	for key := range m.attributes {
 		parts := splitKey(key)
 		if len(parts) == 2 && parts[0] == entityType {
 			id := parts[1]
-			if !seen[id] && (cursor == "" || id > cursor) {
+			if !seen[id] && (cursor == "" || id > cursor) && inScope(scope, id) {
 				seen[id] = true
 				ids = append(ids, id)
 			}
@@ -290,6 +308,19 @@ func (m *mockAttributeRepository) GetSortedEntityIDs(ctx context.Context, tenant
 	return ids, nil
 }
 
+// inScope reports whether id is allowed by scope (an empty scope allows everything)
+func inScope(scope []string, id string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, s := range scope {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
 func splitKey(key string) []string {
 	for i := 0; i < len(key); i++ {
 		if key[i] == ':' {
@@ -1113,6 +1144,167 @@ func TestEvaluator_RuleCallRule_TwoParamsNonStandard(t *testing.T) {
 	}
 }
 
+func TestEvaluator_RuleCallRule_RequestArguments(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Rules: []*entities.RuleDefinition{
+			{
+				Name:       "check_age",
+				Parameters: []string{"subject", "min_age"},
+				Body:       "subject.age >= min_age",
+			},
+		},
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "document",
+				AttributeSchemas: []*entities.AttributeSchema{
+					{Name: "min_age", Type: "integer"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.RuleCallRule{
+							RuleName:  "check_age",
+							Arguments: []string{"subject", "min_age"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "user", EntityID: "alice", Name: "age", Value: int64(20),
+	})
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "document", EntityID: "doc1", Name: "min_age", Value: int64(30),
+	})
+
+	evaluator := NewEvaluator(&mockSchemaRepository{schema}, relationRepo, attributeRepo, celEngine)
+	rule := schema.Entities[1].Permissions[0].Rule
+
+	tests := []struct {
+		name      string
+		arguments []interface{}
+		expected  bool
+		wantErr   bool
+	}{
+		{"argument satisfies rule", []interface{}{nil, float64(18)}, true, false},
+		{"argument fails rule", []interface{}{nil, float64(21)}, false, false},
+		{"no arguments falls back to stored attribute", nil, false, false},
+		{"wrong argument type", []interface{}{nil, "eighteen"}, false, true},
+		{"non-integral number for integer", []interface{}{nil, 18.5}, false, true},
+		{"argument for context-bound parameter", []interface{}{"alice", float64(18)}, false, true},
+		{"too many arguments", []interface{}{nil, float64(18), true}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &EvaluationRequest{
+				TenantID: "test-tenant", EntityType: "document", EntityID: "doc1",
+				SubjectType: "user", SubjectID: "alice",
+				Arguments: tt.arguments,
+			}
+			result, err := evaluator.EvaluateRule(context.Background(), req, rule)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRuleArgument) {
+					t.Fatalf("expected ErrInvalidRuleArgument, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestEvaluator_RuleCallRule_ArgumentsNotBoundToParentRules(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Rules: []*entities.RuleDefinition{
+			{Name: "check_age", Parameters: []string{"subject", "min_age"}, Body: "subject.age >= min_age"},
+			{Name: "is_open", Parameters: []string{"open"}, Body: "open"},
+		},
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name:             "folder",
+				AttributeSchemas: []*entities.AttributeSchema{{Name: "min_age", Type: "integer"}, {Name: "open", Type: "boolean"}},
+				Permissions: []*entities.Permission{
+					{Name: "view", Rule: &entities.RuleCallRule{RuleName: "check_age", Arguments: []string{"subject", "min_age"}}},
+					{Name: "browse", Rule: &entities.RuleCallRule{RuleName: "is_open", Arguments: []string{"open"}}},
+				},
+			},
+			{
+				Name:             "document",
+				Relations:        []*entities.Relation{{Name: "parent", TargetType: "folder"}},
+				AttributeSchemas: []*entities.AttributeSchema{{Name: "min_age", Type: "integer"}},
+				Permissions: []*entities.Permission{
+					{Name: "view", Rule: &entities.LogicalRule{
+						Operator: "and",
+						Left:     &entities.RuleCallRule{RuleName: "check_age", Arguments: []string{"subject", "min_age"}},
+						Right:    &entities.HierarchicalRule{Relation: "parent", Permission: "view"},
+					}},
+					{Name: "browse", Rule: &entities.LogicalRule{
+						Operator: "and",
+						Left:     &entities.RuleCallRule{RuleName: "check_age", Arguments: []string{"subject", "min_age"}},
+						Right:    &entities.HierarchicalRule{Relation: "parent", Permission: "browse"},
+					}},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "parent", SubjectType: "folder", SubjectID: "folder1"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	ctx := context.Background()
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "user", EntityID: "alice", Name: "age", Value: int64(20)})
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "document", EntityID: "doc1", Name: "min_age", Value: int64(30)})
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "folder", EntityID: "folder1", Name: "min_age", Value: int64(30)})
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "folder", EntityID: "folder1", Name: "open", Value: true})
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(&mockSchemaRepository{schema}, relationRepo, attributeRepo, celEngine)
+
+	newReq := func() *EvaluationRequest {
+		return &EvaluationRequest{
+			TenantID: "test-tenant", EntityType: "document", EntityID: "doc1",
+			SubjectType: "user", SubjectID: "alice",
+			Arguments: []interface{}{nil, float64(18)},
+		}
+	}
+
+	// The argument satisfies the document's rule, but the folder's rule keeps its stored min_age
+	result, err := evaluator.EvaluateRule(ctx, newReq(), schema.Entities[2].Permissions[0].Rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result {
+		t.Error("expected the parent's rule to use its stored attribute, not the request argument")
+	}
+
+	// The parent's rule takes one parameter; the request's two arguments must not be bound to it
+	result, err = evaluator.EvaluateRule(ctx, newReq(), schema.Entities[2].Permissions[1].Rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected the parent's rule to be evaluated with its stored attribute")
+	}
+}
+
 func TestEvaluator_PermissionReferencesPermission(t *testing.T) {
 	schema := &entities.Schema{
 		TenantID: "test-tenant",
@@ -1869,3 +2061,206 @@ func TestEvaluateRelation_PermissionCompositionChain(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluator_Trace(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "editor", SubjectType: "user", SubjectID: "bob"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "document",
+		EntityID:   "doc1",
+		Name:       "public",
+		Value:      false,
+	})
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(&mockSchemaRepository{schema}, relationRepo, attributeRepo, celEngine)
+
+	rule := &entities.LogicalRule{
+		Operator: "or",
+		Left:     &entities.ABACRule{Expression: "resource.public == true"},
+		Right: &entities.LogicalRule{
+			Operator: "or",
+			Left:     &entities.RelationRule{Relation: "owner"},
+			Right:    &entities.RelationRule{Relation: "editor"},
+		},
+	}
+
+	trace := &Trace{}
+	result, err := evaluator.EvaluateRule(context.Background(), &EvaluationRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		SubjectType: "user",
+		SubjectID:   "bob",
+		Trace:       trace,
+	}, rule)
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if !result {
+		t.Fatal("expected allowed")
+	}
+
+	want := []struct {
+		level  int
+		typ    string
+		rule   string
+		result bool
+	}{
+		{0, TraceTypeLogical, "or", true},
+		{1, TraceTypeABAC, "resource.public == true", false},
+		{1, TraceTypeLogical, "or", true},
+		{2, TraceTypeRelation, "owner", false},
+		{2, TraceTypeRelation, "editor", true},
+	}
+	if len(trace.Nodes) != len(want) {
+		t.Fatalf("got %d trace nodes, want %d", len(trace.Nodes), len(want))
+	}
+	for i, w := range want {
+		node := trace.Nodes[i]
+		if node.Level != w.level || node.Type != w.typ || node.Rule != w.rule || node.Result != w.result {
+			t.Errorf("node[%d] = {%d %s %q %v}, want {%d %s %q %v}",
+				i, node.Level, node.Type, node.Rule, node.Result, w.level, w.typ, w.rule, w.result)
+		}
+		if node.Entity != "document:doc1" || node.Subject != "user:bob" {
+			t.Errorf("node[%d] entity/subject = %s/%s", i, node.Entity, node.Subject)
+		}
+	}
+
+	abac := trace.Nodes[1]
+	resource, ok := abac.Values["resource"].(map[string]interface{})
+	if !ok || resource["public"] != false {
+		t.Errorf("expected ABAC node to record resource attributes, got %v", abac.Values)
+	}
+
+	editor := trace.Nodes[4]
+	if len(editor.Matched) != 1 || editor.Matched[0] != "document:doc1#editor@user:bob" {
+		t.Errorf("expected editor node to record matched tuple, got %v", editor.Matched)
+	}
+}
+
+func TestEvaluator_Stats_ABAC(t *testing.T) {
+	schema := createTestSchema()
+	attributeRepo := newMockAttributeRepository()
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "document",
+		EntityID:   "doc1",
+		Name:       "public",
+		Value:      true,
+	})
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(&mockSchemaRepository{schema}, &mockRelationRepository{}, attributeRepo, celEngine)
+
+	stats := &EvaluationStats{}
+	_, err := evaluator.EvaluateRule(context.Background(), &EvaluationRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		SubjectType: "user",
+		SubjectID:   "alice",
+		Stats:       stats,
+	}, &entities.ABACRule{Expression: "resource.public == true"})
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+
+	// One node, resource + subject attribute reads, one CEL evaluation
+	if stats.CheckCount != 1 || stats.QueryCount != 2 || stats.CELEvaluations != 1 {
+		t.Errorf("stats = %+v, want {1 2 1}", *stats)
+	}
+}
+
+// createFolderChainSchema returns a schema where folder view is granted by the
+// owner of any ancestor, evaluated recursively one level per parent hop.
+func createFolderChainSchema() *entities.Schema {
+	return &entities.Schema{
+		TenantID: "test-tenant",
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "folder",
+				Relations: []*entities.Relation{
+					{Name: "owner", TargetType: "user"},
+					{Name: "parent", TargetType: "folder"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.LogicalRule{
+							Operator: "or",
+							Left:     &entities.RelationRule{Relation: "owner"},
+							Right:    &entities.HierarchicalRule{Relation: "parent", Permission: "view"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEvaluator_DepthLimit(t *testing.T) {
+	schema := createFolderChainSchema()
+
+	// f0 -> f1 -> ... -> f5, alice owns f5
+	var tuples []*entities.RelationTuple
+	for i := 0; i < 5; i++ {
+		tuples = append(tuples, &entities.RelationTuple{
+			EntityType: "folder", EntityID: fmt.Sprintf("f%d", i), Relation: "parent",
+			SubjectType: "folder", SubjectID: fmt.Sprintf("f%d", i+1),
+		})
+	}
+	tuples = append(tuples, &entities.RelationTuple{
+		EntityType: "folder", EntityID: "f5", Relation: "owner", SubjectType: "user", SubjectID: "alice",
+	})
+
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(&mockSchemaRepository{schema}, &mockRelationRepository{tuples: tuples},
+		newMockAttributeRepository(), celEngine)
+	viewRule := schema.GetEntity("folder").GetPermission("view").Rule
+
+	newReq := func(limit int) *EvaluationRequest {
+		return &EvaluationRequest{
+			TenantID:    "test-tenant",
+			EntityType:  "folder",
+			EntityID:    "f0",
+			SubjectType: "user",
+			SubjectID:   "alice",
+			DepthLimit:  limit,
+		}
+	}
+
+	allowed, err := evaluator.EvaluateRule(context.Background(), newReq(0), viewRule)
+	if err != nil {
+		t.Fatalf("default limit: unexpected error: %v", err)
+	}
+	if !allowed {
+		t.Error("default limit: expected allowed")
+	}
+
+	_, err = evaluator.EvaluateRule(context.Background(), newReq(3), viewRule)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("limit 3: expected ErrMaxDepthExceeded, got %v", err)
+	}
+}
+
+func TestResolveDepthLimit(t *testing.T) {
+	tests := []struct {
+		requested int
+		want      int
+	}{
+		{0, DefaultDepth},
+		{-1, DefaultDepth},
+		{10, 10},
+		{MaxDepth, MaxDepth},
+		{MaxDepth + 1, MaxDepth},
+	}
+	for _, tt := range tests {
+		if got := resolveDepthLimit(tt.requested); got != tt.want {
+			t.Errorf("resolveDepthLimit(%d) = %d, want %d", tt.requested, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,11 @@ type ExpandNode struct {
 	Relation string        // Relation/permission name
 	Subject  string        // Subject reference (e.g., "user:alice"), only for leaf nodes
 	Children []*ExpandNode // Child nodes for logical operations
+
+	// Condition leaves (ABAC rules and rule calls) carry the values the
+	// condition was evaluated with instead of a subject
+	Values      map[string]interface{} // Attributes and rule parameters, keyed by CEL variable name
+	Satisfiable bool                   // Whether the condition holds for Values
 }
 
 // ExpanderInterface defines the interface for permission expansion
@@ -26,17 +31,30 @@ type ExpanderInterface interface {
 type Expander struct {
 	schemaService SchemaServiceInterface
 	relationRepo  repositories.RelationRepository
+	attributeRepo repositories.AttributeRepository
+	celEngine     *CELEngine
 }
 
 // ExpandRequest contains the parameters for expanding a permission tree
 type ExpandRequest struct {
-	TenantID         string                    // Tenant ID
-	SchemaVersion    string                    // Schema version (empty = latest)
-	EntityType       string                    // Resource entity type (e.g., "document")
-	EntityID         string                    // Resource entity ID (e.g., "doc1")
-	Permission       string                    // Permission to expand (e.g., "view")
-	ContextualTuples []*entities.RelationTuple // Temporary tuples for this request
-	SnapshotToken    string                    // Snapshot token for consistency
+	TenantID             string                    // Tenant ID
+	SchemaVersion        string                    // Schema version (empty = latest)
+	EntityType           string                    // Resource entity type (e.g., "document")
+	EntityID             string                    // Resource entity ID (e.g., "doc1")
+	Permission           string                    // Permission to expand (e.g., "view")
+	ContextualTuples     []*entities.RelationTuple // Temporary tuples for this request
+	ContextualAttributes []*entities.Attribute     // Temporary attributes for this request
+	SnapshotToken        string                    // Snapshot token for consistency
+	Arguments            []interface{}             // Positional arguments for rule calls
+	DepthLimit           int                       // Recursion limit from metadata.depth (0 = DefaultDepth)
+}
+
+// withoutArguments returns a copy of r for expanding another permission or entity.
+// Arguments are bound only to the rule calls of the requested permission.
+func (r *ExpandRequest) withoutArguments() *ExpandRequest {
+	nested := *r
+	nested.Arguments = nil
+	return &nested
 }
 
 // ExpandResponse contains the resulting permission tree
@@ -48,10 +66,14 @@ type ExpandResponse struct {
 func NewExpander(
 	schemaService SchemaServiceInterface,
 	relationRepo repositories.RelationRepository,
+	attributeRepo repositories.AttributeRepository,
+	celEngine *CELEngine,
 ) *Expander {
 	return &Expander{
 		schemaService: schemaService,
 		relationRepo:  relationRepo,
+		attributeRepo: attributeRepo,
+		celEngine:     celEngine,
 	}
 }
 
@@ -91,7 +113,7 @@ func (e *Expander) Expand(ctx context.Context, req *ExpandRequest) (*ExpandRespo
 
 	// Build the tree
 	entityRef := fmt.Sprintf("%s:%s", req.EntityType, req.EntityID)
-	tree, err := e.expandRule(ctx, req.TenantID, schema, entityRef, permission.Rule, 0, req.ContextualTuples)
+	tree, err := e.expandRule(ctx, req, schema, entityRef, permission.Rule, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand permission: %w", err)
 	}
@@ -104,53 +126,35 @@ func (e *Expander) Expand(ctx context.Context, req *ExpandRequest) (*ExpandRespo
 // expandRule builds a tree node for the given rule
 func (e *Expander) expandRule(
 	ctx context.Context,
-	tenantID string,
+	req *ExpandRequest,
 	schema *entities.Schema,
 	entityRef string,
 	rule entities.PermissionRule,
 	depth int,
-	contextualTuples []*entities.RelationTuple,
 ) (*ExpandNode, error) {
 	// Check depth limit
-	if depth >= MaxDepth {
-		return nil, fmt.Errorf("maximum recursion depth exceeded (depth: %d)", depth)
+	if limit := resolveDepthLimit(req.DepthLimit); depth >= limit {
+		return nil, fmt.Errorf("%w (depth limit: %d)", ErrMaxDepthExceeded, limit)
 	}
 
 	switch r := rule.(type) {
 	case *entities.RelationRule:
-		return e.expandRelation(ctx, tenantID, schema, entityRef, r, depth, contextualTuples)
+		return e.expandRelation(ctx, req, schema, entityRef, r, depth)
 
 	case *entities.LogicalRule:
-		return e.expandLogical(ctx, tenantID, schema, entityRef, r, depth, contextualTuples)
+		return e.expandLogical(ctx, req, schema, entityRef, r, depth)
 
 	case *entities.HierarchicalRule:
-		return e.expandHierarchical(ctx, tenantID, schema, entityRef, r, depth, contextualTuples)
+		return e.expandHierarchical(ctx, req, schema, entityRef, r, depth)
 
 	case *entities.ABACRule:
-		// ABAC rules can't be expanded into a tree since they depend on runtime attributes
-		// Return a leaf node indicating ABAC evaluation
-		return &ExpandNode{
-			Type:     "leaf",
-			Entity:   entityRef,
-			Relation: "abac",
-			Subject:  fmt.Sprintf("expression:%s", r.Expression),
-		}, nil
+		return e.expandABAC(ctx, req, entityRef, r)
 
 	case *entities.RuleCallRule:
-		return &ExpandNode{
-			Type:     "leaf",
-			Entity:   entityRef,
-			Relation: fmt.Sprintf("rule:%s(%v)", r.RuleName, r.Arguments),
-			Subject:  "rule_call",
-		}, nil
+		return e.expandRuleCall(ctx, req, schema, entityRef, r)
 
 	case *entities.HierarchicalRuleCallRule:
-		return &ExpandNode{
-			Type:     "leaf",
-			Entity:   entityRef,
-			Relation: fmt.Sprintf("%s.%s(%s)", r.Relation, r.RuleName, fmt.Sprintf("%v", r.Arguments)),
-			Subject:  "hierarchical_rule_call",
-		}, nil
+		return e.expandHierarchicalRuleCall(ctx, req, schema, entityRef, r)
 
 	default:
 		return nil, fmt.Errorf("unknown rule type: %T", rule)
@@ -161,12 +165,11 @@ func (e *Expander) expandRule(
 // If the relation name refers to another permission, it expands that permission recursively.
 func (e *Expander) expandRelation(
 	ctx context.Context,
-	tenantID string,
+	req *ExpandRequest,
 	schema *entities.Schema,
 	entityRef string,
 	rule *entities.RelationRule,
 	depth int,
-	contextualTuples []*entities.RelationTuple,
 ) (*ExpandNode, error) {
 	// Parse entity reference
 	entityType, entityID, err := parseEntityRef(entityRef)
@@ -180,7 +183,7 @@ func (e *Expander) expandRelation(
 		isRelation := entity.GetRelation(rule.Relation) != nil
 		if !isRelation {
 			if perm := entity.GetPermission(rule.Relation); perm != nil {
-				return e.expandRule(ctx, tenantID, schema, entityRef, perm.Rule, depth+1, contextualTuples)
+				return e.expandRule(ctx, req.withoutArguments(), schema, entityRef, perm.Rule, depth+1)
 			}
 		}
 	}
@@ -192,13 +195,13 @@ func (e *Expander) expandRelation(
 		Relation:   rule.Relation,
 	}
 
-	tuples, err := e.relationRepo.Read(ctx, tenantID, filter)
+	tuples, err := e.relationRepo.Read(ctx, req.TenantID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read relations: %w", err)
 	}
 
 	// Add matching contextual tuples
-	for _, ct := range contextualTuples {
+	for _, ct := range req.ContextualTuples {
 		if ct.EntityType == entityType &&
 			ct.EntityID == entityID &&
 			ct.Relation == rule.Relation {
@@ -233,12 +236,11 @@ func (e *Expander) expandRelation(
 // expandLogical expands a logical operation (OR/AND/NOT)
 func (e *Expander) expandLogical(
 	ctx context.Context,
-	tenantID string,
+	req *ExpandRequest,
 	schema *entities.Schema,
 	entityRef string,
 	rule *entities.LogicalRule,
 	depth int,
-	contextualTuples []*entities.RelationTuple,
 ) (*ExpandNode, error) {
 	var nodeType string
 	switch rule.Operator {
@@ -260,7 +262,7 @@ func (e *Expander) expandLogical(
 	}
 
 	// Expand left side
-	leftNode, err := e.expandRule(ctx, tenantID, schema, entityRef, rule.Left, depth+1, contextualTuples)
+	leftNode, err := e.expandRule(ctx, req, schema, entityRef, rule.Left, depth+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand left side of %s: %w", rule.Operator, err)
 	}
@@ -268,7 +270,7 @@ func (e *Expander) expandLogical(
 
 	// Expand right side (if exists)
 	if rule.Right != nil {
-		rightNode, err := e.expandRule(ctx, tenantID, schema, entityRef, rule.Right, depth+1, contextualTuples)
+		rightNode, err := e.expandRule(ctx, req, schema, entityRef, rule.Right, depth+1)
 		if err != nil {
 			return nil, fmt.Errorf("failed to expand right side of %s: %w", rule.Operator, err)
 		}
@@ -281,12 +283,11 @@ func (e *Expander) expandLogical(
 // expandHierarchical expands a hierarchical permission (e.g., parent.view)
 func (e *Expander) expandHierarchical(
 	ctx context.Context,
-	tenantID string,
+	req *ExpandRequest,
 	schema *entities.Schema,
 	entityRef string,
 	rule *entities.HierarchicalRule,
 	depth int,
-	contextualTuples []*entities.RelationTuple,
 ) (*ExpandNode, error) {
 	// Parse entity reference
 	entityType, entityID, err := parseEntityRef(entityRef)
@@ -301,13 +302,13 @@ func (e *Expander) expandHierarchical(
 		Relation:   rule.Relation,
 	}
 
-	tuples, err := e.relationRepo.Read(ctx, tenantID, filter)
+	tuples, err := e.relationRepo.Read(ctx, req.TenantID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read relations: %w", err)
 	}
 
 	// Add matching contextual tuples
-	for _, ct := range contextualTuples {
+	for _, ct := range req.ContextualTuples {
 		if ct.EntityType == entityType &&
 			ct.EntityID == entityID &&
 			ct.Relation == rule.Relation {
@@ -334,7 +335,7 @@ func (e *Expander) expandHierarchical(
 		parentPermission := schema.GetPermission(tuple.SubjectType, rule.Permission)
 		if parentPermission != nil {
 			// Recursively expand the parent permission
-			parentNode, err := e.expandRule(ctx, tenantID, schema, parentRef, parentPermission.Rule, depth+1, contextualTuples)
+			parentNode, err := e.expandRule(ctx, req.withoutArguments(), schema, parentRef, parentPermission.Rule, depth+1)
 			if err != nil {
 				return nil, fmt.Errorf("failed to expand hierarchical permission: %w", err)
 			}
@@ -345,8 +346,8 @@ func (e *Expander) expandHierarchical(
 		// If not a permission, check if it's a relation on the parent entity
 		parentEntity := schema.GetEntity(tuple.SubjectType)
 		if parentEntity != nil && parentEntity.GetRelation(rule.Permission) != nil {
-			relationNode, err := e.expandRelation(ctx, tenantID, schema, parentRef,
-				&entities.RelationRule{Relation: rule.Permission}, depth+1, contextualTuples)
+			relationNode, err := e.expandRelation(ctx, req.withoutArguments(), schema, parentRef,
+				&entities.RelationRule{Relation: rule.Permission}, depth+1)
 			if err != nil {
 				return nil, fmt.Errorf("failed to expand hierarchical relation: %w", err)
 			}
@@ -357,6 +358,215 @@ func (e *Expander) expandHierarchical(
 	return node, nil
 }
 
+// expandABAC evaluates an ABAC rule against the entity's attributes and
+// returns a condition leaf with those attributes.
+func (e *Expander) expandABAC(
+	ctx context.Context,
+	req *ExpandRequest,
+	entityRef string,
+	rule *entities.ABACRule,
+) (*ExpandNode, error) {
+	entityType, entityID, err := parseEntityRef(entityRef)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceAttrs, err := e.readAttributes(ctx, req, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource attributes: %w", err)
+	}
+
+	result, err := traceCEL(ctx, rule.Expression, func() (bool, error) {
+		return e.celEngine.Evaluate(rule.Expression, &EvaluationContext{
+			Resource: resourceAttrs,
+			Subject:  map[string]interface{}{},
+			Request:  map[string]interface{}{},
+		})
+	})
+
+	return &ExpandNode{
+		Type:        "leaf",
+		Entity:      entityRef,
+		Relation:    "abac",
+		Values:      map[string]interface{}{"resource": resourceAttrs},
+		Satisfiable: conditionHolds(result, err),
+	}, nil
+}
+
+// expandRuleCall evaluates a rule call against the entity's attributes and the
+// request arguments, and returns a condition leaf with the bound parameters.
+func (e *Expander) expandRuleCall(
+	ctx context.Context,
+	req *ExpandRequest,
+	schema *entities.Schema,
+	entityRef string,
+	rule *entities.RuleCallRule,
+) (*ExpandNode, error) {
+	entityType, entityID, err := parseEntityRef(entityRef)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleDef := schema.GetRule(rule.RuleName)
+	if ruleDef == nil {
+		return nil, fmt.Errorf("rule %s not found", rule.RuleName)
+	}
+	if len(rule.Arguments) != len(ruleDef.Parameters) {
+		return nil, fmt.Errorf("rule %s expects %d arguments, got %d",
+			rule.RuleName, len(ruleDef.Parameters), len(rule.Arguments))
+	}
+
+	resourceAttrs, err := e.readAttributes(ctx, req, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource attributes: %w", err)
+	}
+
+	// Expand has no subject, so subject-bound parameters see no attributes
+	argContexts := map[string]map[string]interface{}{
+		"resource": resourceAttrs,
+		"subject":  {},
+		"request":  {},
+	}
+	paramContexts, paramValues, err := bindRuleParameters(schema, entityType, rule, ruleDef, argContexts, resourceAttrs, req.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(paramContexts)+len(paramValues))
+	for name, v := range paramContexts {
+		values[name] = v
+	}
+	for name, v := range paramValues {
+		values[name] = v
+	}
+
+	result, err := traceCEL(ctx, ruleDef.Body, func() (bool, error) {
+		return e.celEngine.EvaluateRuleWithValues(ruleDef.Body, paramContexts, paramValues)
+	})
+
+	return &ExpandNode{
+		Type:        "leaf",
+		Entity:      entityRef,
+		Relation:    fmt.Sprintf("rule:%s(%v)", rule.RuleName, rule.Arguments),
+		Values:      values,
+		Satisfiable: conditionHolds(result, err),
+	}, nil
+}
+
+// expandHierarchicalRuleCall evaluates a rule call on each parent reached through
+// the relation, returning a union with one condition leaf per parent. Each leaf
+// holds the parent's attributes ("this") and the parameters taken from the
+// current entity.
+func (e *Expander) expandHierarchicalRuleCall(
+	ctx context.Context,
+	req *ExpandRequest,
+	schema *entities.Schema,
+	entityRef string,
+	rule *entities.HierarchicalRuleCallRule,
+) (*ExpandNode, error) {
+	entityType, entityID, err := parseEntityRef(entityRef)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &repositories.RelationFilter{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Relation:   rule.Relation,
+	}
+	tuples, err := e.relationRepo.Read(ctx, req.TenantID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relations: %w", err)
+	}
+	for _, ct := range req.ContextualTuples {
+		if ct.EntityType == entityType &&
+			ct.EntityID == entityID &&
+			ct.Relation == rule.Relation {
+			tuples = append(tuples, ct)
+		}
+	}
+
+	currentAttrs, err := e.readAttributes(ctx, req, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current entity attributes: %w", err)
+	}
+
+	relation := fmt.Sprintf("%s.%s(%s)", rule.Relation, rule.RuleName, fmt.Sprintf("%v", rule.Arguments))
+	node := &ExpandNode{
+		Type:     "union",
+		Entity:   entityRef,
+		Relation: relation,
+		Children: make([]*ExpandNode, 0, len(tuples)),
+	}
+
+	for _, tuple := range tuples {
+		// Skip subject set tuples - they reference a subject set, not a direct parent entity
+		if tuple.SubjectRelation != "" {
+			continue
+		}
+
+		ruleDef := findParentRule(schema, tuple.SubjectType, rule.RuleName)
+		if ruleDef == nil {
+			return nil, fmt.Errorf("rule %s not found", rule.RuleName)
+		}
+		if len(rule.Arguments) != len(ruleDef.Parameters) {
+			return nil, fmt.Errorf("rule %s expects %d arguments, got %d",
+				rule.RuleName, len(ruleDef.Parameters), len(rule.Arguments))
+		}
+
+		parentAttrs, err := e.readAttributes(ctx, req, tuple.SubjectType, tuple.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent attributes: %w", err)
+		}
+		params := hierarchicalRuleParams(ruleDef, rule.Arguments, currentAttrs)
+
+		values := make(map[string]interface{}, len(params)+1)
+		for name, v := range params {
+			values[name] = v
+		}
+		values["this"] = parentAttrs
+
+		result, err := traceCEL(ctx, ruleDef.Body, func() (bool, error) {
+			return e.celEngine.EvaluateWithParams(ruleDef.Body, parentAttrs, params)
+		})
+
+		node.Children = append(node.Children, &ExpandNode{
+			Type:        "leaf",
+			Entity:      fmt.Sprintf("%s:%s", tuple.SubjectType, tuple.SubjectID),
+			Relation:    relation,
+			Values:      values,
+			Satisfiable: conditionHolds(result, err),
+		})
+	}
+
+	return node, nil
+}
+
+// readAttributes reads the attributes of an entity, with contextual attributes
+// from the request taking precedence.
+func (e *Expander) readAttributes(ctx context.Context, req *ExpandRequest, entityType, entityID string) (map[string]interface{}, error) {
+	attrs, err := e.attributeRepo.Read(ctx, req.TenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+	attrs = normalizeJSONNumbers(attrs)
+	if len(req.ContextualAttributes) > 0 {
+		attrs = mergeContextualAttributes(attrs, req.ContextualAttributes, entityType, entityID)
+	}
+	return attrs, nil
+}
+
+// conditionHolds reports whether a condition evaluated to true. Expand has no
+// subject, so a condition reading subject attributes (or attributes the entity
+// does not have) fails to evaluate; it is reported as not satisfiable instead of
+// failing the whole expansion.
+func conditionHolds(result bool, err error) bool {
+	return err == nil && result
+}
+
 // validateRequest validates the expand request
 func (e *Expander) validateRequest(req *ExpandRequest) error {
 	if req.TenantID == "" {
@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/asakaida/keruberosu/internal/entities"
@@ -30,7 +31,7 @@ func TestExpander_Expand_BasicRelation(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -93,7 +94,7 @@ func TestExpander_Expand_LogicalOR(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -175,7 +176,7 @@ func TestExpander_Expand_LogicalAND(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -234,7 +235,7 @@ func TestExpander_Expand_LogicalNOT(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -312,7 +313,7 @@ func TestExpander_Expand_Hierarchical(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -374,7 +375,11 @@ func TestExpander_Expand_ABAC(t *testing.T) {
 
 	relationRepo := &mockRelationRepository{}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	attributeRepo := newMockAttributeRepository()
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "document", EntityID: "doc1", Name: "public", Value: true,
+	})
+	expander := NewExpander(schemaService, relationRepo, attributeRepo, newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -388,15 +393,19 @@ func TestExpander_Expand_ABAC(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// ABAC rules should return a leaf node with the expression
+	// ABAC rules should return a leaf node with the resource attributes
 	if resp.Tree.Type != "leaf" {
 		t.Errorf("expected leaf node, got %s", resp.Tree.Type)
 	}
 	if resp.Tree.Relation != "abac" {
 		t.Errorf("expected relation 'abac', got %s", resp.Tree.Relation)
 	}
-	if resp.Tree.Subject != "expression:resource.public == true" {
-		t.Errorf("expected subject with expression, got %s", resp.Tree.Subject)
+	resource, ok := resp.Tree.Values["resource"].(map[string]interface{})
+	if !ok || resource["public"] != true {
+		t.Errorf("expected resource attributes in values, got %v", resp.Tree.Values)
+	}
+	if !resp.Tree.Satisfiable {
+		t.Error("expected condition to be satisfiable")
 	}
 }
 
@@ -408,7 +417,7 @@ func TestExpander_Expand_EmptyRelation(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -490,7 +499,7 @@ func TestExpander_Expand_ComplexNested(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -531,7 +540,7 @@ func TestExpander_Expand_ErrorCases(t *testing.T) {
 	schema := createTestSchema()
 	relationRepo := &mockRelationRepository{}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	tests := []struct {
 		name       string
@@ -721,7 +730,7 @@ func TestExpander_Expand_MaxDepth(t *testing.T) {
 
 	relationRepo := &mockRelationRepository{tuples: tuples}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -739,6 +748,60 @@ func TestExpander_Expand_MaxDepth(t *testing.T) {
 	}
 }
 
+func TestExpander_Expand_DepthLimit(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "folder",
+				Relations: []*entities.Relation{
+					{Name: "parent", TargetType: "folder"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.HierarchicalRule{
+							Relation:   "parent",
+							Permission: "view",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// 0 -> 1 -> 2 -> 3 -> 4 (4 has no parent)
+	var tuples []*entities.RelationTuple
+	for i := 0; i < 4; i++ {
+		tuples = append(tuples, &entities.RelationTuple{
+			EntityType:  "folder",
+			EntityID:    formatInt(i),
+			Relation:    "parent",
+			SubjectType: "folder",
+			SubjectID:   formatInt(i + 1),
+		})
+	}
+
+	expander := NewExpander(&mockSchemaRepository{schema}, &mockRelationRepository{tuples: tuples}, newMockAttributeRepository(), newTestCELEngine(t))
+
+	req := &ExpandRequest{
+		TenantID:   "test-tenant",
+		EntityType: "folder",
+		EntityID:   "0",
+		Permission: "view",
+	}
+	if _, err := expander.Expand(context.Background(), req); err != nil {
+		t.Fatalf("default limit: unexpected error: %v", err)
+	}
+
+	req.DepthLimit = 2
+	_, err := expander.Expand(context.Background(), req)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("limit 2: expected ErrMaxDepthExceeded, got %v", err)
+	}
+}
+
 // Helper function to format int as string
 func formatInt(i int) string {
 	return string(rune('0' + i%10))
@@ -813,7 +876,7 @@ func TestExpander_Expand_HierarchicalRelation(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -900,7 +963,7 @@ func TestExpander_Expand_SubjectRelation(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -966,7 +1029,7 @@ func TestExpander_Expand_WithRelationName(t *testing.T) {
 	}
 
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	// Use relation name "owner" instead of permission name "edit"
 	req := &ExpandRequest{
@@ -996,7 +1059,7 @@ func TestExpander_Expand_WithRelationName(t *testing.T) {
 }
 
 // TestExpand_ABACRule verifies that expanding a permission consisting of a pure
-// ABAC rule produces a leaf node with relation "abac".
+// ABAC rule produces a condition leaf with relation "abac".
 func TestExpand_ABACRule(t *testing.T) {
 	schema := &entities.Schema{
 		TenantID: "test-tenant",
@@ -1018,7 +1081,7 @@ func TestExpand_ABACRule(t *testing.T) {
 
 	relationRepo := &mockRelationRepository{}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -1038,14 +1101,16 @@ func TestExpand_ABACRule(t *testing.T) {
 	if resp.Tree.Relation != "abac" {
 		t.Errorf("expected relation 'abac', got %s", resp.Tree.Relation)
 	}
-	expectedSubject := "expression:resource.status == \"active\""
-	if resp.Tree.Subject != expectedSubject {
-		t.Errorf("expected subject %q, got %q", expectedSubject, resp.Tree.Subject)
+	if resp.Tree.Subject != "" {
+		t.Errorf("expected no subject on a condition leaf, got %q", resp.Tree.Subject)
+	}
+	if resp.Tree.Satisfiable {
+		t.Error("expected condition not to be satisfiable without the status attribute")
 	}
 }
 
 // TestExpand_RuleCallRule verifies that expanding a permission with a RuleCallRule
-// produces a leaf node with relation containing the rule name and arguments.
+// produces a condition leaf with relation containing the rule name and arguments.
 func TestExpand_RuleCallRule(t *testing.T) {
 	schema := &entities.Schema{
 		TenantID: "test-tenant",
@@ -1075,7 +1140,7 @@ func TestExpand_RuleCallRule(t *testing.T) {
 
 	relationRepo := &mockRelationRepository{}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -1092,8 +1157,11 @@ func TestExpand_RuleCallRule(t *testing.T) {
 	if resp.Tree.Type != "leaf" {
 		t.Errorf("expected leaf node for RuleCallRule, got %s", resp.Tree.Type)
 	}
-	if resp.Tree.Subject != "rule_call" {
-		t.Errorf("expected subject 'rule_call', got %s", resp.Tree.Subject)
+	if _, ok := resp.Tree.Values["resource"]; !ok {
+		t.Errorf("expected bound resource parameter in values, got %v", resp.Tree.Values)
+	}
+	if resp.Tree.Satisfiable {
+		t.Error("expected condition not to be satisfiable without the public attribute")
 	}
 	if !contains(resp.Tree.Relation, "is_public") {
 		t.Errorf("expected relation to contain 'is_public', got %s", resp.Tree.Relation)
@@ -1132,7 +1200,7 @@ func TestExpand_MixedReBACABAC(t *testing.T) {
 		},
 	}
 	schemaService := &mockSchemaRepository{schema}
-	expander := NewExpander(schemaService, relationRepo)
+	expander := NewExpander(schemaService, relationRepo, newMockAttributeRepository(), newTestCELEngine(t))
 
 	req := &ExpandRequest{
 		TenantID:   "test-tenant",
@@ -1174,7 +1242,221 @@ func TestExpand_MixedReBACABAC(t *testing.T) {
 	if rightChild.Relation != "abac" {
 		t.Errorf("expected right child relation 'abac', got %s", rightChild.Relation)
 	}
-	if rightChild.Subject != "expression:resource.is_public == true" {
-		t.Errorf("expected ABAC subject expression, got %s", rightChild.Subject)
+	if rightChild.Values == nil {
+		t.Error("expected ABAC leaf to carry values")
+	}
+}
+
+// TestExpand_HierarchicalRuleCall verifies that a hierarchical rule call expands
+// into one condition leaf per parent, carrying the parent's attributes as "this"
+// and the parameters taken from the current entity.
+func TestExpand_HierarchicalRuleCall(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Rules: []*entities.RuleDefinition{
+			{
+				Name:       "check_level",
+				Parameters: []string{"level"},
+				Body:       "this.clearance >= level",
+			},
+		},
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{Name: "folder"},
+			{
+				Name: "document",
+				Relations: []*entities.Relation{
+					{Name: "parent", TargetType: "folder"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.HierarchicalRuleCallRule{
+							Relation:  "parent",
+							RuleName:  "check_level",
+							Arguments: []string{"level"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "parent", SubjectType: "folder", SubjectID: "f1"},
+			{EntityType: "document", EntityID: "doc1", Relation: "parent", SubjectType: "folder", SubjectID: "f2"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	ctx := context.Background()
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "document", EntityID: "doc1", Name: "level", Value: int64(3)})
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "folder", EntityID: "f1", Name: "clearance", Value: int64(5)})
+	attributeRepo.Write(ctx, "test-tenant", &entities.Attribute{EntityType: "folder", EntityID: "f2", Name: "clearance", Value: int64(1)})
+
+	expander := NewExpander(&mockSchemaRepository{schema}, relationRepo, attributeRepo, newTestCELEngine(t))
+
+	resp, err := expander.Expand(ctx, &ExpandRequest{
+		TenantID:   "test-tenant",
+		EntityType: "document",
+		EntityID:   "doc1",
+		Permission: "view",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Tree.Type != "union" {
+		t.Fatalf("expected union node, got %s", resp.Tree.Type)
+	}
+	if len(resp.Tree.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(resp.Tree.Children))
+	}
+
+	want := map[string]bool{"folder:f1": true, "folder:f2": false}
+	for _, leaf := range resp.Tree.Children {
+		satisfiable, ok := want[leaf.Entity]
+		if !ok {
+			t.Errorf("unexpected leaf entity %s", leaf.Entity)
+			continue
+		}
+		if leaf.Satisfiable != satisfiable {
+			t.Errorf("%s: expected satisfiable=%v, got %v", leaf.Entity, satisfiable, leaf.Satisfiable)
+		}
+		if leaf.Values["level"] != int64(3) {
+			t.Errorf("%s: expected level parameter 3, got %v", leaf.Entity, leaf.Values["level"])
+		}
+		if _, ok := leaf.Values["this"].(map[string]interface{}); !ok {
+			t.Errorf("%s: expected parent attributes as this, got %v", leaf.Entity, leaf.Values["this"])
+		}
+	}
+}
+
+// TestExpand_RuleCallArguments verifies that request arguments are bound to rule
+// parameters when evaluating a rule-call leaf.
+func TestExpand_RuleCallArguments(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Rules: []*entities.RuleDefinition{
+			{
+				Name:       "is_weekday",
+				Parameters: []string{"day"},
+				Body:       "day != \"saturday\" && day != \"sunday\"",
+			},
+		},
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "document",
+				AttributeSchemas: []*entities.AttributeSchema{
+					{Name: "day", Type: "string"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.RuleCallRule{RuleName: "is_weekday", Arguments: []string{"day"}},
+					},
+				},
+			},
+		},
+	}
+
+	expander := NewExpander(&mockSchemaRepository{schema}, &mockRelationRepository{},
+		newMockAttributeRepository(), newTestCELEngine(t))
+
+	req := &ExpandRequest{
+		TenantID:   "test-tenant",
+		EntityType: "document",
+		EntityID:   "doc1",
+		Permission: "view",
+		Arguments:  []interface{}{"monday"},
+	}
+	resp, err := expander.Expand(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Tree.Values["day"] != "monday" {
+		t.Errorf("expected day parameter 'monday', got %v", resp.Tree.Values["day"])
+	}
+	if !resp.Tree.Satisfiable {
+		t.Error("expected condition to be satisfiable")
+	}
+
+	req.Arguments = []interface{}{float64(1)}
+	_, err = expander.Expand(context.Background(), req)
+	if !errors.Is(err, ErrInvalidRuleArgument) {
+		t.Errorf("expected ErrInvalidRuleArgument, got %v", err)
+	}
+}
+
+// TestExpand_RuleCallArgumentsNotBoundToParentRules verifies that request
+// arguments are bound only to the rule calls of the requested permission.
+func TestExpand_RuleCallArgumentsNotBoundToParentRules(t *testing.T) {
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Rules: []*entities.RuleDefinition{
+			{Name: "is_weekday", Parameters: []string{"day"}, Body: "day != \"saturday\" && day != \"sunday\""},
+			{Name: "is_open", Parameters: []string{"open"}, Body: "open"},
+		},
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name:             "folder",
+				AttributeSchemas: []*entities.AttributeSchema{{Name: "open", Type: "boolean"}},
+				Permissions: []*entities.Permission{
+					{Name: "view", Rule: &entities.RuleCallRule{RuleName: "is_open", Arguments: []string{"open"}}},
+				},
+			},
+			{
+				Name:             "document",
+				Relations:        []*entities.Relation{{Name: "parent", TargetType: "folder"}},
+				AttributeSchemas: []*entities.AttributeSchema{{Name: "day", Type: "string"}},
+				Permissions: []*entities.Permission{
+					{Name: "view", Rule: &entities.LogicalRule{
+						Operator: "and",
+						Left:     &entities.RuleCallRule{RuleName: "is_weekday", Arguments: []string{"day"}},
+						Right:    &entities.HierarchicalRule{Relation: "parent", Permission: "view"},
+					}},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "parent", SubjectType: "folder", SubjectID: "folder1"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "folder", EntityID: "folder1", Name: "open", Value: true,
+	})
+	expander := NewExpander(&mockSchemaRepository{schema}, relationRepo, attributeRepo, newTestCELEngine(t))
+
+	resp, err := expander.Expand(context.Background(), &ExpandRequest{
+		TenantID:   "test-tenant",
+		EntityType: "document",
+		EntityID:   "doc1",
+		Permission: "view",
+		Arguments:  []interface{}{"monday"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Tree.Children[0].Values["day"] != "monday" {
+		t.Errorf("expected day parameter 'monday', got %v", resp.Tree.Children[0].Values["day"])
+	}
+	parentLeaf := resp.Tree.Children[1].Children[0]
+	if parentLeaf.Values["open"] != true {
+		t.Errorf("expected the parent's rule to use its stored attribute, got %v", parentLeaf.Values["open"])
+	}
+}
+
+func newTestCELEngine(t *testing.T) *CELEngine {
+	t.Helper()
+	celEngine, err := NewCELEngine()
+	if err != nil {
+		t.Fatalf("failed to create CEL engine: %v", err)
 	}
+	return celEngine
 }
@@ -2,8 +2,9 @@ package authorization
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"sort"
 
 	"github.com/asakaida/keruberosu/internal/entities"
@@ -19,6 +20,7 @@ const (
 // LookupInterface defines the interface for entity and subject lookup
 type LookupInterface interface {
 	LookupEntity(ctx context.Context, req *LookupEntityRequest) (*LookupEntityResponse, error)
+	LookupEntityStream(ctx context.Context, req *LookupEntityRequest, send LookupEntityStreamFunc) (*LookupEntityStreamResponse, error)
 	LookupSubject(ctx context.Context, req *LookupSubjectRequest) (*LookupSubjectResponse, error)
 }
 
@@ -44,14 +46,31 @@ type LookupEntityRequest struct {
 	SnapshotToken        string
 	PageSize             int
 	PageToken            string
+	DepthLimit           int // Recursion limit from metadata.depth (0 = DefaultDepth)
+	// Scope optionally restricts candidates per entity type (entity type -> IDs).
+	// Only the entry for EntityType is applied; an empty entry means no restriction.
+	Scope map[string][]string
 }
 
 // LookupEntityResponse contains the list of entities
 type LookupEntityResponse struct {
 	EntityIDs     []string
 	NextPageToken string
+	SchemaVersion string // Schema version the lookup was evaluated against
+	SnapshotToken string // Snapshot token the result is valid at (empty if none was resolved)
 }
 
+// LookupEntityStreamResponse describes what a completed LookupEntityStream was evaluated at
+type LookupEntityStreamResponse struct {
+	SchemaVersion string // Schema version the lookup was evaluated against
+	SnapshotToken string // Snapshot token the result is valid at (empty if none was resolved)
+}
+
+// LookupEntityStreamFunc receives each verified entity ID together with a
+// continuous token that resumes the lookup right after that entity.
+// Returning an error stops the stream.
+type LookupEntityStreamFunc func(entityID string, continuousToken string) error
+
 // LookupSubjectRequest contains the parameters for looking up subjects
 type LookupSubjectRequest struct {
 	TenantID             string
@@ -66,12 +85,15 @@ type LookupSubjectRequest struct {
 	SnapshotToken        string
 	PageSize             int
 	PageToken            string
+	DepthLimit           int // Recursion limit from metadata.depth (0 = DefaultDepth)
 }
 
 // LookupSubjectResponse contains the list of subjects
 type LookupSubjectResponse struct {
 	SubjectIDs    []string
 	NextPageToken string
+	SchemaVersion string // Schema version the lookup was evaluated against
+	SnapshotToken string // Snapshot token the result is valid at (empty if none was resolved)
 }
 
 // NewLookup creates a new Lookup.
@@ -102,14 +124,84 @@ func (l *Lookup) LookupEntity(ctx context.Context, req *LookupEntityRequest) (*L
 		return nil, fmt.Errorf("invalid lookup entity request: %w", err)
 	}
 
+	limit := req.PageSize
+	if limit <= 0 {
+		limit = defaultLookupLimit
+	}
+
+	// lookupEntityPage pins the schema version and snapshot token on the
+	// request it is given; keep the caller's request untouched
+	pageReq := *req
+	var entityIDs []string
+	nextPageToken, err := l.lookupEntityPage(ctx, &pageReq, limit, func(entityID string) error {
+		entityIDs = append(entityIDs, entityID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LookupEntityResponse{
+		EntityIDs:     entityIDs,
+		NextPageToken: nextPageToken,
+		SchemaVersion: pageReq.SchemaVersion,
+		SnapshotToken: pageReq.SnapshotToken,
+	}, nil
+}
+
+// LookupEntityStream finds all entities of a given type that a subject has
+// permission on and hands each one to send as soon as it is verified.
+// Unlike LookupEntity it is not bounded by PageSize: it keeps fetching
+// candidate batches until the result set is exhausted. PageToken resumes the
+// stream from a previously received continuous token. send is called
+// synchronously, so a slow consumer throttles candidate verification rather
+// than causing results to accumulate in memory.
+func (l *Lookup) LookupEntityStream(ctx context.Context, req *LookupEntityRequest, send LookupEntityStreamFunc) (*LookupEntityStreamResponse, error) {
+	if err := l.validateLookupEntityRequest(req); err != nil {
+		return nil, fmt.Errorf("invalid lookup entity request: %w", err)
+	}
+
+	batchSize := req.PageSize
+	if batchSize <= 0 {
+		batchSize = defaultLookupLimit
+	}
+
+	pageReq := *req
+	for {
+		nextPageToken, err := l.lookupEntityPage(ctx, &pageReq, batchSize, func(entityID string) error {
+			// Entity IDs are returned in ascending order and page tokens are
+			// exclusive cursors, so the ID itself resumes right after this item.
+			return send(entityID, entityID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if nextPageToken == "" {
+			return &LookupEntityStreamResponse{
+				SchemaVersion: pageReq.SchemaVersion,
+				SnapshotToken: pageReq.SnapshotToken,
+			}, nil
+		}
+		pageReq.PageToken = nextPageToken
+	}
+}
+
+// lookupEntityPage resolves the permission and emits up to limit verified
+// entity IDs after req.PageToken in ascending order. It returns the token for
+// the next page, or an empty string when no more candidates remain.
+// req.SchemaVersion and req.SnapshotToken are pinned to the values resolved
+// for this page, so a caller reusing req for the next page sees the same
+// schema and snapshot.
+func (l *Lookup) lookupEntityPage(ctx context.Context, req *LookupEntityRequest, limit int, emit func(entityID string) error) (string, error) {
 	schema, err := l.schemaService.GetSchemaEntity(ctx, req.TenantID, req.SchemaVersion)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get schema: %w", err)
+		return "", fmt.Errorf("failed to get schema: %w", err)
 	}
+	req.SchemaVersion = schema.Version
 
 	entity := schema.GetEntity(req.EntityType)
 	if entity == nil {
-		return nil, fmt.Errorf("entity type %s not found in schema", req.EntityType)
+		return "", fmt.Errorf("entity type %s not found in schema", req.EntityType)
 	}
 
 	permission := entity.GetPermission(req.Permission)
@@ -121,15 +213,10 @@ func (l *Lookup) LookupEntity(ctx context.Context, req *LookupEntityRequest) (*L
 				Rule: &entities.RelationRule{Relation: req.Permission},
 			}
 		} else {
-			return nil, fmt.Errorf("permission %s not found in entity %s", req.Permission, req.EntityType)
+			return "", fmt.Errorf("permission %s not found in entity %s", req.Permission, req.EntityType)
 		}
 	}
 
-	limit := req.PageSize
-	if limit <= 0 {
-		limit = defaultLookupLimit
-	}
-
 	// Extract relations with schema context for hierarchical expansion
 	visited := make(map[string]bool)
 	relations, parentRelations, hasUnresolvable := extractRelationsFromRuleWithContext(
@@ -150,26 +237,28 @@ func (l *Lookup) LookupEntity(ctx context.Context, req *LookupEntityRequest) (*L
 		// correctness (the SQL approximation may over-include in edge cases).
 		entityIDs, err := l.relationRepo.LookupAccessibleEntitiesComplex(
 			ctx, req.TenantID,
-			req.EntityType, relations, parentRelations,
+			req.EntityType, req.Scope[req.EntityType], relations, parentRelations,
 			req.SubjectType, req.SubjectID,
-			MaxDepth, req.PageToken, limit+1)
+			resolveDepthLimit(req.DepthLimit), req.PageToken, limit+1)
 		if err != nil {
-			return nil, fmt.Errorf("failed to lookup accessible entities: %w", err)
+			return "", fmt.Errorf("failed to lookup accessible entities: %w", err)
 		}
 
 		if len(req.ContextualTuples) > 0 {
-			ctxIDs := extractEntityIDsFromContextualTuples(req.ContextualTuples, req.EntityType)
+			ctxIDs := filterIDsInScope(
+				extractEntityIDsFromContextualTuples(req.ContextualTuples, req.EntityType),
+				req.Scope[req.EntityType])
 			if req.PageToken != "" {
 				ctxIDs = filterIDsAfterCursor(ctxIDs, req.PageToken)
 			}
 			entityIDs = mergeSortedUnique(entityIDs, ctxIDs, len(entityIDs)+len(ctxIDs))
 		}
 
-		return l.verifyEntitiesAndPaginate(ctx, req, entityIDs, limit)
+		return l.verifyEntitiesAndPaginate(ctx, req, entityIDs, limit, emit)
 	}
 
 	// Fallback path: batched sorted entity IDs + Check loop
-	return l.lookupEntityFallback(ctx, req, limit)
+	return l.lookupEntityFallback(ctx, req, limit, emit)
 }
 
 // LookupSubject finds all subjects of a given type that have permission on an entity.
@@ -184,6 +273,12 @@ func (l *Lookup) LookupSubject(ctx context.Context, req *LookupSubjectRequest) (
 		return nil, fmt.Errorf("failed to get schema: %w", err)
 	}
 
+	// Pin the resolved schema version (and, after the first check, the snapshot
+	// token) on a copy of the request so every candidate is checked against the same state
+	pinnedReq := *req
+	pinnedReq.SchemaVersion = schema.Version
+	req = &pinnedReq
+
 	entity := schema.GetEntity(req.EntityType)
 	if entity == nil {
 		return nil, fmt.Errorf("entity type %s not found in schema", req.EntityType)
@@ -221,13 +316,15 @@ func (l *Lookup) LookupSubject(ctx context.Context, req *LookupSubjectRequest) (
 		useOptimizedSubject = false
 	}
 
+	var resp *LookupSubjectResponse
 	if useOptimizedSubject {
 		// Optimized path
-		subjectIDs, err := l.relationRepo.LookupAccessibleSubjectsComplex(
+		var subjectIDs []string
+		subjectIDs, err = l.relationRepo.LookupAccessibleSubjectsComplex(
 			ctx, req.TenantID,
 			req.EntityType, req.EntityID, relations, parentRelations,
 			req.SubjectType,
-			MaxDepth, req.PageToken, limit+1)
+			resolveDepthLimit(req.DepthLimit), req.PageToken, limit+1)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup accessible subjects: %w", err)
 		}
@@ -240,17 +337,24 @@ func (l *Lookup) LookupSubject(ctx context.Context, req *LookupSubjectRequest) (
 			subjectIDs = mergeSortedUnique(subjectIDs, ctxIDs, len(subjectIDs)+len(ctxIDs))
 		}
 
-		return l.verifySubjectsAndPaginate(ctx, req, subjectIDs, limit)
+		resp, err = l.verifySubjectsAndPaginate(ctx, req, subjectIDs, limit)
+	} else {
+		// Fallback path
+		resp, err = l.lookupSubjectFallback(ctx, req, limit)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Fallback path
-	return l.lookupSubjectFallback(ctx, req, limit)
+	resp.SchemaVersion = req.SchemaVersion
+	resp.SnapshotToken = req.SnapshotToken
+	return resp, nil
 }
 
 // lookupEntityFallback uses batched GetSortedEntityIDs + Check loop.
 // Merges candidates from both relations and attributes tables to ensure
 // entities accessible only through attributes (ABAC) are also found.
-func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequest, limit int) (*LookupEntityResponse, error) {
+func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequest, limit int, emit func(entityID string) error) (string, error) {
 	batchSize := limit * 3
 	if batchSize < defaultBatchSize {
 		batchSize = defaultBatchSize
@@ -260,16 +364,18 @@ func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequ
 	}
 
 	cursor := req.PageToken
-	var allowedIDs []string
+	allowedCount := 0
 
 	// Pre-extract contextual tuple entity IDs so they are included in candidates
+	scope := req.Scope[req.EntityType]
 	var ctxEntityIDs []string
 	if len(req.ContextualTuples) > 0 {
-		ctxEntityIDs = extractEntityIDsFromContextualTuples(req.ContextualTuples, req.EntityType)
+		ctxEntityIDs = filterIDsInScope(
+			extractEntityIDsFromContextualTuples(req.ContextualTuples, req.EntityType), scope)
 	}
 
 	for {
-		dbCandidates := l.getMergedEntityCandidates(ctx, req.TenantID, req.EntityType, cursor, batchSize)
+		dbCandidates := l.getMergedEntityCandidates(ctx, req.TenantID, req.EntityType, scope, cursor, batchSize)
 
 		// Merge contextual tuple entity IDs into candidates
 		var candidates []string
@@ -286,6 +392,9 @@ func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequ
 
 		var lastCandidate string
 		for _, entityID := range candidates {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
 			lastCandidate = entityID
 			resp, err := l.checker.Check(ctx, &CheckRequest{
 				TenantID:             req.TenantID,
@@ -299,14 +408,24 @@ func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequ
 				ContextualTuples:     req.ContextualTuples,
 				ContextualAttributes: req.ContextualAttributes,
 				SnapshotToken:        req.SnapshotToken,
+				DepthLimit:           req.DepthLimit,
 			})
 			if err != nil {
-				log.Printf("WARNING: Check failed for entity %s:%s: %v", req.EntityType, entityID, err)
+				if errors.Is(err, ErrMaxDepthExceeded) {
+					return "", err
+				}
+				slog.WarnContext(ctx, "Check failed for lookup candidate", "entity", req.EntityType+":"+entityID, "error", err)
 				continue
 			}
+			if req.SnapshotToken == "" {
+				req.SnapshotToken = resp.SnapshotToken
+			}
 			if resp.Allowed {
-				allowedIDs = append(allowedIDs, entityID)
-				if len(allowedIDs) >= limit {
+				if err := emit(entityID); err != nil {
+					return "", err
+				}
+				allowedCount++
+				if allowedCount >= limit {
 					break
 				}
 			}
@@ -314,7 +433,7 @@ func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequ
 
 		cursor = lastCandidate
 
-		if len(allowedIDs) >= limit {
+		if allowedCount >= limit {
 			break
 		}
 
@@ -324,22 +443,19 @@ func (l *Lookup) lookupEntityFallback(ctx context.Context, req *LookupEntityRequ
 	}
 
 	nextPageToken := ""
-	if len(allowedIDs) >= limit {
+	if allowedCount >= limit {
 		nextPageToken = cursor
 	}
 
-	return &LookupEntityResponse{
-		EntityIDs:     allowedIDs,
-		NextPageToken: nextPageToken,
-	}, nil
+	return nextPageToken, nil
 }
 
 // getMergedEntityCandidates returns sorted unique entity IDs from both
-// relations and attributes tables.
-func (l *Lookup) getMergedEntityCandidates(ctx context.Context, tenantID, entityType, cursor string, batchSize int) []string {
-	relCandidates, err := l.relationRepo.GetSortedEntityIDs(ctx, tenantID, entityType, cursor, batchSize)
+// relations and attributes tables, restricted to scope when it is non-empty.
+func (l *Lookup) getMergedEntityCandidates(ctx context.Context, tenantID, entityType string, scope []string, cursor string, batchSize int) []string {
+	relCandidates, err := l.relationRepo.GetSortedEntityIDs(ctx, tenantID, entityType, scope, cursor, batchSize)
 	if err != nil {
-		log.Printf("WARNING: failed to get entity IDs from relations: %v", err)
+		slog.WarnContext(ctx, "Failed to get entity IDs from relations", "entity_type", entityType, "error", err)
 		relCandidates = nil
 	}
 
@@ -347,9 +463,9 @@ func (l *Lookup) getMergedEntityCandidates(ctx context.Context, tenantID, entity
 		return relCandidates
 	}
 
-	attrCandidates, err := l.attributeRepo.GetSortedEntityIDs(ctx, tenantID, entityType, cursor, batchSize)
+	attrCandidates, err := l.attributeRepo.GetSortedEntityIDs(ctx, tenantID, entityType, scope, cursor, batchSize)
 	if err != nil {
-		log.Printf("WARNING: failed to get entity IDs from attributes: %v", err)
+		slog.WarnContext(ctx, "Failed to get entity IDs from attributes", "entity_type", entityType, "error", err)
 		attrCandidates = nil
 	}
 
@@ -408,11 +524,18 @@ func (l *Lookup) lookupSubjectFallback(ctx context.Context, req *LookupSubjectRe
 				ContextualTuples:     req.ContextualTuples,
 				ContextualAttributes: req.ContextualAttributes,
 				SnapshotToken:        req.SnapshotToken,
+				DepthLimit:           req.DepthLimit,
 			})
 			if err != nil {
-				log.Printf("WARNING: Check failed for subject %s:%s: %v", req.SubjectType, subjectID, err)
+				if errors.Is(err, ErrMaxDepthExceeded) {
+					return nil, err
+				}
+				slog.WarnContext(ctx, "Check failed for lookup candidate", "subject", req.SubjectType+":"+subjectID, "error", err)
 				continue
 			}
+			if req.SnapshotToken == "" {
+				req.SnapshotToken = resp.SnapshotToken
+			}
 			if resp.Allowed {
 				allowedIDs = append(allowedIDs, subjectID)
 				if len(allowedIDs) >= limit {
@@ -448,7 +571,7 @@ func (l *Lookup) lookupSubjectFallback(ctx context.Context, req *LookupSubjectRe
 func (l *Lookup) getMergedSubjectCandidates(ctx context.Context, tenantID, subjectType, cursor string, batchSize int) []string {
 	relCandidates, err := l.relationRepo.GetSortedSubjectIDs(ctx, tenantID, subjectType, cursor, batchSize)
 	if err != nil {
-		log.Printf("WARNING: failed to get subject IDs from relations: %v", err)
+		slog.WarnContext(ctx, "Failed to get subject IDs from relations", "subject_type", subjectType, "error", err)
 		relCandidates = nil
 	}
 
@@ -456,22 +579,26 @@ func (l *Lookup) getMergedSubjectCandidates(ctx context.Context, tenantID, subje
 		return relCandidates
 	}
 
-	attrCandidates, err := l.attributeRepo.GetSortedEntityIDs(ctx, tenantID, subjectType, cursor, batchSize)
+	attrCandidates, err := l.attributeRepo.GetSortedEntityIDs(ctx, tenantID, subjectType, nil, cursor, batchSize)
 	if err != nil {
-		log.Printf("WARNING: failed to get subject IDs from attributes: %v", err)
+		slog.WarnContext(ctx, "Failed to get subject IDs from attributes", "subject_type", subjectType, "error", err)
 		attrCandidates = nil
 	}
 
 	return mergeSortedUnique(relCandidates, attrCandidates, batchSize)
 }
 
-// verifyEntitiesAndPaginate verifies candidate entity IDs with Check and applies pagination
-func (l *Lookup) verifyEntitiesAndPaginate(ctx context.Context, req *LookupEntityRequest, candidates []string, limit int) (*LookupEntityResponse, error) {
+// verifyEntitiesAndPaginate verifies candidate entity IDs with Check, emits the
+// allowed ones and returns the next page token
+func (l *Lookup) verifyEntitiesAndPaginate(ctx context.Context, req *LookupEntityRequest, candidates []string, limit int, emit func(entityID string) error) (string, error) {
 	// Track whether more candidates may exist beyond what we checked
 	hasMoreCandidates := len(candidates) > limit
-	var allowedIDs []string
-	var lastChecked string
+	allowedCount := 0
+	var lastAllowed, lastChecked string
 	for _, entityID := range candidates {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		lastChecked = entityID
 		resp, err := l.checker.Check(ctx, &CheckRequest{
 			TenantID:             req.TenantID,
@@ -485,31 +612,39 @@ func (l *Lookup) verifyEntitiesAndPaginate(ctx context.Context, req *LookupEntit
 			ContextualTuples:     req.ContextualTuples,
 			ContextualAttributes: req.ContextualAttributes,
 			SnapshotToken:        req.SnapshotToken,
+			DepthLimit:           req.DepthLimit,
 		})
 		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return "", err
+			}
 			continue
 		}
+		if req.SnapshotToken == "" {
+			req.SnapshotToken = resp.SnapshotToken
+		}
 		if resp.Allowed {
-			allowedIDs = append(allowedIDs, entityID)
-			if len(allowedIDs) >= limit {
+			if err := emit(entityID); err != nil {
+				return "", err
+			}
+			allowedCount++
+			lastAllowed = entityID
+			if allowedCount >= limit {
 				break
 			}
 		}
 	}
 
 	nextPageToken := ""
-	if len(allowedIDs) >= limit {
-		nextPageToken = allowedIDs[len(allowedIDs)-1]
+	if allowedCount >= limit {
+		nextPageToken = lastAllowed
 	} else if hasMoreCandidates && lastChecked != "" {
 		// More candidates exist in the DB but not enough passed Check in this batch.
 		// Set the token to the last checked candidate so the next page continues from there.
 		nextPageToken = lastChecked
 	}
 
-	return &LookupEntityResponse{
-		EntityIDs:     allowedIDs,
-		NextPageToken: nextPageToken,
-	}, nil
+	return nextPageToken, nil
 }
 
 // verifySubjectsAndPaginate verifies candidate subject IDs with Check and applies pagination
@@ -531,10 +666,17 @@ func (l *Lookup) verifySubjectsAndPaginate(ctx context.Context, req *LookupSubje
 			ContextualTuples:     req.ContextualTuples,
 			ContextualAttributes: req.ContextualAttributes,
 			SnapshotToken:        req.SnapshotToken,
+			DepthLimit:           req.DepthLimit,
 		})
 		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
 			continue
 		}
+		if req.SnapshotToken == "" {
+			req.SnapshotToken = resp.SnapshotToken
+		}
 		if resp.Allowed {
 			allowedIDs = append(allowedIDs, subjectID)
 			if len(allowedIDs) >= limit {
@@ -807,4 +949,23 @@ func filterIDsAfterCursor(ids []string, cursor string) []string {
 	return ids[idx:]
 }
 
+// filterIDsInScope returns the IDs contained in scope, preserving order.
+// An empty scope returns ids unchanged.
+func filterIDsInScope(ids []string, scope []string) []string {
+	if len(scope) == 0 {
+		return ids
+	}
+	allowed := make(map[string]bool, len(scope))
+	for _, id := range scope {
+		allowed[id] = true
+	}
+	var filtered []string
+	for _, id := range ids {
+		if allowed[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
 // extractBaseType is defined in evaluator.go - reused via package scope.
@@ -2,10 +2,14 @@ package authorization
 
 import (
 	"context"
+	"errors"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
 )
 
 // --- extractRelationsFromRuleWithContext tests ---
@@ -616,6 +620,383 @@ func TestLookup_LookupEntity_Pagination(t *testing.T) {
 	}
 }
 
+func TestLookup_LookupEntityStream_AcrossBatches(t *testing.T) {
+	// ABAC forces the fallback path; PageSize=2 forces multiple internal batches
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "document",
+				Relations: []*entities.Relation{
+					{Name: "owner", TargetType: "user"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.LogicalRule{
+							Operator: "or",
+							Left:     &entities.RelationRule{Relation: "owner"},
+							Right:    &entities.ABACRule{Expression: "resource.public == true"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc2", Relation: "owner", SubjectType: "user", SubjectID: "bob"},
+			{EntityType: "document", EntityID: "doc3", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc4", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc5", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+	lookup := NewLookup(checker, schemaService, relationRepo)
+
+	req := &LookupEntityRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+		PageSize:    2,
+	}
+
+	var ids, tokens []string
+	_, err := lookup.LookupEntityStream(context.Background(), req, func(entityID, continuousToken string) error {
+		ids = append(ids, entityID)
+		tokens = append(tokens, continuousToken)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"doc1", "doc3", "doc4", "doc5"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+
+	// Resuming from the token of the second item yields the remainder
+	req.PageToken = tokens[1]
+	var resumed []string
+	_, err = lookup.LookupEntityStream(context.Background(), req, func(entityID, _ string) error {
+		resumed = append(resumed, entityID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error on resume: %v", err)
+	}
+	if strings.Join(resumed, ",") != "doc4,doc5" {
+		t.Errorf("expected [doc4 doc5] after resume, got %v", resumed)
+	}
+}
+
+func TestLookup_PinsSchemaAndSnapshot(t *testing.T) {
+	// ABAC forces the fallback path; PageSize=2 forces multiple internal batches
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Version:  "v1",
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name: "document",
+				Relations: []*entities.Relation{
+					{Name: "owner", TargetType: "user"},
+				},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.LogicalRule{
+							Operator: "or",
+							Left:     &entities.RelationRule{Relation: "owner"},
+							Right:    &entities.ABACRule{Expression: "resource.public == true"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc2", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc3", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc4", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc5", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+	}
+	var capturedVersions []string
+	schemaService := &mockSchemaServiceCapture{
+		schema: schema,
+		onGetSchemaEntity: func(_, version string) {
+			capturedVersions = append(capturedVersions, version)
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	memCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer memCache.Close()
+	snapshots := &countingSnapshotProvider{}
+	checker := NewCheckerWithCache(schemaService, evaluator, memCache, snapshots, time.Minute)
+	lookup := NewLookup(checker, schemaService, relationRepo)
+
+	var ids []string
+	resp, err := lookup.LookupEntityStream(context.Background(), &LookupEntityRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+		PageSize:    2,
+	}, func(entityID, _ string) error {
+		ids = append(ids, entityID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 5 {
+		t.Fatalf("expected 5 entities, got %v", ids)
+	}
+
+	// Only the first lookup resolves the latest schema; every later call,
+	// including those for later batches, uses the pinned version
+	if len(capturedVersions) < 2 || capturedVersions[0] != "" {
+		t.Fatalf("expected the first call to resolve the latest schema, got %v", capturedVersions)
+	}
+	for i, version := range capturedVersions[1:] {
+		if version != "v1" {
+			t.Errorf("call %d used schema version %q, expected pinned %q", i+1, version, "v1")
+		}
+	}
+
+	// The snapshot is resolved once and reused for every candidate
+	if calls := snapshots.calls.Load(); calls != 1 {
+		t.Errorf("expected snapshot to be resolved once, got %d", calls)
+	}
+
+	// The resolved schema version and snapshot token are reported
+	if resp.SchemaVersion != "v1" || resp.SnapshotToken != "100:105:" {
+		t.Errorf("expected stream evaluated at v1/100:105:, got %s/%s", resp.SchemaVersion, resp.SnapshotToken)
+	}
+
+	entityResp, err := lookup.LookupEntity(context.Background(), &LookupEntityRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entityResp.SchemaVersion != "v1" || entityResp.SnapshotToken != "100:105:" {
+		t.Errorf("expected LookupEntity evaluated at v1/100:105:, got %s/%s", entityResp.SchemaVersion, entityResp.SnapshotToken)
+	}
+
+	subjectResp, err := lookup.LookupSubject(context.Background(), &LookupSubjectRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		Permission:  "view",
+		SubjectType: "user",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subjectResp.SubjectIDs) != 1 || subjectResp.SchemaVersion != "v1" || subjectResp.SnapshotToken != "100:105:" {
+		t.Errorf("expected LookupSubject to return alice evaluated at v1/100:105:, got %+v", subjectResp)
+	}
+}
+
+func TestLookup_LookupEntityStream_StopsOnSendError(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		tuples: []*entities.RelationTuple{
+			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			{EntityType: "document", EntityID: "doc2", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		},
+		lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+			return []string{"doc1", "doc2"}, nil
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+	lookup := NewLookup(checker, schemaService, relationRepo)
+
+	sendErr := errors.New("client went away")
+	calls := 0
+	_, err := lookup.LookupEntityStream(context.Background(), &LookupEntityRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	}, func(entityID, continuousToken string) error {
+		calls++
+		return sendErr
+	})
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("expected send error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected stream to stop after first send, got %d calls", calls)
+	}
+}
+
+func TestLookup_LookupEntityStream_Canceled(t *testing.T) {
+	schema := createTestSchema()
+	relationRepo := &mockRelationRepository{
+		lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+			return []string{"doc1", "doc2"}, nil
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+	checker := NewChecker(schemaService, evaluator)
+	lookup := NewLookup(checker, schemaService, relationRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := lookup.LookupEntityStream(ctx, &LookupEntityRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	}, func(entityID, continuousToken string) error {
+		t.Errorf("unexpected send for %s after cancellation", entityID)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLookup_LookupEntity_Scope(t *testing.T) {
+	tuples := []*entities.RelationTuple{
+		{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		{EntityType: "document", EntityID: "doc2", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+		{EntityType: "document", EntityID: "doc3", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+	}
+
+	t.Run("optimized path pushes scope to SQL lookup", func(t *testing.T) {
+		var gotScope []string
+		relationRepo := &mockRelationRepository{
+			tuples: tuples,
+			lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+				gotScope = scope
+				return scope, nil
+			},
+		}
+		schema := createTestSchema()
+		celEngine, _ := NewCELEngine()
+		schemaService := &mockSchemaRepository{schema}
+		evaluator := NewEvaluator(schemaService, relationRepo, newMockAttributeRepository(), celEngine)
+		lookup := NewLookup(NewChecker(schemaService, evaluator), schemaService, relationRepo)
+
+		resp, err := lookup.LookupEntity(context.Background(), &LookupEntityRequest{
+			TenantID:    "test-tenant",
+			EntityType:  "document",
+			Permission:  "view",
+			SubjectType: "user",
+			SubjectID:   "alice",
+			Scope: map[string][]string{
+				"document": {"doc1", "doc3"},
+				"folder":   {"folder1"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(gotScope, ",") != "doc1,doc3" {
+			t.Errorf("expected scope [doc1 doc3] passed to repository, got %v", gotScope)
+		}
+		if strings.Join(resp.EntityIDs, ",") != "doc1,doc3" {
+			t.Errorf("expected [doc1 doc3], got %v", resp.EntityIDs)
+		}
+	})
+
+	t.Run("fallback path restricts candidates", func(t *testing.T) {
+		schema := &entities.Schema{
+			TenantID: "test-tenant",
+			Entities: []*entities.Entity{
+				{Name: "user"},
+				{
+					Name: "document",
+					Relations: []*entities.Relation{
+						{Name: "owner", TargetType: "user"},
+					},
+					Permissions: []*entities.Permission{
+						{
+							Name: "view",
+							Rule: &entities.LogicalRule{
+								Operator: "or",
+								Left:     &entities.RelationRule{Relation: "owner"},
+								Right:    &entities.ABACRule{Expression: "resource.public == true"},
+							},
+						},
+					},
+				},
+			},
+		}
+		relationRepo := &mockRelationRepository{tuples: tuples}
+		attributeRepo := newMockAttributeRepository()
+		celEngine, _ := NewCELEngine()
+		schemaService := &mockSchemaRepository{schema}
+		evaluator := NewEvaluator(schemaService, relationRepo, attributeRepo, celEngine)
+		lookup := NewLookup(NewChecker(schemaService, evaluator), schemaService, relationRepo, attributeRepo)
+
+		resp, err := lookup.LookupEntity(context.Background(), &LookupEntityRequest{
+			TenantID:    "test-tenant",
+			EntityType:  "document",
+			Permission:  "view",
+			SubjectType: "user",
+			SubjectID:   "alice",
+			ContextualTuples: []*entities.RelationTuple{
+				{EntityType: "document", EntityID: "doc4", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
+			},
+			Scope: map[string][]string{"document": {"doc2"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(resp.EntityIDs, ",") != "doc2" {
+			t.Errorf("expected [doc2], got %v", resp.EntityIDs)
+		}
+	})
+}
+
+func TestFilterIDsInScope(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	if got := filterIDsInScope(ids, nil); strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("expected unchanged IDs for empty scope, got %v", got)
+	}
+	if got := filterIDsInScope(ids, []string{"c", "a", "z"}); strings.Join(got, ",") != "a,c" {
+		t.Errorf("expected [a c], got %v", got)
+	}
+}
+
 func TestLookup_LookupEntity_ErrorCases(t *testing.T) {
 	schema := createTestSchema()
 	relationRepo := &mockRelationRepository{}
@@ -1403,7 +1784,7 @@ func TestBugfix_LookupEntityContextualTupleOnlyEntities(t *testing.T) {
 		},
 		// LookupAccessibleEntitiesComplex returns doc1 from SQL
 		lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string,
-			entityType string, relations []string, parentRelations []string,
+			entityType string, scope []string, relations []string, parentRelations []string,
 			subjectType string, subjectID string,
 			maxDepth int, cursor string, limit int) ([]string, error) {
 			if subjectID == "alice" {
@@ -1992,7 +2373,7 @@ func TestLookup_LookupEntity_WithRelationName(t *testing.T) {
 		tuples: []*entities.RelationTuple{
 			{EntityType: "document", EntityID: "doc1", Relation: "owner", SubjectType: "user", SubjectID: "alice"},
 		},
-		lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string, entityType string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
+		lookupAccessibleEntitiesComplexFunc: func(ctx context.Context, tenantID string, entityType string, scope []string, relations []string, parentRelations []string, subjectType string, subjectID string, maxDepth int, cursor string, limit int) ([]string, error) {
 			// Return doc1 for alice's owner relation
 			if subjectID == "alice" {
 				return []string{"doc1"}, nil
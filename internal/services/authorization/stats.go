@@ -0,0 +1,30 @@
+package authorization
+
+// EvaluationStats counts the work performed while evaluating a single check.
+// A check is evaluated sequentially, so the counters are not synchronized.
+type EvaluationStats struct {
+	CheckCount     int // Rule nodes evaluated, including every nested sub-check
+	QueryCount     int // Relation and attribute repository queries issued
+	CELEvaluations int // CEL expressions evaluated (ABAC rules and rule calls)
+}
+
+// addCheck counts one evaluated rule node. It is a no-op on nil stats.
+func (s *EvaluationStats) addCheck() {
+	if s != nil {
+		s.CheckCount++
+	}
+}
+
+// addQuery counts one repository query. It is a no-op on nil stats.
+func (s *EvaluationStats) addQuery() {
+	if s != nil {
+		s.QueryCount++
+	}
+}
+
+// addCELEvaluation counts one CEL evaluation. It is a no-op on nil stats.
+func (s *EvaluationStats) addCELEvaluation() {
+	if s != nil {
+		s.CELEvaluations++
+	}
+}
@@ -0,0 +1,112 @@
+package authorization
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// Trace node types, one per permission rule kind
+const (
+	TraceTypeRelation             = "relation"
+	TraceTypeLogical              = "logical"
+	TraceTypeHierarchical         = "hierarchical"
+	TraceTypeABAC                 = "abac"
+	TraceTypeRuleCall             = "rule_call"
+	TraceTypeHierarchicalRuleCall = "hierarchical_rule_call"
+)
+
+// Trace records the rule nodes visited while evaluating a single check.
+// It is only collected for checks run in debug mode, and is not safe for
+// concurrent use (a single check is evaluated sequentially).
+type Trace struct {
+	Nodes []*TraceNode // Visited nodes in evaluation order (pre-order)
+
+	level int // Nesting level of the node currently being evaluated
+}
+
+// TraceNode describes one rule node visited during evaluation
+type TraceNode struct {
+	Level    int                    // Nesting level in the evaluation tree (0 = permission root)
+	Type     string                 // Rule kind (see TraceType* constants)
+	Rule     string                 // Rule as written in the schema (e.g., "owner", "parent.edit", "or")
+	Entity   string                 // Entity the rule was evaluated on (type:id)
+	Subject  string                 // Subject being checked (type:id[#relation])
+	Result   bool                   // Result of the node
+	Matched  []string               // Tuples that granted the node
+	Values   map[string]interface{} // Attribute values and parameters handed to CEL
+	Error    string                 // Evaluation error, if any
+	Duration time.Duration          // Wall time spent in the node, including child nodes
+}
+
+// enter appends a node for rule and returns it; the caller must call exit
+// once the node has been evaluated.
+func (t *Trace) enter(req *EvaluationRequest, rule entities.PermissionRule) *TraceNode {
+	subject := req.SubjectType + ":" + req.SubjectID
+	if req.SubjectRelation != "" {
+		subject += "#" + req.SubjectRelation
+	}
+	ruleType, ruleText := describeRule(rule)
+	node := &TraceNode{
+		Level:   t.level,
+		Type:    ruleType,
+		Rule:    ruleText,
+		Entity:  req.EntityType + ":" + req.EntityID,
+		Subject: subject,
+	}
+	t.Nodes = append(t.Nodes, node)
+	t.level++
+	return node
+}
+
+// exit records the outcome of node
+func (t *Trace) exit(node *TraceNode, start time.Time, result bool, err error) {
+	t.level--
+	node.Result = result
+	node.Duration = time.Since(start)
+	if err != nil {
+		node.Error = err.Error()
+	}
+}
+
+// match records a tuple that granted the node. It is a no-op on a nil node
+// so that evaluation code can call it unconditionally.
+func (n *TraceNode) match(tuple *entities.RelationTuple) {
+	if n == nil {
+		return
+	}
+	n.Matched = append(n.Matched, tuple.String())
+}
+
+// setValue records a value handed to CEL. It is a no-op on a nil node.
+func (n *TraceNode) setValue(key string, value interface{}) {
+	if n == nil {
+		return
+	}
+	if n.Values == nil {
+		n.Values = make(map[string]interface{})
+	}
+	n.Values[key] = value
+}
+
+// describeRule returns the trace type and the schema notation of rule
+func describeRule(rule entities.PermissionRule) (string, string) {
+	switch r := rule.(type) {
+	case *entities.RelationRule:
+		return TraceTypeRelation, r.Relation
+	case *entities.LogicalRule:
+		return TraceTypeLogical, r.Operator
+	case *entities.HierarchicalRule:
+		return TraceTypeHierarchical, r.Relation + "." + r.Permission
+	case *entities.ABACRule:
+		return TraceTypeABAC, r.Expression
+	case *entities.RuleCallRule:
+		return TraceTypeRuleCall, fmt.Sprintf("%s(%s)", r.RuleName, strings.Join(r.Arguments, ", "))
+	case *entities.HierarchicalRuleCallRule:
+		return TraceTypeHierarchicalRuleCall, fmt.Sprintf("%s.%s(%s)", r.Relation, r.RuleName, strings.Join(r.Arguments, ", "))
+	default:
+		return fmt.Sprintf("%T", rule), ""
+	}
+}
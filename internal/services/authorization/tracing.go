@@ -0,0 +1,53 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates OpenTelemetry spans for rule nodes, cache lookups and CEL evaluations.
+// Unlike Trace, which is only collected for debug checks, spans are recorded
+// whenever a tracer provider is installed and the request is sampled.
+var tracer = otel.Tracer("github.com/asakaida/keruberosu/internal/services/authorization")
+
+// startRuleSpan starts the span of one rule node, named after the rule kind
+// (e.g. "rule.relation")
+func startRuleSpan(ctx context.Context, req *EvaluationRequest, rule entities.PermissionRule) (context.Context, trace.Span) {
+	ruleType, ruleText := describeRule(rule)
+	ctx, span := tracer.Start(ctx, "rule."+ruleType)
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("keruberosu.rule", ruleText),
+			attribute.String("keruberosu.entity", req.EntityType+":"+req.EntityID),
+			attribute.String("keruberosu.subject", req.SubjectType+":"+req.SubjectID),
+			attribute.Int("keruberosu.depth", req.Depth),
+		)
+	}
+	return ctx, span
+}
+
+// traceCEL runs eval, one CEL evaluation of expression, in a span
+func traceCEL(ctx context.Context, expression string, eval func() (bool, error)) (bool, error) {
+	_, span := tracer.Start(ctx, "cel.evaluate", trace.WithAttributes(
+		attribute.String("cel.expression", expression),
+	))
+	result, err := eval()
+	endSpan(span, result, err)
+	return result, err
+}
+
+// endSpan records the boolean outcome or the error of a span and ends it
+func endSpan(span trace.Span, result bool, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Bool("keruberosu.result", result))
+	}
+	span.End()
+}
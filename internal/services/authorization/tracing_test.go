@@ -0,0 +1,90 @@
+package authorization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestChecker_Check_Spans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+
+	schema := &entities.Schema{
+		TenantID: "test-tenant",
+		Entities: []*entities.Entity{
+			{Name: "user"},
+			{
+				Name:      "document",
+				Relations: []*entities.Relation{{Name: "owner", TargetType: "user"}},
+				Permissions: []*entities.Permission{
+					{
+						Name: "view",
+						Rule: &entities.LogicalRule{
+							Operator: "or",
+							Left:     &entities.RelationRule{Relation: "owner"},
+							Right:    &entities.ABACRule{Expression: "resource.public == true"},
+						},
+					},
+				},
+			},
+		},
+	}
+	attributeRepo := newMockAttributeRepository()
+	attributeRepo.Write(context.Background(), "test-tenant", &entities.Attribute{
+		EntityType: "document", EntityID: "doc1", Name: "public", Value: true,
+	})
+	celEngine, _ := NewCELEngine()
+	schemaService := &mockSchemaRepository{schema}
+	evaluator := NewEvaluator(schemaService, &mockRelationRepository{}, attributeRepo, celEngine)
+	checkCache, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, DefaultTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer checkCache.Close()
+	checker := NewCheckerWithCache(schemaService, evaluator, checkCache, &countingSnapshotProvider{}, time.Minute)
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "Permission/Check")
+	resp, err := checker.Check(ctx, &CheckRequest{
+		TenantID:    "test-tenant",
+		EntityType:  "document",
+		EntityID:    "doc1",
+		Permission:  "view",
+		SubjectType: "user",
+		SubjectID:   "alice",
+	})
+	root.End()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !resp.Allowed {
+		t.Error("expected allowed")
+	}
+
+	counts := make(map[string]int)
+	for _, span := range exporter.GetSpans() {
+		counts[span.Name]++
+		if span.SpanContext.TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("span %s is not part of the request trace", span.Name)
+		}
+	}
+	want := map[string]int{
+		"cache.get":     1,
+		"rule.logical":  1,
+		"rule.relation": 1,
+		"rule.abac":     1,
+		"cel.evaluate":  1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("expected %d %s span(s), got %d (all spans: %v)", n, name, counts[name], counts)
+		}
+	}
+}
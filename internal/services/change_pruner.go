@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// changePruneInterval is how often the change log is pruned
+const changePruneInterval = 5 * time.Minute
+
+// ChangePruner periodically deletes changes older than the retention window
+// from the change log read by Data.Watch. Watch streams resuming from a
+// position inside the pruned range fail with repositories.ErrChangesPruned.
+type ChangePruner struct {
+	changeRepo repositories.ChangeRepository
+	retention  time.Duration
+	interval   time.Duration
+	now        func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewChangePruner creates a pruner keeping changes for the retention window
+func NewChangePruner(changeRepo repositories.ChangeRepository, retention time.Duration) *ChangePruner {
+	return &ChangePruner{
+		changeRepo: changeRepo,
+		retention:  retention,
+		interval:   changePruneInterval,
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Prune deletes the changes recorded before the retention window
+func (p *ChangePruner) Prune(ctx context.Context) (int64, error) {
+	return p.changeRepo.PruneChanges(ctx, p.now().Add(-p.retention))
+}
+
+// Start prunes the change log now and then every interval until Stop
+func (p *ChangePruner) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.pruneAndLog()
+			select {
+			case <-ticker.C:
+			case <-p.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (p *ChangePruner) pruneAndLog() {
+	deleted, err := p.Prune(context.Background())
+	if err != nil {
+		slog.Warn("Failed to prune change log", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Pruned change log", "deleted", deleted, "retention", p.retention.String())
+	}
+}
+
+// Stop stops pruning the change log
+func (p *ChangePruner) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	p.wg.Wait()
+}
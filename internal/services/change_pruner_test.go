@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChangePruner_Prune(t *testing.T) {
+	repo := &mockChangeRepository{}
+	pruner := NewChangePruner(repo, 24*time.Hour)
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	pruner.now = func() time.Time { return now }
+
+	if _, err := pruner.Prune(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.prunes) != 1 || !repo.prunes[0].Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("expected changes before %v to be pruned, got %v", now.Add(-24*time.Hour), repo.prunes)
+	}
+
+	repo.pruneErr = errors.New("connection refused")
+	if _, err := pruner.Prune(context.Background()); !errors.Is(err, repo.pruneErr) {
+		t.Errorf("expected repository error, got %v", err)
+	}
+}
+
+func TestChangePruner_StartStop(t *testing.T) {
+	repo := &mockChangeRepository{}
+	pruner := NewChangePruner(repo, time.Hour)
+	pruner.interval = 10 * time.Millisecond
+
+	pruner.Start()
+	deadline := time.Now().Add(time.Second)
+	for {
+		repo.mu.Lock()
+		n := len(repo.prunes)
+		repo.mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected periodic pruning, got %d prunes", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	pruner.Stop()
+	pruner.Stop() // Stop is idempotent
+
+	repo.mu.Lock()
+	n := len(repo.prunes)
+	repo.mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if len(repo.prunes) != n {
+		t.Errorf("expected no pruning after Stop, got %d more", len(repo.prunes)-n)
+	}
+}
@@ -299,15 +299,20 @@ func (v *Validator) validatePermissionRule(entity *EntityAST, permissionName str
 				entity.Name, permissionName, r.RuleName, len(r.Arguments), len(ruleDef.Parameters)))
 		}
 
-		// Validate that arguments are valid identifiers (resource, subject, etc.)
+		// Validate that arguments are standard names (resource, subject, request) or
+		// attributes of the current entity. Attribute arguments take their value from
+		// the request's positional arguments, falling back to the stored attribute.
 		validArguments := map[string]bool{
 			"resource": true,
 			"subject":  true,
 			"request":  true,
 		}
+		for _, attr := range entity.Attributes {
+			validArguments[attr.Name] = true
+		}
 		for _, arg := range r.Arguments {
 			if !validArguments[arg] {
-				v.errors = append(v.errors, fmt.Sprintf("entity %s: permission %s calls rule %s with invalid argument: %s (must be 'resource', 'subject', or 'request')",
+				v.errors = append(v.errors, fmt.Sprintf("entity %s: permission %s calls rule %s with invalid argument: %s (must be a valid attribute name or one of 'resource', 'subject', 'request')",
 					entity.Name, permissionName, r.RuleName, arg))
 			}
 		}
@@ -619,6 +619,29 @@ entity document {
 	}
 }
 
+func TestValidator_RuleCallAttributeArgument(t *testing.T) {
+	input := `rule check_ip(request, ip_range) {
+  request.ip in ip_range
+}
+
+entity document {
+  attribute ip_range string[]
+  permission view = check_ip(request, ip_range)
+}`
+
+	lexer := NewLexer(input)
+	parser := NewParser(lexer)
+	schema, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	validator := NewValidator(schema)
+	if err := validator.Validate(); err != nil {
+		t.Errorf("expected attribute argument to be valid, got: %v", err)
+	}
+}
+
 func TestValidator_MultipleRulesAndCalls(t *testing.T) {
 	input := `rule is_public(resource) {
   resource.public == true
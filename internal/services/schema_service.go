@@ -2,18 +2,27 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/infrastructure/logging"
 	"github.com/asakaida/keruberosu/internal/repositories"
 	"github.com/asakaida/keruberosu/internal/services/parser"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// tracer creates a span for every schema resolution
+var tracer = otel.Tracer("github.com/asakaida/keruberosu/internal/services")
+
 // SchemaServiceInterface defines the interface for schema management operations
 type SchemaServiceInterface interface {
 	WriteSchema(ctx context.Context, tenantID string, schemaDSL string) (string, error)
+	WriteSchemaInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error)
 	ReadSchema(ctx context.Context, tenantID string) (*entities.Schema, error)
 	ValidateSchema(ctx context.Context, schemaDSL string) error
 	DeleteSchema(ctx context.Context, tenantID string) error
@@ -35,12 +44,45 @@ func NewSchemaService(schemaRepo repositories.SchemaRepository) *SchemaService {
 
 // WriteSchema parses DSL, validates it, and creates a new schema version
 func (s *SchemaService) WriteSchema(ctx context.Context, tenantID string, schemaDSL string) (string, error) {
+	if err := s.validateForWrite(tenantID, schemaDSL); err != nil {
+		return "", err
+	}
+
+	// Always create a new version (Permify-compatible behavior)
+	version, err := s.schemaRepo.Create(ctx, tenantID, schemaDSL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create schema version: %w", err)
+	}
+
+	s.invalidateCache(tenantID)
+	return version, nil
+}
+
+// WriteSchemaInTx is like WriteSchema but creates the schema version within an
+// existing transaction, so callers can commit other writes (e.g. an audit log) atomically with it
+func (s *SchemaService) WriteSchemaInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	if err := s.validateForWrite(tenantID, schemaDSL); err != nil {
+		return "", err
+	}
+
+	version, err := s.schemaRepo.CreateInTx(ctx, tx, tenantID, schemaDSL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create schema version: %w", err)
+	}
+
+	// Invalidate eagerly: a rolled-back write only costs a cache miss
+	s.invalidateCache(tenantID)
+	return version, nil
+}
+
+// validateForWrite parses and validates DSL before a new schema version is created
+func (s *SchemaService) validateForWrite(tenantID string, schemaDSL string) error {
 	// Validate input
 	if tenantID == "" {
-		return "", fmt.Errorf("tenant ID is required")
+		return fmt.Errorf("tenant ID is required")
 	}
 	if schemaDSL == "" {
-		return "", fmt.Errorf("schema DSL is required")
+		return fmt.Errorf("schema DSL is required")
 	}
 
 	// Parse DSL
@@ -48,29 +90,21 @@ func (s *SchemaService) WriteSchema(ctx context.Context, tenantID string, schema
 	p := parser.NewParser(lexer)
 	ast, err := p.Parse()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse DSL: %w", err)
+		return fmt.Errorf("failed to parse DSL: %w", err)
 	}
 
 	// Validate schema
 	validator := parser.NewValidator(ast)
 	if err := validator.Validate(); err != nil {
-		return "", fmt.Errorf("schema validation failed: %w", err)
+		return fmt.Errorf("schema validation failed: %w", err)
 	}
 
 	// Convert AST to entities.Schema for validation
-	_, err = parser.ASTToSchema(tenantID, ast)
-	if err != nil {
-		return "", fmt.Errorf("failed to convert schema: %w", err)
-	}
-
-	// Always create a new version (Permify-compatible behavior)
-	version, err := s.schemaRepo.Create(ctx, tenantID, schemaDSL)
-	if err != nil {
-		return "", fmt.Errorf("failed to create schema version: %w", err)
+	if _, err := parser.ASTToSchema(tenantID, ast); err != nil {
+		return fmt.Errorf("failed to convert schema: %w", err)
 	}
 
-	s.invalidateCache(tenantID)
-	return version, nil
+	return nil
 }
 
 // ReadSchema retrieves the latest schema for a tenant
@@ -136,9 +170,33 @@ func (s *SchemaService) DeleteSchema(ctx context.Context, tenantID string) error
 // GetSchemaEntity retrieves the parsed schema entity for internal use
 // version="" means use the latest version
 func (s *SchemaService) GetSchemaEntity(ctx context.Context, tenantID string, version string) (*entities.Schema, error) {
+	ctx, span := tracer.Start(ctx, "schema.resolve")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("keruberosu.tenant_id", tenantID),
+		attribute.String("keruberosu.schema_version.requested", version),
+	)
+
+	schema, parsed, err := s.getSchemaEntity(ctx, tenantID, version)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.String("keruberosu.schema_version", schema.Version),
+		attribute.Bool("keruberosu.schema_parsed", parsed),
+	)
+	logging.SetSchemaVersion(ctx, schema.Version)
+	return schema, nil
+}
+
+// getSchemaEntity resolves the schema; parsed reports whether the DSL had to be
+// parsed because the version was not in the parsed schema cache
+func (s *SchemaService) getSchemaEntity(ctx context.Context, tenantID string, version string) (*entities.Schema, bool, error) {
 	// Validate input
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenant ID is required")
+		return nil, false, fmt.Errorf("tenant ID is required")
 	}
 
 	// Get schema from database
@@ -154,17 +212,17 @@ func (s *SchemaService) GetSchemaEntity(ctx context.Context, tenantID string, ve
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get schema: %w", err)
+		return nil, false, fmt.Errorf("failed to get schema: %w", err)
 	}
 
 	if dbSchema == nil {
-		return nil, fmt.Errorf("schema not found for tenant: %s", tenantID)
+		return nil, false, fmt.Errorf("schema not found for tenant: %s", tenantID)
 	}
 
 	// Check cache using actual version from DB
 	cacheKey := tenantID + ":" + dbSchema.Version
 	if cached, ok := s.schemaCache.Load(cacheKey); ok {
-		return cached.(*entities.Schema), nil
+		return cached.(*entities.Schema), false, nil
 	}
 
 	// Parse DSL to populate Entities field
@@ -172,13 +230,13 @@ func (s *SchemaService) GetSchemaEntity(ctx context.Context, tenantID string, ve
 	p := parser.NewParser(lexer)
 	ast, err := p.Parse()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse schema DSL: %w", err)
+		return nil, false, fmt.Errorf("failed to parse schema DSL: %w", err)
 	}
 
 	// Convert AST to Schema with Entities populated
 	parsedSchema, err := parser.ASTToSchema(tenantID, ast)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert AST to schema: %w", err)
+		return nil, false, fmt.Errorf("failed to convert AST to schema: %w", err)
 	}
 
 	// Preserve metadata from database
@@ -189,7 +247,15 @@ func (s *SchemaService) GetSchemaEntity(ctx context.Context, tenantID string, ve
 	// Cache the parsed schema
 	s.schemaCache.Store(cacheKey, parsedSchema)
 
-	return parsedSchema, nil
+	return parsedSchema, true, nil
+}
+
+// Ready reports whether the schema store answers queries, i.e. schemas can be read and written
+func (s *SchemaService) Ready(ctx context.Context) error {
+	if _, err := s.schemaRepo.ListVersions(ctx, "", 1, ""); err != nil {
+		return fmt.Errorf("schema store is not ready: %w", err)
+	}
+	return nil
 }
 
 // invalidateCache removes all cached schemas for a tenant.
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 
@@ -39,6 +40,10 @@ func (m *mockSchemaRepository) Create(ctx context.Context, tenantID string, sche
 	return version, nil
 }
 
+func (m *mockSchemaRepository) CreateInTx(ctx context.Context, tx *sql.Tx, tenantID string, schemaDSL string) (string, error) {
+	return m.Create(ctx, tenantID, schemaDSL)
+}
+
 func (m *mockSchemaRepository) GetLatestVersion(ctx context.Context, tenantID string) (*entities.Schema, error) {
 	versions, exists := m.schemas[tenantID]
 	if !exists || len(versions) == 0 {
@@ -480,3 +485,23 @@ func TestSchemaService_GetSchemaEntity_MissingTenantID(t *testing.T) {
 		t.Fatal("expected error for missing tenant ID")
 	}
 }
+
+// unavailableSchemaRepository fails every query, like an unreachable database
+type unavailableSchemaRepository struct {
+	*mockSchemaRepository
+}
+
+func (m *unavailableSchemaRepository) ListVersions(ctx context.Context, tenantID string, limit int, cursor string) ([]*entities.SchemaVersion, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestSchemaService_Ready(t *testing.T) {
+	if err := NewSchemaService(newMockSchemaRepository()).Ready(context.Background()); err != nil {
+		t.Errorf("expected ready, got %v", err)
+	}
+
+	service := NewSchemaService(&unavailableSchemaRepository{newMockSchemaRepository()})
+	if err := service.Ready(context.Background()); err == nil {
+		t.Error("expected error when the schema store is unavailable")
+	}
+}
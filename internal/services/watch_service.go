@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+	"github.com/asakaida/keruberosu/internal/repositories"
+)
+
+// watchBatchSize is the maximum number of transactions read from the change log at once
+const watchBatchSize = 100
+
+// defaultWatchPollInterval is used when NewWatchService is given a non-positive poll interval
+const defaultWatchPollInterval = time.Second
+
+// ChangeSubscriber notifies watchers that new changes may be available.
+// Implemented by database.ChangeNotifier.
+type ChangeSubscriber interface {
+	Subscribe() (<-chan struct{}, func())
+}
+
+// WatchServiceInterface defines the interface for streaming data changes
+type WatchServiceInterface interface {
+	Watch(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error
+}
+
+// WatchService streams relation and attribute changes from the change log
+type WatchService struct {
+	changeRepo   repositories.ChangeRepository
+	subscriber   ChangeSubscriber // Optional: without it, the change log is polled only
+	pollInterval time.Duration
+}
+
+// NewWatchService creates a new WatchService.
+// pollInterval bounds the delay between a commit and its delivery when no
+// notification arrives (e.g., subscriber is nil or the listener reconnects).
+func NewWatchService(changeRepo repositories.ChangeRepository, subscriber ChangeSubscriber, pollInterval time.Duration) *WatchService {
+	if pollInterval <= 0 {
+		pollInterval = defaultWatchPollInterval
+	}
+	return &WatchService{
+		changeRepo:   changeRepo,
+		subscriber:   subscriber,
+		pollInterval: pollInterval,
+	}
+}
+
+// Watch calls send for every change set of the tenant committed by a transaction
+// after afterTxID, in transaction order, until ctx is done or send fails.
+// An afterTxID of 0 starts from the changes committed after the call.
+func (s *WatchService) Watch(ctx context.Context, tenantID string, afterTxID int64, send func(*entities.ChangeSet) error) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	// Subscribe before the first read so that no notification is missed
+	var notify <-chan struct{}
+	if s.subscriber != nil {
+		var unsubscribe func()
+		notify, unsubscribe = s.subscriber.Subscribe()
+		defer unsubscribe()
+	}
+
+	if afterTxID <= 0 {
+		current, err := s.changeRepo.CurrentTxID(ctx)
+		if err != nil {
+			return err
+		}
+		afterTxID = current - 1
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		changeSets, err := s.changeRepo.ReadChanges(ctx, tenantID, afterTxID, watchBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, changeSet := range changeSets {
+			if err := send(changeSet); err != nil {
+				return err
+			}
+			afterTxID = changeSet.TxID
+		}
+		if len(changeSets) == watchBatchSize {
+			// More changes may be pending; read again without waiting
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notify:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asakaida/keruberosu/internal/entities"
+)
+
+// Mock ChangeRepository
+type mockChangeRepository struct {
+	mu         sync.Mutex
+	changeSets []*entities.ChangeSet // Committed change sets in txid order
+	currentTx  int64
+	reads      []int64     // afterTxID of every ReadChanges call
+	prunes     []time.Time // before of every PruneChanges call
+	pruneErr   error
+}
+
+func (m *mockChangeRepository) ReadChanges(ctx context.Context, tenantID string, afterTxID int64, limit int) ([]*entities.ChangeSet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reads = append(m.reads, afterTxID)
+	var result []*entities.ChangeSet
+	for _, cs := range m.changeSets {
+		if cs.TxID > afterTxID && len(result) < limit {
+			result = append(result, cs)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockChangeRepository) CurrentTxID(ctx context.Context) (int64, error) {
+	return m.currentTx, nil
+}
+
+func (m *mockChangeRepository) PruneChanges(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prunes = append(m.prunes, before)
+	if m.pruneErr != nil {
+		return 0, m.pruneErr
+	}
+	return 1, nil
+}
+
+func (m *mockChangeRepository) commit(cs *entities.ChangeSet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changeSets = append(m.changeSets, cs)
+}
+
+// Mock ChangeSubscriber
+type mockChangeSubscriber struct {
+	ch chan struct{}
+}
+
+func (m *mockChangeSubscriber) Subscribe() (<-chan struct{}, func()) {
+	return m.ch, func() {}
+}
+
+func writeChangeSet(txid int64, entityID string) *entities.ChangeSet {
+	return &entities.ChangeSet{
+		TxID: txid,
+		Changes: []*entities.Change{{
+			Operation: entities.ChangeOperationWrite,
+			Tuple: &entities.RelationTuple{
+				EntityType: "document", EntityID: entityID, Relation: "viewer",
+				SubjectType: "user", SubjectID: "alice",
+			},
+		}},
+	}
+}
+
+func TestWatchService_Watch_FromTxID(t *testing.T) {
+	repo := &mockChangeRepository{changeSets: []*entities.ChangeSet{
+		writeChangeSet(10, "doc1"),
+		writeChangeSet(11, "doc2"),
+		writeChangeSet(12, "doc3"),
+	}}
+	service := NewWatchService(repo, nil, time.Hour)
+
+	var received []int64
+	errStop := errors.New("stop")
+	err := service.Watch(context.Background(), "default", 10, func(cs *entities.ChangeSet) error {
+		received = append(received, cs.TxID)
+		if len(received) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected send error to be returned, got %v", err)
+	}
+	if len(received) != 2 || received[0] != 11 || received[1] != 12 {
+		t.Errorf("expected change sets 11 and 12, got %v", received)
+	}
+}
+
+func TestWatchService_Watch_FromNow(t *testing.T) {
+	repo := &mockChangeRepository{
+		changeSets: []*entities.ChangeSet{writeChangeSet(5, "old")},
+		currentTx:  20,
+	}
+	subscriber := &mockChangeSubscriber{ch: make(chan struct{}, 1)}
+	service := NewWatchService(repo, subscriber, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *entities.ChangeSet, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- service.Watch(ctx, "default", 0, func(cs *entities.ChangeSet) error {
+			received <- cs
+			return nil
+		})
+	}()
+
+	// A change committed after the watch started is delivered on notification
+	repo.commit(writeChangeSet(20, "new"))
+	subscriber.ch <- struct{}{}
+
+	select {
+	case cs := <-received:
+		if cs.TxID != 20 {
+			t.Errorf("expected change set 20, got %d", cs.TxID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("change set was not delivered after notification")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.reads[0] != 19 {
+		t.Errorf("expected first read after txid 19, got %d", repo.reads[0])
+	}
+}
+
+func TestWatchService_Watch_Polls(t *testing.T) {
+	repo := &mockChangeRepository{}
+	service := NewWatchService(repo, nil, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		repo.commit(writeChangeSet(3, "doc1"))
+	}()
+
+	errStop := errors.New("stop")
+	err := service.Watch(ctx, "default", 1, func(cs *entities.ChangeSet) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected change set to be delivered by polling, got %v", err)
+	}
+}
+
+func TestWatchService_Watch_EmptyTenant(t *testing.T) {
+	service := NewWatchService(&mockChangeRepository{}, nil, time.Second)
+	err := service.Watch(context.Background(), "", 0, func(cs *entities.ChangeSet) error { return nil })
+	if err == nil {
+		t.Error("expected error for empty tenant ID")
+	}
+}
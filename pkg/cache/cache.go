@@ -28,6 +28,14 @@ type Cache interface {
 	Metrics() *Metrics
 }
 
+// BatchGetter is implemented by caches that can look up many keys at once,
+// such as a remote cache fetching them in a single round trip.
+type BatchGetter interface {
+	// GetMulti retrieves the values of keys.
+	// Returns a map holding only the keys that were found.
+	GetMulti(ctx context.Context, keys []string) map[string]interface{}
+}
+
 // Metrics holds cache performance statistics.
 type Metrics struct {
 	// Hits is the number of cache hits
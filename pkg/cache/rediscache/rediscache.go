@@ -0,0 +1,268 @@
+package rediscache
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/asakaida/keruberosu/pkg/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKeyPrefix namespaces cache keys, so the Redis database can be shared
+const DefaultKeyPrefix = "keruberosu:cache:"
+
+// scanBatchSize is the number of keys requested per SCAN call by Clear
+const scanBatchSize = 1000
+
+// Cache implements a cache shared between server replicas, backed by Redis.
+// Values are stored as JSON, so they are returned as the types encoding/json
+// decodes into interface{} (bool, float64, string, []interface{} or
+// map[string]interface{}).
+type Cache struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	ttl       time.Duration
+
+	// Metrics
+	metrics *cacheMetrics
+}
+
+type cacheMetrics struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	keysAdded atomic.Uint64
+}
+
+// Config holds configuration for the Redis cache.
+type Config struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates to Redis (empty = no authentication).
+	Password string
+
+	// DB is the Redis database number.
+	DB int
+
+	// TLS connects to Redis over TLS.
+	TLS bool
+
+	// KeyPrefix is prepended to every key (DefaultKeyPrefix if empty).
+	// Clear deletes only the keys with this prefix.
+	KeyPrefix string
+
+	// DefaultTTL is the time-to-live used when Set is called with a zero TTL.
+	DefaultTTL time.Duration
+
+	// Timeout bounds each Redis command, so a slow Redis degrades to cache
+	// misses instead of slowing down checks (default 100ms).
+	Timeout time.Duration
+
+	// EnableMetrics enables collection of cache metrics.
+	EnableMetrics bool
+}
+
+// New creates a Redis cache and checks that the server is reachable.
+func New(config *Config) (*Cache, error) {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 100 * time.Millisecond
+	}
+	options := &redis.Options{
+		Addr:         config.Addr,
+		Password:     config.Password,
+		DB:           config.DB,
+		DialTimeout:  5 * timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		// A failed lookup falls back to evaluation, so retry only once (for
+		// connections closed by the server) instead of delaying the check
+		MaxRetries:    1,
+		DialerRetries: 1,
+	}
+	if config.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.DialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", config.Addr, err)
+	}
+
+	return NewWithClient(client, config), nil
+}
+
+// NewWithClient creates a Redis cache using an existing client (such as a
+// cluster or sentinel client). Closing the cache closes the client.
+func NewWithClient(client redis.UniversalClient, config *Config) *Cache {
+	keyPrefix := config.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = DefaultKeyPrefix
+	}
+
+	c := &Cache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       config.DefaultTTL,
+	}
+
+	if config.EnableMetrics {
+		c.metrics = &cacheMetrics{}
+	}
+
+	return c
+}
+
+// Get retrieves a value from cache. Redis errors are reported as misses.
+func (c *Cache) Get(ctx context.Context, key string) (interface{}, bool) {
+	data, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		c.recordLookup(false)
+		return nil, false
+	}
+
+	value, ok := decode(data)
+	c.recordLookup(ok)
+	return value, ok
+}
+
+// GetMulti retrieves the values of keys in a single pipelined round trip.
+// Keys that are missing, undecodable or failed are left out of the result.
+func (c *Cache) GetMulti(ctx context.Context, keys []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return result
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, c.keyPrefix+key)
+	}
+	// Per-key errors (including redis.Nil for misses) are read from each command
+	_, _ = pipe.Exec(ctx)
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil {
+			c.recordLookup(false)
+			continue
+		}
+		value, ok := decode(data)
+		c.recordLookup(ok)
+		if ok {
+			result[keys[i]] = value
+		}
+	}
+	return result
+}
+
+// Set stores a value in cache with the specified TTL (DefaultTTL if zero).
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	if err := c.client.Set(ctx, c.keyPrefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write to redis: %w", err)
+	}
+	if c.metrics != nil {
+		c.metrics.keysAdded.Add(1)
+	}
+	return nil
+}
+
+// Delete removes a value from cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, c.keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete from redis: %w", err)
+	}
+	return nil
+}
+
+// Clear removes all entries with the key prefix from cache. Other keys in the
+// Redis database are left untouched.
+func (c *Cache) Clear(ctx context.Context) error {
+	iter := c.client.Scan(ctx, 0, c.keyPrefix+"*", scanBatchSize).Iterator()
+	batch := make([]string, 0, scanBatchSize)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanBatchSize {
+			if err := c.client.Unlink(ctx, batch...).Err(); err != nil {
+				return fmt.Errorf("failed to clear redis cache: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan redis cache: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := c.client.Unlink(ctx, batch...).Err(); err != nil {
+			return fmt.Errorf("failed to clear redis cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the Redis client.
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
+
+// Metrics returns cache statistics of this replica. Evictions happen inside
+// Redis and are not reported.
+func (c *Cache) Metrics() *cache.Metrics {
+	if c.metrics == nil {
+		return &cache.Metrics{}
+	}
+
+	return &cache.Metrics{
+		Hits:      c.metrics.hits.Load(),
+		Misses:    c.metrics.misses.Load(),
+		KeysAdded: c.metrics.keysAdded.Load(),
+	}
+}
+
+// ResetMetrics resets cache statistics.
+func (c *Cache) ResetMetrics() {
+	if c.metrics == nil {
+		return
+	}
+
+	c.metrics.hits.Store(0)
+	c.metrics.misses.Store(0)
+	c.metrics.keysAdded.Store(0)
+}
+
+// recordLookup counts a hit or a miss
+func (c *Cache) recordLookup(hit bool) {
+	if c.metrics == nil {
+		return
+	}
+	if hit {
+		c.metrics.hits.Add(1)
+	} else {
+		c.metrics.misses.Add(1)
+	}
+}
+
+// decode parses a stored JSON value
+func decode(data []byte) (interface{}, bool) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
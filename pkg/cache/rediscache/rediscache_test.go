@@ -0,0 +1,150 @@
+package rediscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestCache(t *testing.T) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cache, err := New(&Config{
+		Addr:          mr.Addr(),
+		DefaultTTL:    time.Minute,
+		EnableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache, mr
+}
+
+func TestCache_SetAndGet(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "key1", true, time.Minute); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if err := cache.Set(ctx, "key2", "value2", time.Minute); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+
+	value, found := cache.Get(ctx, "key1")
+	if !found || value != true {
+		t.Errorf("expected true, got %v (found %v)", value, found)
+	}
+	value, found = cache.Get(ctx, "key2")
+	if !found || value != "value2" {
+		t.Errorf("expected value2, got %v (found %v)", value, found)
+	}
+	if _, found := cache.Get(ctx, "nonexistent"); found {
+		t.Error("expected not to find nonexistent key")
+	}
+
+	// Keys are stored with the prefix
+	if !mr.Exists(DefaultKeyPrefix + "key1") {
+		t.Errorf("expected key %s in redis, got %v", DefaultKeyPrefix+"key1", mr.Keys())
+	}
+}
+
+func TestCache_TTLExpiration(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	cache.Set(ctx, "key1", true, time.Second)
+	cache.Set(ctx, "key2", true, 0) // DefaultTTL
+
+	if ttl := mr.TTL(DefaultKeyPrefix + "key2"); ttl != time.Minute {
+		t.Errorf("expected the default TTL of 1m, got %v", ttl)
+	}
+
+	mr.FastForward(2 * time.Second)
+	if _, found := cache.Get(ctx, "key1"); found {
+		t.Error("expected not to find key1 after expiration")
+	}
+	if _, found := cache.Get(ctx, "key2"); !found {
+		t.Error("expected to find key2 before its default TTL")
+	}
+}
+
+func TestCache_GetMulti(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", true, time.Minute)
+	cache.Set(ctx, "c", false, time.Minute)
+
+	result := cache.GetMulti(ctx, []string{"a", "b", "c"})
+	if len(result) != 2 || result["a"] != true || result["c"] != false {
+		t.Errorf("expected a=true and c=false, got %v", result)
+	}
+
+	metrics := cache.Metrics()
+	if metrics.Hits != 2 || metrics.Misses != 1 || metrics.KeysAdded != 2 {
+		t.Errorf("expected 2 hits, 1 miss and 2 keys added, got %+v", metrics)
+	}
+
+	if result := cache.GetMulti(ctx, nil); len(result) != 0 {
+		t.Errorf("expected no results for no keys, got %v", result)
+	}
+}
+
+func TestCache_DeleteAndClear(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		cache.Set(ctx, key, true, time.Minute)
+	}
+	mr.Set("other:key", "kept")
+
+	if err := cache.Delete(ctx, "key1"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, found := cache.Get(ctx, "key1"); found {
+		t.Error("expected key1 to be deleted")
+	}
+
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("failed to clear: %v", err)
+	}
+	if _, found := cache.Get(ctx, "key2"); found {
+		t.Error("expected key2 to be cleared")
+	}
+	if !mr.Exists("other:key") {
+		t.Error("expected keys without the prefix to be kept")
+	}
+}
+
+func TestCache_RedisUnavailable(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+	cache.Set(ctx, "key1", true, time.Minute)
+
+	mr.Close()
+
+	if _, found := cache.Get(ctx, "key1"); found {
+		t.Error("expected a miss while redis is unavailable")
+	}
+	if result := cache.GetMulti(ctx, []string{"key1"}); len(result) != 0 {
+		t.Errorf("expected no results while redis is unavailable, got %v", result)
+	}
+	if err := cache.Set(ctx, "key2", true, time.Minute); err == nil {
+		t.Error("expected Set to fail while redis is unavailable")
+	}
+}
+
+func TestNew_Unreachable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+
+	if _, err := New(&Config{Addr: addr, Timeout: 10 * time.Millisecond}); err == nil {
+		t.Error("expected error for an unreachable redis")
+	}
+}
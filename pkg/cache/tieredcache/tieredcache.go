@@ -0,0 +1,190 @@
+package tieredcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/asakaida/keruberosu/pkg/cache"
+)
+
+// Cache implements a two-tier cache: a local cache (such as memorycache) in
+// front of a shared remote cache (such as rediscache). Reads try the local tier
+// first and copy remote hits into it, so hot keys are served in-process;
+// writes go to both tiers.
+//
+// The local tier is not invalidated when another replica changes the remote
+// tier, so it suits caches whose keys identify immutable values (such as check
+// results keyed by snapshot token and schema version). LocalTTL bounds how long
+// a local copy can outlive a Delete or Clear on another replica.
+type Cache struct {
+	local    cache.Cache
+	remote   cache.Cache
+	localTTL time.Duration
+
+	// Metrics of the cache as a whole (a hit in either tier is a hit)
+	metrics *cacheMetrics
+}
+
+type cacheMetrics struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	keysAdded atomic.Uint64
+}
+
+// DefaultLocalTTL is the LocalTTL used when none is configured
+const DefaultLocalTTL = time.Minute
+
+// Config holds configuration for the tiered cache.
+type Config struct {
+	// LocalTTL is the time-to-live of remote hits copied into the local tier, and
+	// the maximum time-to-live of entries written to it (DefaultLocalTTL if zero).
+	// Entries written with a shorter TTL keep it.
+	LocalTTL time.Duration
+
+	// EnableMetrics enables collection of cache metrics.
+	EnableMetrics bool
+}
+
+// New creates a tiered cache. Closing it closes both tiers.
+func New(local, remote cache.Cache, config *Config) *Cache {
+	localTTL := config.LocalTTL
+	if localTTL <= 0 {
+		localTTL = DefaultLocalTTL
+	}
+
+	c := &Cache{
+		local:    local,
+		remote:   remote,
+		localTTL: localTTL,
+	}
+
+	if config.EnableMetrics {
+		c.metrics = &cacheMetrics{}
+	}
+
+	return c
+}
+
+// Get retrieves a value from the local tier, or else from the remote tier.
+func (c *Cache) Get(ctx context.Context, key string) (interface{}, bool) {
+	if value, found := c.local.Get(ctx, key); found {
+		c.recordLookup(true)
+		return value, true
+	}
+
+	value, found := c.remote.Get(ctx, key)
+	c.recordLookup(found)
+	if found {
+		_ = c.local.Set(ctx, key, value, c.localTTL)
+	}
+	return value, found
+}
+
+// GetMulti retrieves the values of keys, looking up the keys missing from the
+// local tier in the remote tier with one batch when it supports BatchGetter.
+func (c *Cache) GetMulti(ctx context.Context, keys []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if value, found := c.local.Get(ctx, key); found {
+			result[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+
+	var remoteHits map[string]interface{}
+	if batch, ok := c.remote.(cache.BatchGetter); ok && len(missing) > 0 {
+		remoteHits = batch.GetMulti(ctx, missing)
+	} else {
+		remoteHits = make(map[string]interface{}, len(missing))
+		for _, key := range missing {
+			if value, found := c.remote.Get(ctx, key); found {
+				remoteHits[key] = value
+			}
+		}
+	}
+	for key, value := range remoteHits {
+		result[key] = value
+		_ = c.local.Set(ctx, key, value, c.localTTL)
+	}
+
+	if c.metrics != nil {
+		c.metrics.hits.Add(uint64(len(result)))
+		c.metrics.misses.Add(uint64(len(keys) - len(result)))
+	}
+	return result
+}
+
+// Set stores a value in both tiers. The local tier keeps it for at most LocalTTL.
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	if err := c.local.Set(ctx, key, value, localTTL); err != nil {
+		return err
+	}
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if c.metrics != nil {
+		c.metrics.keysAdded.Add(1)
+	}
+	return nil
+}
+
+// Delete removes a value from both tiers.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return errors.Join(c.local.Delete(ctx, key), c.remote.Delete(ctx, key))
+}
+
+// Clear removes all entries from both tiers. Local tiers of other replicas
+// keep their entries until LocalTTL expires.
+func (c *Cache) Clear(ctx context.Context) error {
+	return errors.Join(c.local.Clear(ctx), c.remote.Clear(ctx))
+}
+
+// Close closes both tiers.
+func (c *Cache) Close() error {
+	return errors.Join(c.local.Close(), c.remote.Close())
+}
+
+// Metrics returns cache statistics. Hits count hits in either tier, and
+// evictions are those of the local tier.
+func (c *Cache) Metrics() *cache.Metrics {
+	if c.metrics == nil {
+		return &cache.Metrics{}
+	}
+
+	return &cache.Metrics{
+		Hits:        c.metrics.hits.Load(),
+		Misses:      c.metrics.misses.Load(),
+		KeysAdded:   c.metrics.keysAdded.Load(),
+		KeysEvicted: c.local.Metrics().KeysEvicted,
+	}
+}
+
+// Local returns the local tier.
+func (c *Cache) Local() cache.Cache {
+	return c.local
+}
+
+// Remote returns the remote tier.
+func (c *Cache) Remote() cache.Cache {
+	return c.remote
+}
+
+// recordLookup counts a hit or a miss
+func (c *Cache) recordLookup(hit bool) {
+	if c.metrics == nil {
+		return
+	}
+	if hit {
+		c.metrics.hits.Add(1)
+	} else {
+		c.metrics.misses.Add(1)
+	}
+}
@@ -0,0 +1,139 @@
+package tieredcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/asakaida/keruberosu/pkg/cache/memorycache"
+	"github.com/asakaida/keruberosu/pkg/cache/rediscache"
+)
+
+// newTestCache creates a memorycache in front of a rediscache backed by miniredis
+func newTestCache(t *testing.T, localTTL time.Duration) (*Cache, *memorycache.Cache, *rediscache.Cache) {
+	t.Helper()
+	local, err := memorycache.New(&memorycache.Config{MaxSizeBytes: 1024 * 1024, EnableMetrics: true})
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	remote, err := rediscache.New(&rediscache.Config{Addr: miniredis.RunT(t).Addr(), EnableMetrics: true})
+	if err != nil {
+		t.Fatalf("failed to create remote cache: %v", err)
+	}
+	cache := New(local, remote, &Config{LocalTTL: localTTL, EnableMetrics: true})
+	t.Cleanup(func() { cache.Close() })
+	return cache, local, remote
+}
+
+func TestCache_SetWritesBothTiers(t *testing.T) {
+	cache, local, remote := newTestCache(t, time.Minute)
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "key1", true, time.Hour); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if _, found := local.Get(ctx, "key1"); !found {
+		t.Error("expected key1 in the local tier")
+	}
+	if _, found := remote.Get(ctx, "key1"); !found {
+		t.Error("expected key1 in the remote tier")
+	}
+
+	value, found := cache.Get(ctx, "key1")
+	if !found || value != true {
+		t.Errorf("expected true, got %v (found %v)", value, found)
+	}
+}
+
+func TestCache_RemoteHitPopulatesLocal(t *testing.T) {
+	cache, local, remote := newTestCache(t, time.Minute)
+	ctx := context.Background()
+
+	// Written by another replica
+	remote.Set(ctx, "key1", true, time.Hour)
+
+	value, found := cache.Get(ctx, "key1")
+	if !found || value != true {
+		t.Fatalf("expected true from the remote tier, got %v (found %v)", value, found)
+	}
+	if value, found := local.Get(ctx, "key1"); !found || value != true {
+		t.Errorf("expected the remote hit copied into the local tier, got %v (found %v)", value, found)
+	}
+
+	if _, found := cache.Get(ctx, "missing"); found {
+		t.Error("expected a miss for a key in neither tier")
+	}
+
+	metrics := cache.Metrics()
+	if metrics.Hits != 1 || metrics.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", metrics)
+	}
+}
+
+func TestCache_LocalTTL(t *testing.T) {
+	cache, local, remote := newTestCache(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	cache.Set(ctx, "key1", true, time.Hour)
+	time.Sleep(100 * time.Millisecond)
+
+	if _, found := local.Get(ctx, "key1"); found {
+		t.Error("expected the local copy to expire after LocalTTL")
+	}
+	if _, found := remote.Get(ctx, "key1"); !found {
+		t.Error("expected the remote tier to keep its TTL")
+	}
+	if _, found := cache.Get(ctx, "key1"); !found {
+		t.Error("expected a hit from the remote tier")
+	}
+}
+
+func TestCache_GetMulti(t *testing.T) {
+	cache, local, remote := newTestCache(t, time.Minute)
+	ctx := context.Background()
+
+	local.Set(ctx, "a", true, time.Minute)
+	remote.Set(ctx, "b", false, time.Minute)
+
+	result := cache.GetMulti(ctx, []string{"a", "b", "c"})
+	if len(result) != 2 || result["a"] != true || result["b"] != false {
+		t.Errorf("expected a=true and b=false, got %v", result)
+	}
+	if _, found := local.Get(ctx, "b"); !found {
+		t.Error("expected the remote hit copied into the local tier")
+	}
+
+	metrics := cache.Metrics()
+	if metrics.Hits != 2 || metrics.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %+v", metrics)
+	}
+	if remoteMetrics := remote.Metrics(); remoteMetrics.Hits+remoteMetrics.Misses != 2 {
+		t.Errorf("expected only the 2 local misses looked up remotely, got %+v", remoteMetrics)
+	}
+}
+
+func TestCache_DeleteAndClear(t *testing.T) {
+	cache, local, remote := newTestCache(t, time.Minute)
+	ctx := context.Background()
+
+	cache.Set(ctx, "key1", true, time.Minute)
+	cache.Set(ctx, "key2", true, time.Minute)
+
+	if err := cache.Delete(ctx, "key1"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, found := local.Get(ctx, "key1"); found {
+		t.Error("expected key1 deleted from the local tier")
+	}
+	if _, found := remote.Get(ctx, "key1"); found {
+		t.Error("expected key1 deleted from the remote tier")
+	}
+
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("failed to clear: %v", err)
+	}
+	if _, found := cache.Get(ctx, "key2"); found {
+		t.Error("expected key2 cleared from both tiers")
+	}
+}